@@ -10,9 +10,10 @@ set RELEASE_PATH=.\release-debug
 REM 创建发布文件夹
 echo.
 echo 创建发布文件夹...
-if exist "%RELEASE_PATH%" rd /s /q "%RELEASE_PATH%"
-mkdir "%RELEASE_PATH%\etc"
-mkdir "%RELEASE_PATH%\static"
+REM 不删除已有的发布文件夹：其中的 data\（推送台账、文案库、目录缓存、缩略图）和运行时保存的推送预设在重新构建后保留，
+REM 只覆盖程序、随程序发布的配置文件和静态文件
+if not exist "%RELEASE_PATH%\etc" mkdir "%RELEASE_PATH%\etc"
+if not exist "%RELEASE_PATH%\static" mkdir "%RELEASE_PATH%\static"
 
 REM 构建 Windows 调试版本（显示控制台窗口）
 echo.
@@ -41,6 +42,10 @@ REM 复制必需的文件
 echo.
 echo 复制配置文件和静态文件...
 copy "etc\filemanager-api.yaml" "%RELEASE_PATH%\etc\" >nul
+copy "etc\catalog.yaml" "%RELEASE_PATH%\etc\" >nul
+copy "etc\media-specs.yaml" "%RELEASE_PATH%\etc\" >nul
+copy "etc\lexicon.yaml" "%RELEASE_PATH%\etc\" >nul
+if not exist "%RELEASE_PATH%\etc\presets.yaml" copy "etc\presets.yaml" "%RELEASE_PATH%\etc\" >nul
 copy "static\index.html" "%RELEASE_PATH%\static\" >nul
 
 echo.
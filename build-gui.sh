@@ -10,7 +10,8 @@ RELEASE_PATH="/Users/chemanyu/Desktop/release"
 # 创建发布文件夹
 echo ""
 echo "创建发布文件夹..."
-rm -rf "$RELEASE_PATH"
+# 不删除已有的发布文件夹：其中的 data/（推送台账、文案库、目录缓存、缩略图）和运行时保存的推送预设在重新构建后保留，
+# 只覆盖程序和随程序发布的配置文件
 mkdir -p "$RELEASE_PATH/etc"
 
 # 构建 Windows GUI 版本
@@ -44,6 +45,12 @@ echo ""
 echo "复制配置文件..."
 
 cp etc/filemanager-api.yaml "$RELEASE_PATH/etc/"
+cp etc/catalog.yaml "$RELEASE_PATH/etc/"
+cp etc/media-specs.yaml "$RELEASE_PATH/etc/"
+cp etc/lexicon.yaml "$RELEASE_PATH/etc/"
+if [ ! -f "$RELEASE_PATH/etc/presets.yaml" ]; then
+    cp etc/presets.yaml "$RELEASE_PATH/etc/"
+fi
 
 # 创建启动说明
 cat > "$RELEASE_PATH/使用说明.txt" << EOF
@@ -95,6 +102,10 @@ cat > "$RELEASE_PATH/使用说明.txt" << EOF
 ==================================================
 注意事项
 ==================================================
+- 投放媒体与素材品类在 etc/catalog.yaml 中维护，修改后重启程序即可生效
+- 各投放媒体的素材规格在 etc/media-specs.yaml 中维护，不符合时预检报错或提示
+- 推送历史等运行数据保存在 data/ 中，推送预设保存在 etc/presets.yaml 中，重新构建时均保留
+- 违禁词词库在 etc/lexicon.yaml 中维护，修改保存后自动生效；文件缺失时不检查违禁词
 - 请确保已配置 etc/filemanager-api.yaml 中的京东 API 相关参数
 - 素材文件夹中不要包含隐藏文件（如 .DS_Store）
 - 上传前请确保网络连接正常
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"jd_material_push/internal/config"
+)
+
+// serverFlag 正在运行的服务地址；台账由服务独占读写，会修改或读取台账的命令都通过服务的接口完成。
+// 默认连接 GUI 记录在 PortFile 中的端口，没有记录时使用配置的 Port
+func serverFlag(fs *flag.FlagSet, c config.Config) *string {
+	port := c.Port
+	if data, err := os.ReadFile(c.PortFile); err == nil {
+		if p, err := strconv.Atoi(strings.TrimSpace(string(data))); err == nil && p > 0 {
+			port = p
+		}
+	}
+	return fs.String("server", fmt.Sprintf("http://127.0.0.1:%d", port), "正在运行的服务地址")
+}
+
+// postJSON 向服务发送 JSON 请求并解析响应
+func postJSON(server, path string, req, resp any) error {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("序列化请求失败: %v", err)
+	}
+	httpResp, err := http.Post(strings.TrimRight(server, "/")+path, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("连接服务失败（请先启动服务）: %v", err)
+	}
+	defer httpResp.Body.Close()
+
+	body, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return fmt.Errorf("读取响应失败: %v", err)
+	}
+	if httpResp.StatusCode != http.StatusOK {
+		return fmt.Errorf("请求失败: %s", strings.TrimSpace(string(body)))
+	}
+	if err := json.Unmarshal(body, resp); err != nil {
+		return fmt.Errorf("解析响应失败: %v", err)
+	}
+	return nil
+}
+
+// getReport 从服务导出任务报告，返回报告文件名和内容
+func getReport(server, jobID, format string) (string, []byte, error) {
+	query := url.Values{"jobId": {jobID}, "format": {format}}
+	httpResp, err := http.Get(strings.TrimRight(server, "/") + "/api/history/report?" + query.Encode())
+	if err != nil {
+		return "", nil, fmt.Errorf("连接服务失败（请先启动服务）: %v", err)
+	}
+	defer httpResp.Body.Close()
+
+	body, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return "", nil, fmt.Errorf("读取报告失败: %v", err)
+	}
+	if httpResp.StatusCode != http.StatusOK {
+		return "", nil, fmt.Errorf("导出报告失败: %s", strings.TrimSpace(string(body)))
+	}
+	// 成功时以附件返回报告文件，否则为 JSON 格式的错误信息
+	if httpResp.Header.Get("Content-Disposition") == "" {
+		var errResp struct {
+			Message string `json:"message"`
+		}
+		if err := json.Unmarshal(body, &errResp); err != nil {
+			return "", nil, fmt.Errorf("解析导出结果失败: %v", err)
+		}
+		return "", nil, fmt.Errorf("导出报告失败: %s", errResp.Message)
+	}
+	return fmt.Sprintf("report-%s.%s", jobID, format), body, nil
+}
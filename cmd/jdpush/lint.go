@@ -0,0 +1,66 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"jd_material_push/internal/config"
+	"jd_material_push/internal/types"
+)
+
+// runLint 通过正在运行的服务预检文件夹，预设按提交时相同的规则补全并检查账号，存在 error 级别问题时返回 1
+func runLint(c config.Config, args []string) int {
+	fs := flag.NewFlagSet("lint", flag.ExitOnError)
+	media := fs.String("media", "", "投放媒体，逗号分隔")
+	categories := fs.String("cate", "", "素材品类，逗号分隔")
+	releaseCopy := fs.String("copy", "", "投放文案")
+	profileName := fs.String("profile", "", "处理方案，使用其中的忽略规则、扩展名和违禁词检查方式")
+	presetName := fs.String("preset", "", "推送预设，补全未指定的设置")
+	server := serverFlag(fs, c)
+	_ = fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "用法: jdpush lint [-preset 预设] [-media 媒体] [-cate 品类] [-copy 文案] [-profile 方案] [-server 地址] <文件夹>")
+		return 2
+	}
+
+	// 服务的工作目录可能与命令行不同，传绝对路径
+	folder, err := filepath.Abs(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	var resp types.ValidateResponse
+	if err := postJSON(*server, "/api/validate", types.ValidateRequest{
+		FolderPath:   folder,
+		MediaList:    splitList(*media),
+		CategoryList: splitList(*categories),
+		ReleaseCopy:  *releaseCopy,
+		Profile:      *profileName,
+		Preset:       *presetName,
+	}, &resp); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	if resp.Code != 200 {
+		fmt.Fprintln(os.Stderr, resp.Message)
+		return 2
+	}
+
+	for _, f := range resp.Data {
+		file := f.File
+		if file == "" {
+			file = "-"
+		}
+		fmt.Printf("[%s] %s: %s (%s)\n", f.Severity, file, f.Message, f.Rule)
+	}
+
+	fmt.Printf("\n错误: %d, 警告: %d\n", resp.ErrorCount, resp.WarningCount)
+	if !resp.Passed {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"jd_material_push/internal/config"
+
+	"github.com/zeromicro/go-zero/core/conf"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// command 一个子命令
+type command struct {
+	name  string
+	usage string
+	run   func(c config.Config, args []string) int
+}
+
+var commands = []command{
+	{name: "lint", usage: "lint [选项] <文件夹>  上传前预检文件夹", run: runLint},
+	{name: "retry", usage: "retry [选项] <任务ID>  重试任务中失败的文件", run: runRetry},
+	{name: "preset", usage: "preset list|export|import  查看、导出和导入推送预设", run: runPreset},
+	{name: "report", usage: "report [选项] <任务ID>  导出任务的交付报告（CSV、JSON 或 HTML）", run: runReport},
+}
+
+func main() {
+	configFile := flag.String("f", "etc/filemanager-api.yaml", "the config file")
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+
+	var c config.Config
+	conf.MustLoad(*configFile, &c)
+	logx.DisableStat()
+
+	name := flag.Arg(0)
+	for _, cmd := range commands {
+		if cmd.name == name {
+			os.Exit(cmd.run(c, flag.Args()[1:]))
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "未知命令: %s\n\n", name)
+	usage()
+	os.Exit(2)
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "用法: jdpush [-f 配置文件] <命令> [参数]")
+	fmt.Fprintln(os.Stderr, "\n命令:")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %s\n", cmd.usage)
+	}
+}
+
+// splitList 解析逗号分隔的参数
+func splitList(s string) []string {
+	var result []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
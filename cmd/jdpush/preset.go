@@ -0,0 +1,97 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"jd_material_push/internal/config"
+	"jd_material_push/internal/preset"
+)
+
+// runPreset 查看、导出和导入推送预设
+func runPreset(c config.Config, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "用法: jdpush preset list | export [-o 文件] [预设名...] | import [-overwrite] <文件>")
+		return 2
+	}
+
+	store, err := preset.NewStore(c.PresetsPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	switch args[0] {
+	case "list":
+		presets, err := store.List()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+		for _, p := range presets {
+			fmt.Printf("%s\t媒体: %s\t品类: %s", p.Name, strings.Join(p.MediaList, ","), strings.Join(p.CategoryList, ","))
+			if p.Description != "" {
+				fmt.Printf("\t%s", p.Description)
+			}
+			fmt.Println()
+		}
+		fmt.Printf("\n共 %d 个预设（%s）\n", len(presets), c.PresetsPath)
+		return 0
+
+	case "export":
+		fs := flag.NewFlagSet("preset export", flag.ExitOnError)
+		output := fs.String("o", "", "导出到文件，默认输出到标准输出")
+		_ = fs.Parse(args[1:])
+
+		data, err := store.Export(fs.Args())
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+		if *output == "" {
+			os.Stdout.Write(data)
+			return 0
+		}
+		if err := os.WriteFile(*output, data, 0644); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+		fmt.Printf("已导出到 %s\n", *output)
+		return 0
+
+	case "import":
+		fs := flag.NewFlagSet("preset import", flag.ExitOnError)
+		overwrite := fs.Bool("overwrite", false, "覆盖同名预设，默认跳过")
+		_ = fs.Parse(args[1:])
+		if fs.NArg() != 1 {
+			fmt.Fprintln(os.Stderr, "用法: jdpush preset import [-overwrite] <文件>")
+			return 2
+		}
+
+		data, err := os.ReadFile(fs.Arg(0))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+		result, err := store.Import(data, *overwrite)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+		for _, name := range result.Added {
+			fmt.Printf("[新增] %s\n", name)
+		}
+		for _, name := range result.Replaced {
+			fmt.Printf("[覆盖] %s\n", name)
+		}
+		for _, name := range result.Skipped {
+			fmt.Printf("[跳过] %s（已存在，使用 -overwrite 覆盖）\n", name)
+		}
+		return 0
+	}
+
+	fmt.Fprintf(os.Stderr, "未知的 preset 子命令: %s\n", args[0])
+	return 2
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"jd_material_push/internal/config"
+)
+
+// runReport 通过正在运行的服务导出任务的交付报告
+func runReport(c config.Config, args []string) int {
+	fs := flag.NewFlagSet("report", flag.ExitOnError)
+	format := fs.String("format", "html", "报告格式: csv、json、html")
+	output := fs.String("o", "", "输出文件，默认为当前目录下的 report-<任务ID>.<格式>，- 表示标准输出")
+	server := serverFlag(fs, c)
+	_ = fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "用法: jdpush report [-format csv|json|html] [-o 文件] [-server 地址] <任务ID>")
+		return 2
+	}
+
+	fileName, content, err := getReport(*server, fs.Arg(0), *format)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	switch *output {
+	case "-":
+		os.Stdout.Write(content)
+		return 0
+	case "":
+		*output = fileName
+	}
+	if err := os.WriteFile(*output, content, 0644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	fmt.Printf("已导出到 %s\n", *output)
+	return 0
+}
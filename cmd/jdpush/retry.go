@@ -0,0 +1,63 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"jd_material_push/internal/config"
+	"jd_material_push/internal/types"
+)
+
+// runRetry 通过正在运行的服务重试台账任务中失败的文件，仍有失败时返回 1
+func runRetry(c config.Config, args []string) int {
+	fs := flag.NewFlagSet("retry", flag.ExitOnError)
+	profile := fs.String("profile", "", "重新上传时的处理方案")
+	server := serverFlag(fs, c)
+	_ = fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "用法: jdpush retry [-profile 方案] [-server 地址] <任务ID>")
+		return 2
+	}
+
+	var resp types.RetryJobResponse
+	if err := postJSON(*server, "/api/jobs/retry", types.RetryJobRequest{
+		JobID:   fs.Arg(0),
+		Profile: *profile,
+	}, &resp); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	if resp.Code != 200 {
+		fmt.Fprintln(os.Stderr, resp.Message)
+		return 2
+	}
+
+	failed := false
+	for _, r := range resp.Data {
+		if r.Success {
+			fmt.Printf("[上传成功] %s\n", r.FileName)
+		} else {
+			fmt.Printf("[上传失败] %s: %s\n", r.FileName, r.ErrorMsg)
+			failed = true
+		}
+	}
+	for _, b := range resp.Batches {
+		status := "提交成功"
+		if !b.Success {
+			status = "提交失败"
+			failed = true
+		}
+		fmt.Printf("[%s] 批次 %d（%d 个素材）: %s\n", status, b.Batch, len(b.Materials), b.Message)
+	}
+	if resp.Held != nil {
+		failed = true
+	}
+
+	fmt.Printf("\n%s\n", resp.Message)
+	if failed {
+		return 1
+	}
+	return 0
+}
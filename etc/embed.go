@@ -0,0 +1,9 @@
+// Package etc 将随程序发布的配置文件内置到可执行文件中
+package etc
+
+import _ "embed"
+
+// CatalogYAML 内置的投放媒体与素材品类目录，即 etc/catalog.yaml，磁盘上的目录文件不可用时使用
+//
+//go:embed catalog.yaml
+var CatalogYAML []byte
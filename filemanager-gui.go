@@ -2,20 +2,30 @@ package main
 
 import (
 	"bytes"
+	"context"
 	_ "embed"
 	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"jd_material_push/internal/config"
 	"jd_material_push/internal/handler"
+	"jd_material_push/internal/logic"
+	"jd_material_push/internal/middleware"
+	"jd_material_push/internal/source"
 	"jd_material_push/internal/svc"
+	"jd_material_push/internal/transform"
 	"jd_material_push/internal/types"
 
 	"image/color"
@@ -25,6 +35,7 @@ import (
 	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 	"github.com/zeromicro/go-zero/core/conf"
@@ -32,8 +43,9 @@ import (
 )
 
 const (
-	IconFolder = "[DIR] "
-	IconFile   = "[FILE] "
+	IconFolder  = "[DIR] "
+	IconFile    = "[FILE] "
+	IconSkipped = "[SKIP] "
 )
 
 //go:embed etc/filemanager-api.yaml
@@ -44,12 +56,68 @@ var chineseFont []byte
 
 var configFile = flag.String("f", "etc/filemanager-api.yaml", "the config file")
 
+// submitPolicies GUI 中可选的提交策略，有文件上传失败时决定是否提交上传成功的素材
+var submitPolicies = []struct {
+	label string
+	value string
+}{
+	{label: "部分成功也提交", value: "partial"},
+	{label: "全部上传成功才提交", value: "all"},
+	{label: "上传成功比例达到阈值才提交", value: "threshold"},
+}
+
+// uploadOrders GUI 中可选的上传顺序，决定文件的上传、提交分批和在平台上的先后顺序
+var uploadOrders = []struct {
+	label string
+	value string
+}{
+	{label: "按文件名", value: source.OrderName},
+	{label: "按文件名中的数字", value: source.OrderNatural},
+	{label: "按修改时间", value: source.OrderMtime},
+	{label: "按文件大小", value: source.OrderSize},
+}
+
+// submitPolicyValue 由界面上的策略名称取接口参数值
+func submitPolicyValue(label string) string {
+	for _, p := range submitPolicies {
+		if p.label == label {
+			return p.value
+		}
+	}
+	return ""
+}
+
+// submitPolicySetting 一次推送的提交策略
+type submitPolicySetting struct {
+	Policy             string
+	MinUploadedPercent int
+}
+
+// copySetting 一次推送的投放文案变体和模板变量
+type copySetting struct {
+	Variants []types.CopyVariant
+	Vars     map[string]string
+}
+
+// materialNameSetting 一次推送的素材名称模板和活动名
+type materialNameSetting struct {
+	Template string
+	Campaign string
+}
+
+// pushPreset 一次推送所选的预设及其处理方案，后端用预设补全界面中未填写的设置
+type pushPreset struct {
+	Name    string
+	Profile string
+}
+
 type FileInfo struct {
 	Name    string `json:"name"`
 	Path    string `json:"path"`
 	Size    int64  `json:"size"`
 	IsDir   bool   `json:"isDir"`
 	ModTime string `json:"modTime"`
+	Skipped string `json:"skipped,omitempty"` // 被忽略规则跳过时命中的规则
 }
 
 func main() {
@@ -85,22 +153,40 @@ func main() {
 	}
 	log.Println("配置文件加载成功")
 
-	// 使用随机可用端口
+	// 接口没有登录校验且使用本机的京东 Cookie，默认只监听本机；开启 Share 后监听所有网卡，
+	// 其他电脑须携带共享口令。优先使用配置的端口，被占用时改用随机可用端口；实际端口写入 PortFile，jdpush 命令据此连接
+	host := "127.0.0.1"
+	if c.Share.Enabled {
+		if c.Share.Token == "" {
+			log.Fatalf("开启局域网共享（Share.Enabled）时必须配置共享口令 Share.Token")
+		}
+		host = "0.0.0.0"
+	}
 	log.Println("申请端口...")
-	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	listener, err := net.Listen("tcp", net.JoinHostPort(host, strconv.Itoa(c.Port)))
+	if err != nil {
+		log.Printf("端口 %d 不可用: %v，改用随机端口", c.Port, err)
+		listener, err = net.Listen("tcp", net.JoinHostPort(host, "0"))
+	}
 	if err != nil {
 		log.Fatalf("申请端口失败: %v", err)
 	}
 	port := listener.Addr().(*net.TCPAddr).Port
 	listener.Close()
-	log.Printf("使用端口: %d", port)
+	log.Printf("监听地址: %s", net.JoinHostPort(host, strconv.Itoa(port)))
+	if err := writePortFile(c.PortFile, port); err != nil {
+		log.Printf("记录端口失败: %v", err)
+	}
 
 	// 启动后端服务
 	log.Println("启动后端服务...")
-	server := rest.MustNewServer(rest.RestConf{
-		Host: "127.0.0.1",
-		Port: port,
-	})
+	restConf := c.RestConf
+	restConf.Host = host
+	restConf.Port = port
+	server := rest.MustNewServer(restConf)
+	if c.Share.Enabled {
+		server.Use(middleware.NewShareTokenMiddleware(c.Share.Token).Handle)
+	}
 
 	ctx := svc.NewServiceContext(c)
 	handler.RegisterHandlers(server, ctx)
@@ -134,40 +220,19 @@ func main() {
 	var fileInfos []FileInfo
 	var selectedMedia []string
 	var selectedCategories []string
-
-	// 投放媒体选项
-	mediaOptions := map[string]string{
-		"巨量引擎":   "jlyq",
-		"巨量星图":   "jlxt",
-		"快手磁力智投": "ksclzt",
-		"快手磁力聚星": "kscljx",
-		"百度营销":   "bdyx",
-		"广点通":    "gdt",
-		"B站":     "bz",
-		"趣头条":    "qtt",
-	}
-
-	// 素材所属品类选项（部分常用的）
-	categoryOptions := map[string]string{
-		"本地生活/旅游出行": "4938",
-		"家庭清洁/纸品":   "15901",
-		"鲜花/奢侈品":    "1672",
-		"数码":        "652",
-		"家用电器":      "737",
-		"食品饮料":      "1320",
-		"厨具":        "6196",
-		"美妆护肤":      "1316",
-		"手机通讯":      "9987",
-		"服饰内衣":      "1315",
-		"生活日用":      "1620",
-		"个人护理":      "16750",
-		"鞋靴":        "11729",
-		"电脑、办公":     "670",
-		"运动户外":      "1318",
-		"生鲜":        "12218",
-		"母婴":        "1319",
+	// 最近一次推送的台账任务，由上传协程写入、按钮回调读取
+	var lastJobMu sync.Mutex
+	var lastJobID string
+	getLastJobID := func() string {
+		lastJobMu.Lock()
+		defer lastJobMu.Unlock()
+		return lastJobID
 	}
 
+	// 投放媒体与素材品类目录，服务在同一进程中，直接读取当前生效的目录
+	catalogResp, _ := logic.NewGetCatalogLogic(context.Background(), ctx).GetCatalog()
+	catalogData := catalogResp.Data
+
 	// 投放文案输入框
 	releaseCopyEntry := widget.NewEntry()
 	releaseCopyEntry.SetPlaceHolder("请输入投放文案")
@@ -177,6 +242,71 @@ func main() {
 	releaseCopyContainer := container.NewVBox(releaseCopyEntry)
 	releaseCopyContainer.Resize(fyne.NewSize(0, 60)) // 限制高度为60像素
 
+	// 文案库：选择已保存的文案填入投放文案，或将当前文案保存到文案库
+	copyLibraryBtn := widget.NewButton("从文案库选择", func() {
+		showCopyLibraryDialog(port, myWindow, func(text string) {
+			releaseCopyEntry.SetText(text)
+		})
+	})
+	saveCopyBtn := widget.NewButton("保存到文案库", func() {
+		text := strings.TrimSpace(releaseCopyEntry.Text)
+		if text == "" {
+			dialog.ShowInformation("提示", "请输入投放文案", myWindow)
+			return
+		}
+		nameEntry := widget.NewEntry()
+		nameEntry.SetPlaceHolder("名称，留空时取文案开头")
+		tagsEntry := widget.NewEntry()
+		tagsEntry.SetPlaceHolder("标签，逗号分隔")
+		dialog.ShowForm("保存到文案库", "保存", "取消", []*widget.FormItem{
+			widget.NewFormItem("名称", nameEntry),
+			widget.NewFormItem("标签", tagsEntry),
+		}, func(confirmed bool) {
+			if !confirmed {
+				return
+			}
+			if err := saveCopy(text, nameEntry.Text, splitTags(tagsEntry.Text), port); err != nil {
+				dialog.ShowError(err, myWindow)
+				return
+			}
+			dialog.ShowInformation("提示", "已保存到文案库", myWindow)
+		}, myWindow)
+	})
+
+	// 文案变体和模板变量：每行一个变体，轮流分配给素材；"通配符 => 文案" 只分配给文件名匹配的素材
+	copyVariantsEntry := widget.NewMultiLineEntry()
+	copyVariantsEntry.SetPlaceHolder("文案变体，每行一个，轮流分配；*_promo* => 文案 按文件名分配\n可用 {product} {price} {stem} {part:1}")
+	copyVariantsEntry.SetMinRowsVisible(3)
+	productEntry := widget.NewEntry()
+	productEntry.SetPlaceHolder("商品名，用于 {product}")
+	priceEntry := widget.NewEntry()
+	priceEntry.SetPlaceHolder("价格，用于 {price}")
+
+	// 素材名称模板，留空时使用配置的 NameTemplate
+	nameTemplateEntry := widget.NewEntry()
+	nameTemplateEntry.SetPlaceHolder("如 {date}_{campaign}_{category}_{seq:3}_{stem}，留空使用原文件名")
+	nameTemplateEntry.SetText(c.NameTemplate)
+	campaignEntry := widget.NewEntry()
+	campaignEntry.SetPlaceHolder("活动名，用于 {campaign}")
+
+	// 提交策略选择，阈值只在按比例提交时可编辑
+	minPercentEntry := widget.NewEntry()
+	minPercentEntry.SetPlaceHolder("最低上传成功比例（%）")
+	minPercentEntry.SetText("90")
+	minPercentEntry.Disable()
+	var policyLabels []string
+	for _, p := range submitPolicies {
+		policyLabels = append(policyLabels, p.label)
+	}
+	submitPolicySelect := widget.NewSelect(policyLabels, func(selected string) {
+		if submitPolicyValue(selected) == "threshold" {
+			minPercentEntry.Enable()
+		} else {
+			minPercentEntry.Disable()
+		}
+	})
+	submitPolicySelect.SetSelected(submitPolicies[0].label)
+
 	// 选中的媒体显示标签 - 使用多行富文本显示
 	selectedMediaLabel := widget.NewRichTextFromMarkdown("**未选择**")
 	selectedMediaLabel.Wrapping = fyne.TextWrapWord
@@ -195,15 +325,10 @@ func main() {
 			tempSelected[val] = true
 		}
 
-		// 创建复选框 - 按照固定顺序显示
-		mediaOrder := []string{
-			"巨量引擎", "巨量星图", "快手磁力智投", "快手磁力聚星",
-			"百度营销", "广点通", "B站", "趣头条",
-		}
-		for _, label := range mediaOrder {
-			value := mediaOptions[label]
-			label := label
-			check := widget.NewCheck(label, func(checked bool) {
+		// 创建复选框 - 按照目录顺序显示
+		for _, option := range catalogData.Media {
+			value := option.Value
+			check := widget.NewCheck(option.Label, func(checked bool) {
 				if checked {
 					tempSelected[value] = true
 				} else {
@@ -230,34 +355,21 @@ func main() {
 			scrollContent,
 			func(confirmed bool) {
 				if confirmed {
-					selectedMedia = []string{}
-					for val := range tempSelected {
-						selectedMedia = append(selectedMedia, val)
-					}
+					// 按目录顺序保存，相同选择每次提交的 applyAttr 一致
+					selectedMedia = selectedInOrder(catalogData.Media, tempSelected)
 					// 更新显示标签 - 使用更清晰的格式
 					if len(selectedMedia) == 0 {
 						selectedMediaLabel.ParseMarkdown("**未选择**")
 					} else {
-						displayLabels := []string{}
-						for _, label := range mediaOrder {
-							value := mediaOptions[label]
-							for _, sel := range selectedMedia {
-								if value == sel {
-									displayLabels = append(displayLabels, label)
-									break
-								}
-							}
-						}
-						mdText := fmt.Sprintf("**已选择 %d 项：**\n", len(selectedMedia))
-						for i, label := range displayLabels {
-							mdText += fmt.Sprintf("%d. %s\n", i+1, label)
-						}
-						selectedMediaLabel.ParseMarkdown(mdText)
+						selectedMediaLabel.ParseMarkdown(formatSelectedOptions(catalogData.Media, selectedMedia))
 					}
 				}
 			}, myWindow)
 	})
 
+	// 按子目录匹配品类：压缩包中按品类分目录交付时，目录名与品类名称或取值相同的素材使用该品类
+	categoryByFolderCheck := widget.NewCheck("按子目录名称匹配品类（未匹配的使用所选品类）", nil)
+
 	// 素材品类选择对话框
 	selectCategoryBtn := widget.NewButton("选择素材品类", func() {
 		var checkBoxes []*widget.Check
@@ -268,17 +380,10 @@ func main() {
 			tempSelected[val] = true
 		}
 
-		// 创建复选框 - 按照固定顺序显示
-		categoryOrder := []string{
-			"本地生活/旅游出行", "家庭清洁/纸品", "鲜花/奢侈品", "数码",
-			"家用电器", "食品饮料", "厨具", "美妆护肤",
-			"手机通讯", "服饰内衣", "生活日用", "个人护理",
-			"鞋靴", "电脑、办公", "运动户外", "生鲜", "母婴",
-		}
-		for _, label := range categoryOrder {
-			value := categoryOptions[label]
-			label := label
-			check := widget.NewCheck(label, func(checked bool) {
+		// 创建复选框 - 按照目录顺序显示
+		for _, option := range catalogData.Categories {
+			value := option.Value
+			check := widget.NewCheck(option.Label, func(checked bool) {
 				if checked {
 					tempSelected[value] = true
 				} else {
@@ -305,29 +410,13 @@ func main() {
 			scrollContent,
 			func(confirmed bool) {
 				if confirmed {
-					selectedCategories = []string{}
-					for val := range tempSelected {
-						selectedCategories = append(selectedCategories, val)
-					}
+					// 按目录顺序保存，相同选择每次提交的 applyAttr 一致
+					selectedCategories = selectedInOrder(catalogData.Categories, tempSelected)
 					// 更新显示标签 - 使用更清晰的格式
 					if len(selectedCategories) == 0 {
 						selectedCategoryLabel.ParseMarkdown("**未选择**")
 					} else {
-						displayLabels := []string{}
-						for _, label := range categoryOrder {
-							value := categoryOptions[label]
-							for _, sel := range selectedCategories {
-								if value == sel {
-									displayLabels = append(displayLabels, label)
-									break
-								}
-							}
-						}
-						mdText := fmt.Sprintf("**已选择 %d 项：**\n", len(selectedCategories))
-						for i, label := range displayLabels {
-							mdText += fmt.Sprintf("%d. %s\n", i+1, label)
-						}
-						selectedCategoryLabel.ParseMarkdown(mdText)
+						selectedCategoryLabel.ParseMarkdown(formatSelectedOptions(catalogData.Categories, selectedCategories))
 					}
 				}
 			}, myWindow)
@@ -354,6 +443,9 @@ func main() {
 					icon = IconFolder
 				}
 				label.Text = fmt.Sprintf("%s%s", icon, fileInfo.Name)
+				if fileInfo.Skipped != "" {
+					label.Text = fmt.Sprintf("%s%s（%s）", IconSkipped, fileInfo.Name, fileInfo.Skipped)
+				}
 				label.Refresh()
 			}
 		},
@@ -364,6 +456,45 @@ func main() {
 	pathLabel.TextSize = 14
 	pathLabel.TextStyle = fyne.TextStyle{Bold: true}
 
+	// GUI 上传使用 default 方案，文件列表按该方案和 .pushignore 的规则标出跳过的文件
+	defaultProfile, _ := transform.Lookup(c.Profiles, "")
+	fileFilter := defaultProfile.Filter()
+
+	// 上传顺序选择，文件列表按所选顺序显示
+	uploadOrder := c.UploadOrder
+	var orderLabels []string
+	for _, o := range uploadOrders {
+		orderLabels = append(orderLabels, o.label)
+	}
+	orderSelect := widget.NewSelect(orderLabels, func(selected string) {
+		for _, o := range uploadOrders {
+			if o.label == selected {
+				uploadOrder = o.value
+			}
+		}
+		if selectedPath != "" {
+			fileInfos = scanFolder(selectedPath, uploadOrder, fileFilter)
+			fileList.Refresh()
+		}
+	})
+	for _, o := range uploadOrders {
+		if o.value == uploadOrder {
+			orderSelect.SetSelected(o.label)
+		}
+	}
+
+	// 选中文件夹或压缩包后刷新文件列表
+	selectSource := func(path string) {
+		selectedPath = path
+		log.Printf("用户选择了: %s", selectedPath)
+		pathLabel.Text = selectedPath
+		pathLabel.Refresh()
+		fileInfos = scanFolder(selectedPath, uploadOrder, fileFilter)
+		fileList.Refresh()
+
+		log.Printf("扫描到 %d 个文件/文件夹", len(fileInfos))
+	}
+
 	// 选择文件夹按钮
 	selectBtn := widget.NewButton("选择文件夹", func() {
 		log.Println("用户点击了选择文件夹按钮")
@@ -377,22 +508,241 @@ func main() {
 				log.Println("用户取消了选择")
 				return
 			}
+			selectSource(uri.Path())
+		}, myWindow)
+	})
+
+	// 选择压缩包按钮，.zip / .tar.gz 无需手动解压
+	selectArchiveBtn := widget.NewButton("选择压缩包", func() {
+		log.Println("用户点击了选择压缩包按钮")
+		fileDialog := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+			if err != nil {
+				log.Printf("选择压缩包出错: %v", err)
+				dialog.ShowError(err, myWindow)
+				return
+			}
+			if reader == nil {
+				log.Println("用户取消了选择")
+				return
+			}
+			path := reader.URI().Path()
+			reader.Close()
+			if !source.IsArchive(path) {
+				dialog.ShowInformation("提示", "仅支持 .zip、.tar.gz、.tgz 压缩包", myWindow)
+				return
+			}
+			selectSource(path)
+		}, myWindow)
+		fileDialog.SetFilter(storage.NewExtensionFileFilter([]string{".zip", ".gz", ".tgz"}))
+		fileDialog.Show()
+	})
+
+	// 推送预设：选择后填入投放媒体、品类、文案、名称、提交策略和上传顺序，预设的处理方案用于扫描和上传
+	var presets []types.PresetInfo
+	var selectedPreset string
+	profileName := ""
+	presetSelect := widget.NewSelect(nil, nil)
+	presetSelect.PlaceHolder = "选择推送预设"
+	applyPresetToForm := func(p types.PresetInfo) {
+		profile, err := transform.Lookup(c.Profiles, p.Profile)
+		if err != nil {
+			dialog.ShowError(err, myWindow)
+			return
+		}
+		selectedPreset, profileName, fileFilter = p.Name, p.Profile, profile.Filter()
 
-			selectedPath = uri.Path()
-			log.Printf("用户选择了文件夹: %s", selectedPath)
-			pathLabel.Text = selectedPath
-			pathLabel.Refresh()
-			fileInfos = scanFolder(selectedPath)
+		if len(p.MediaList) > 0 {
+			selectedMedia = append([]string(nil), p.MediaList...)
+			selectedMediaLabel.ParseMarkdown(formatSelectedOptions(catalogData.Media, selectedMedia))
+		}
+		if len(p.CategoryList) > 0 {
+			selectedCategories = append([]string(nil), p.CategoryList...)
+			selectedCategoryLabel.ParseMarkdown(formatSelectedOptions(catalogData.Categories, selectedCategories))
+		}
+		if p.ReleaseCopy != "" {
+			releaseCopyEntry.SetText(p.ReleaseCopy)
+		}
+		// 引用文案库的变体无法在文本框中表示，此时留空，提交时由后端使用预设中的变体
+		copyVariantsEntry.SetText(formatCopyVariants(p.CopyVariants))
+		productEntry.SetText(p.CopyVars["product"])
+		priceEntry.SetText(p.CopyVars["price"])
+		if p.NameTemplate != "" {
+			nameTemplateEntry.SetText(p.NameTemplate)
+		}
+		campaignEntry.SetText(p.Campaign)
+		for _, policy := range submitPolicies {
+			if policy.value == p.SubmitPolicy {
+				submitPolicySelect.SetSelected(policy.label)
+			}
+		}
+		if p.MinUploadedPercent > 0 {
+			minPercentEntry.SetText(strconv.Itoa(p.MinUploadedPercent))
+		}
+		for _, o := range uploadOrders {
+			if o.value == p.Order {
+				// 切换顺序时会按新的规则重新扫描文件列表
+				orderSelect.SetSelected(o.label)
+			}
+		}
+		if selectedPath != "" {
+			fileInfos = scanFolder(selectedPath, uploadOrder, fileFilter)
 			fileList.Refresh()
+		}
+	}
+	reloadPresets := func() {
+		list, err := listPresets(port)
+		if err != nil {
+			log.Printf("获取推送预设失败: %v", err)
+			return
+		}
+		presets = list.Data
+		var names []string
+		for _, p := range presets {
+			names = append(names, p.Name)
+		}
+		presetSelect.Options = names
+		presetSelect.Refresh()
+	}
+	presetSelect.OnChanged = func(name string) {
+		for _, p := range presets {
+			if p.Name == name {
+				applyPresetToForm(p)
+			}
+		}
+	}
+	reloadPresets()
+
+	// 将当前的投放设置保存为预设，同名时覆盖
+	savePresetBtn := widget.NewButton("保存为预设", func() {
+		nameEntry := widget.NewEntry()
+		nameEntry.SetText(selectedPreset)
+		descEntry := widget.NewEntry()
+		for _, p := range presets {
+			if p.Name == selectedPreset {
+				descEntry.SetText(p.Description)
+			}
+		}
+		accountCheck := widget.NewCheck("仅限当前京东账号使用", nil)
+		dialog.ShowForm("保存为预设", "保存", "取消", []*widget.FormItem{
+			widget.NewFormItem("名称", nameEntry),
+			widget.NewFormItem("说明", descEntry),
+			widget.NewFormItem("", accountCheck),
+		}, func(confirmed bool) {
+			if !confirmed {
+				return
+			}
+			name := strings.TrimSpace(nameEntry.Text)
+			if name == "" {
+				dialog.ShowInformation("提示", "请输入预设名称", myWindow)
+				return
+			}
+			p := types.PresetInfo{
+				Name:            name,
+				Description:     strings.TrimSpace(descEntry.Text),
+				MediaList:       selectedMedia,
+				CategoryList:    selectedCategories,
+				ReleaseCopy:     releaseCopyEntry.Text,
+				CopyVariants:    parseCopyVariants(copyVariantsEntry.Text),
+				NameTemplate:    strings.TrimSpace(nameTemplateEntry.Text),
+				Campaign:        strings.TrimSpace(campaignEntry.Text),
+				Profile:         profileName,
+				Order:           uploadOrder,
+				SubmitPolicy:    submitPolicyValue(submitPolicySelect.Selected),
+				IsolateFailures: true,
+			}
+			if p.SubmitPolicy == "threshold" {
+				p.MinUploadedPercent, _ = strconv.Atoi(strings.TrimSpace(minPercentEntry.Text))
+			}
+			for key, entry := range map[string]*widget.Entry{"product": productEntry, "price": priceEntry} {
+				if v := strings.TrimSpace(entry.Text); v != "" {
+					if p.CopyVars == nil {
+						p.CopyVars = make(map[string]string)
+					}
+					p.CopyVars[key] = v
+				}
+			}
+			if accountCheck.Checked {
+				list, err := listPresets(port)
+				if err != nil {
+					dialog.ShowError(err, myWindow)
+					return
+				}
+				if list.Account == "" {
+					dialog.ShowInformation("提示", "无法识别当前登录的京东账号", myWindow)
+					return
+				}
+				p.Account = list.Account
+			}
+			if err := savePreset(p, port); err != nil {
+				dialog.ShowError(err, myWindow)
+				return
+			}
+			reloadPresets()
+			presetSelect.SetSelected(name)
+			dialog.ShowInformation("提示", "已保存预设 "+name, myWindow)
+		}, myWindow)
+	})
+
+	// 从 YAML 文件导入预设，如同事导出的或共享文件夹中的预设
+	importPresetsBtn := widget.NewButton("导入预设", func() {
+		fileDialog := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, myWindow)
+				return
+			}
+			if reader == nil {
+				return
+			}
+			data, err := io.ReadAll(reader)
+			reader.Close()
+			if err != nil {
+				dialog.ShowError(err, myWindow)
+				return
+			}
+			dialog.ShowCustomConfirm("导入预设", "覆盖", "跳过", widget.NewLabel("已存在同名预设时覆盖还是跳过？"), func(overwrite bool) {
+				result, err := importPresets(string(data), overwrite, port)
+				if err != nil {
+					dialog.ShowError(err, myWindow)
+					return
+				}
+				reloadPresets()
+				dialog.ShowInformation("导入预设", result.Message, myWindow)
+			}, myWindow)
+		}, myWindow)
+		fileDialog.SetFilter(storage.NewExtensionFileFilter([]string{".yaml", ".yml"}))
+		fileDialog.Show()
+	})
 
-			log.Printf("扫描到 %d 个文件/文件夹", len(fileInfos))
+	// 导出全部预设为 YAML 文件，可放到共享文件夹供团队使用
+	exportPresetsBtn := widget.NewButton("导出预设", func() {
+		content, err := exportPresets(port)
+		if err != nil {
+			dialog.ShowError(err, myWindow)
+			return
+		}
+		saveDialog := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, myWindow)
+				return
+			}
+			if writer == nil {
+				return
+			}
+			defer writer.Close()
+			if _, err := writer.Write([]byte(content)); err != nil {
+				dialog.ShowError(err, myWindow)
+				return
+			}
+			dialog.ShowInformation("导出预设", "已导出到 "+writer.URI().Path(), myWindow)
 		}, myWindow)
+		saveDialog.SetFileName("presets.yaml")
+		saveDialog.Show()
 	})
 
 	// 提交按钮
 	submitBtn := widget.NewButton("上传并提交素材", func() {
 		if selectedPath == "" {
-			dialog.ShowInformation("提示", "请先选择文件夹", myWindow)
+			dialog.ShowInformation("提示", "请先选择文件夹或压缩包", myWindow)
 			return
 		}
 		if len(selectedMedia) == 0 {
@@ -407,27 +757,276 @@ func main() {
 			dialog.ShowInformation("提示", "请输入投放文案", myWindow)
 			return
 		}
+		policy := submitPolicySetting{Policy: submitPolicyValue(submitPolicySelect.Selected)}
+		if policy.Policy == "threshold" {
+			percent, err := strconv.Atoi(strings.TrimSpace(minPercentEntry.Text))
+			if err != nil || percent < 1 || percent > 100 {
+				dialog.ShowInformation("提示", "最低上传成功比例需为 1-100 的整数", myWindow)
+				return
+			}
+			policy.MinUploadedPercent = percent
+		}
+
+		names := materialNameSetting{Template: strings.TrimSpace(nameTemplateEntry.Text), Campaign: strings.TrimSpace(campaignEntry.Text)}
+		copies := copySetting{
+			Variants: parseCopyVariants(copyVariantsEntry.Text),
+			Vars:     map[string]string{"product": strings.TrimSpace(productEntry.Text), "price": strings.TrimSpace(priceEntry.Text)},
+		}
+
+		// 上传并提交，预检通过（或用户确认忽略警告）后调用
+		startPush := func() {
+			log.Printf("开始上传并提交素材，共 %d 个文件", len(fileInfos))
+
+			// 显示进度对话框
+			progressDialog := dialog.NewCustomWithoutButtons("上传中",
+				widget.NewProgressBarInfinite(),
+				myWindow)
+			progressDialog.Show()
+
+			// 在后台上传并提交
+			go func() {
+				result, jobID := uploadAndSubmitMaterial(selectedPath, port, selectedMedia, selectedCategories, categoryByFolderCheck.Checked, releaseCopyEntry.Text, copies, policy, names, uploadOrder, fileFilter, pushPreset{Name: selectedPreset, Profile: profileName})
+				if jobID != "" {
+					lastJobMu.Lock()
+					lastJobID = jobID
+					lastJobMu.Unlock()
+				}
+
+				// 关闭进度对话框并在主线程显示结果
+				progressDialog.Hide()
+				showUploadResultDialog(result, myWindow)
+			}()
+		}
+
+		// 先在后台预检，有错误时阻止推送，只有警告时由用户确认
+		progressDialog := dialog.NewCustomWithoutButtons("预检中",
+			widget.NewProgressBarInfinite(),
+			myWindow)
+		progressDialog.Show()
+
+		go func() {
+			validateResp, err := validateFolder(selectedPath, selectedMedia, selectedCategories, releaseCopyEntry.Text, pushPreset{Name: selectedPreset, Profile: profileName}, port)
+			progressDialog.Hide()
+			if err != nil {
+				dialog.ShowError(err, myWindow)
+				return
+			}
+			if !validateResp.Passed {
+				showUploadResultDialog(formatValidateReport(validateResp), myWindow)
+				return
+			}
+			if validateResp.WarningCount > 0 {
+				reportText := widget.NewRichTextFromMarkdown(formatValidateReport(validateResp))
+				reportText.Wrapping = fyne.TextWrapWord
+				scroll := container.NewVScroll(reportText)
+				scroll.SetMinSize(fyne.NewSize(600, 400))
+				dialog.ShowCustomConfirm("预检警告", "继续推送", "取消", scroll, func(confirmed bool) {
+					if confirmed {
+						startPush()
+					}
+				}, myWindow)
+				return
+			}
+			startPush()
+		}()
+	})
+
+	// 预览按钮：按当前的文件顺序、投放设置和名称模板显示提交时的素材名称
+	previewNamesBtn := widget.NewButton("预览素材名称", func() {
+		if selectedPath == "" {
+			dialog.ShowInformation("提示", "请先选择文件夹或压缩包", myWindow)
+			return
+		}
+		var files []types.PreviewFile
+		for _, f := range fileInfos {
+			if !f.IsDir && f.Skipped == "" {
+				files = append(files, types.PreviewFile{FileName: f.Name, FileSize: f.Size})
+			}
+		}
+		previewResp, err := previewNames(types.PreviewNamesRequest{
+			Files:        files,
+			MediaList:    selectedMedia,
+			CategoryList: selectedCategories,
+			NameTemplate: strings.TrimSpace(nameTemplateEntry.Text),
+			Campaign:     strings.TrimSpace(campaignEntry.Text),
+		}, port)
+		if err != nil {
+			dialog.ShowError(err, myWindow)
+			return
+		}
+		showUploadResultDialog(formatNamePreview(previewResp), myWindow)
+	})
+
+	// 违禁词按钮：检查投放文案、文案变体和提交时的素材名称，与提交时使用相同的词库和规则
+	checkComplianceBtn := widget.NewButton("检查违禁词", func() {
+		req := types.ComplianceCheckRequest{Texts: []string{releaseCopyEntry.Text}}
+		for _, v := range parseCopyVariants(copyVariantsEntry.Text) {
+			req.Texts = append(req.Texts, v.Text)
+		}
+		if selectedPath != "" {
+			var files []types.PreviewFile
+			for _, f := range fileInfos {
+				if !f.IsDir && f.Skipped == "" {
+					files = append(files, types.PreviewFile{FileName: f.Name, FileSize: f.Size})
+				}
+			}
+			previewResp, err := previewNames(types.PreviewNamesRequest{
+				Files:        files,
+				MediaList:    selectedMedia,
+				CategoryList: selectedCategories,
+				NameTemplate: strings.TrimSpace(nameTemplateEntry.Text),
+				Campaign:     strings.TrimSpace(campaignEntry.Text),
+			}, port)
+			if err != nil {
+				dialog.ShowError(err, myWindow)
+				return
+			}
+			for _, p := range previewResp.Data {
+				req.MaterialNames = append(req.MaterialNames, p.Name)
+			}
+		}
+		checkResp, err := checkCompliance(req, port)
+		if err != nil {
+			dialog.ShowError(err, myWindow)
+			return
+		}
+		showUploadResultDialog(formatComplianceReport(checkResp), myWindow)
+	})
 
-		log.Printf("开始上传并提交素材，共 %d 个文件", len(fileInfos))
+	// 重试按钮：只重新上传最近一次任务中失败的文件，并提交尚未提交成功的素材，结果记录在同一任务
+	retryBtn := widget.NewButton("重试失败文件", func() {
+		jobID := getLastJobID()
+		if jobID == "" {
+			dialog.ShowInformation("提示", "本次运行还没有推送过素材", myWindow)
+			return
+		}
 
-		// 显示进度对话框
-		progressDialog := dialog.NewCustomWithoutButtons("上传中",
+		progressDialog := dialog.NewCustomWithoutButtons("重试中",
 			widget.NewProgressBarInfinite(),
 			myWindow)
 		progressDialog.Show()
 
-		// 在后台上传并提交
 		go func() {
-			result := uploadAndSubmitMaterial(selectedPath, port, selectedMedia, selectedCategories, releaseCopyEntry.Text)
+			result, err := retryJob(jobID, port)
+			progressDialog.Hide()
+			if err != nil {
+				dialog.ShowError(err, myWindow)
+				return
+			}
+			showUploadResultDialog(formatRetryResult(result), myWindow)
+		}()
+	})
+
+	// 补交按钮：补交最近一次任务中因提交策略暂缓的素材，仍未满足策略时由用户确认是否强制提交
+	submitHeldBtn := widget.NewButton("提交暂缓素材", func() {
+		jobID := getLastJobID()
+		if jobID == "" {
+			dialog.ShowInformation("提示", "本次运行还没有推送过素材", myWindow)
+			return
+		}
+
+		progressDialog := dialog.NewCustomWithoutButtons("提交中",
+			widget.NewProgressBarInfinite(),
+			myWindow)
+		progressDialog.Show()
 
-			// 关闭进度对话框并在主线程显示结果
+		go func() {
+			result, err := submitHeld(jobID, false, port)
 			progressDialog.Hide()
-			showUploadResultDialog(result, myWindow)
+			if err != nil {
+				dialog.ShowError(err, myWindow)
+				return
+			}
+			if result.Code != 409 {
+				showUploadResultDialog(formatSubmitHeldResult(result), myWindow)
+				return
+			}
+
+			reportText := widget.NewRichTextFromMarkdown(formatSubmitHeldResult(result))
+			reportText.Wrapping = fyne.TextWrapWord
+			scroll := container.NewVScroll(reportText)
+			scroll.SetMinSize(fyne.NewSize(600, 400))
+			dialog.ShowCustomConfirm("仍未满足提交策略", "仍然提交", "取消", scroll, func(confirmed bool) {
+				if !confirmed {
+					return
+				}
+				progressDialog := dialog.NewCustomWithoutButtons("提交中",
+					widget.NewProgressBarInfinite(),
+					myWindow)
+				progressDialog.Show()
+
+				go func() {
+					result, err := submitHeld(jobID, true, port)
+					progressDialog.Hide()
+					if err != nil {
+						dialog.ShowError(err, myWindow)
+						return
+					}
+					showUploadResultDialog(formatSubmitHeldResult(result), myWindow)
+				}()
+			}, myWindow)
 		}()
 	})
 
+	// 导出上次推送的交付报告，更早的任务在推送历史中导出
+	exportReportBtn := widget.NewButton("导出上次推送报告", func() {
+		jobID := getLastJobID()
+		if jobID == "" {
+			dialog.ShowInformation("提示", "本次运行还没有推送过素材，更早的任务请在推送历史中导出", myWindow)
+			return
+		}
+		showExportReportDialog(jobID, port, myWindow)
+	})
+
+	// 撤回按钮：撤回最近一次任务中已提交的素材
+	withdrawBtn := widget.NewButton("撤回上次提交", func() {
+		jobID := getLastJobID()
+		if jobID == "" {
+			dialog.ShowInformation("提示", "本次运行还没有提交过素材", myWindow)
+			return
+		}
+
+		// 先获取待撤回清单，确认后再执行
+		preview, err := withdrawMaterial(jobID, false, port)
+		if err != nil {
+			dialog.ShowError(err, myWindow)
+			return
+		}
+		if preview.Code != 200 {
+			dialog.ShowInformation("提示", preview.Message, myWindow)
+			return
+		}
+
+		mdText := fmt.Sprintf("**以下 %d 个素材将从素材中心撤回：**\n\n", len(preview.Data))
+		for i, item := range preview.Data {
+			mdText += fmt.Sprintf("%d. %s\n", i+1, item.MaterialName)
+		}
+		listText := widget.NewRichTextFromMarkdown(mdText)
+		listText.Wrapping = fyne.TextWrapWord
+		scroll := container.NewVScroll(listText)
+		scroll.SetMinSize(fyne.NewSize(500, 300))
+
+		dialog.ShowCustomConfirm("确认撤回", "撤回", "取消", scroll, func(confirmed bool) {
+			if !confirmed {
+				return
+			}
+			go func() {
+				result, err := withdrawMaterial(jobID, true, port)
+				if err != nil {
+					dialog.ShowError(err, myWindow)
+					return
+				}
+				showUploadResultDialog(formatWithdrawResult(result), myWindow)
+			}()
+		}, myWindow)
+	})
+
 	// 布局
 	formContent := container.NewVBox(
+		widget.NewLabelWithStyle("推送预设:", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		presetSelect,
+		container.NewGridWithColumns(3, savePresetBtn, importPresetsBtn, exportPresetsBtn),
+		widget.NewSeparator(),
 		widget.NewLabelWithStyle("投放媒体:", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
 		container.NewPadded(selectedMediaLabel),
 		selectMediaBtn,
@@ -435,9 +1034,23 @@ func main() {
 		widget.NewLabelWithStyle("素材品类:", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
 		container.NewPadded(selectedCategoryLabel),
 		selectCategoryBtn,
+		categoryByFolderCheck,
 		widget.NewSeparator(),
 		widget.NewLabelWithStyle("投放文案:", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
 		releaseCopyContainer,
+		container.NewGridWithColumns(3, copyLibraryBtn, saveCopyBtn, checkComplianceBtn),
+		copyVariantsEntry,
+		container.NewGridWithColumns(2, productEntry, priceEntry),
+		widget.NewSeparator(),
+		widget.NewLabelWithStyle("素材名称:", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		nameTemplateEntry,
+		container.NewGridWithColumns(2, campaignEntry, previewNamesBtn),
+		widget.NewSeparator(),
+		widget.NewLabelWithStyle("提交策略:", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		container.NewGridWithColumns(2, submitPolicySelect, minPercentEntry),
+		widget.NewSeparator(),
+		widget.NewLabelWithStyle("上传顺序:", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		orderSelect,
 	)
 
 	// 给表单内容添加滚动支持
@@ -445,19 +1058,32 @@ func main() {
 	formScroll.SetMinSize(fyne.NewSize(0, 350)) // 增加最小高度，确保所有选项可见
 
 	content := container.NewBorder(
-		container.NewVBox(pathLabel, selectBtn, widget.NewSeparator(), formScroll),
-		submitBtn,
+		container.NewVBox(pathLabel, container.NewGridWithColumns(2, selectBtn, selectArchiveBtn), widget.NewSeparator(), formScroll),
+		container.NewVBox(submitBtn, container.NewGridWithColumns(2, retryBtn, submitHeldBtn), container.NewGridWithColumns(2, withdrawBtn, exportReportBtn)),
 		nil,
 		nil,
 		fileList,
 	)
 
-	myWindow.SetContent(content)
+	// 推送历史页，切换到该页时刷新
+	historyTab, refreshHistory := newHistoryTab(catalogData, port, myWindow)
+	tabs := container.NewAppTabs(
+		container.NewTabItem("推送", content),
+		container.NewTabItem("推送历史", historyTab),
+	)
+	tabs.OnSelected = func(tab *container.TabItem) {
+		if tab.Content == historyTab {
+			refreshHistory()
+		}
+	}
+
+	myWindow.SetContent(tabs)
 
 	// 关闭时停止服务器
 	myWindow.SetOnClosed(func() {
 		log.Println("窗口已关闭，停止服务器...")
 		server.Stop()
+		removePortFile(c.PortFile, port)
 		log.Println("程序正常退出")
 	})
 
@@ -465,40 +1091,66 @@ func main() {
 	myWindow.ShowAndRun()
 }
 
-// scanFolder 扫描文件夹并返回文件信息
-func scanFolder(folderPath string) []FileInfo {
-	log.Printf("开始扫描文件夹: %s", folderPath)
-	var files []FileInfo
-
-	entries, err := os.ReadDir(folderPath)
-	if err != nil {
-		log.Printf("读取文件夹失败: %v", err)
-		return files
+// writePortFile 记录服务实际监听的端口
+func writePortFile(path string, port int) error {
+	if path == "" {
+		return nil
 	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(strconv.Itoa(port)+"\n"), 0644)
+}
 
-	for _, entry := range entries {
-		// 过滤掉 .DS_Store 和其他隐藏文件
-		if entry.Name() == ".DS_Store" || (len(entry.Name()) > 0 && entry.Name()[0] == '.') {
-			log.Printf("跳过隐藏文件: %s", entry.Name())
-			continue
-		}
+// removePortFile 退出时删除端口文件；已被之后启动的实例改写时保留
+func removePortFile(path string, port int) {
+	data, err := os.ReadFile(path)
+	if err != nil || strings.TrimSpace(string(data)) != strconv.Itoa(port) {
+		return
+	}
+	os.Remove(path)
+}
 
-		info, err := entry.Info()
-		if err != nil {
-			continue
-		}
+// scanFolder 按上传时相同的规则扫描文件夹或压缩包：只列第一层文件（压缩包含子目录路径），按上传顺序排列；
+// 被方案或 .pushignore 跳过的文件排在最后并注明命中的规则
+func scanFolder(folderPath string, order string, filter source.Filter) []FileInfo {
+	log.Printf("开始扫描文件夹: %s", folderPath)
+	var files []FileInfo
 
-		fullPath := filepath.Join(folderPath, entry.Name())
-		fileData := FileInfo{
-			Name:    entry.Name(),
-			Path:    fullPath,
-			Size:    info.Size(),
-			IsDir:   entry.IsDir(),
-			ModTime: info.ModTime().Format(time.RFC3339),
-		}
-		files = append(files, fileData)
+	src, err := source.Open(folderPath)
+	if err != nil {
+		log.Printf("读取上传源失败: %v", err)
+		return files
+	}
+	defer src.Close()
+
+	entries, skipped, err := source.Select(src, filter)
+	if err != nil {
+		log.Printf("忽略规则有误: %v", err)
+		return files
+	}
+	entries, err = source.Sorted(entries, order)
+	if err != nil {
+		log.Printf("%v，按文件名排序", err)
+		entries, _ = source.Sorted(entries, source.OrderName)
 	}
 
+	for _, entry := range entries {
+		files = append(files, FileInfo{
+			Name:    entry.Path,
+			Path:    filepath.Join(folderPath, filepath.FromSlash(entry.Path)),
+			Size:    entry.Size,
+			ModTime: entry.ModTime.Format(time.RFC3339),
+		})
+	}
+	for _, s := range skipped {
+		log.Printf("跳过 %s: %s", s.Path, s.Rule)
+		files = append(files, FileInfo{
+			Name:    s.Path,
+			Path:    filepath.Join(folderPath, filepath.FromSlash(s.Path)),
+			Skipped: s.Rule,
+		})
+	}
 	return files
 }
 
@@ -521,26 +1173,26 @@ func (ct *chineseTheme) Font(style fyne.TextStyle) fyne.Resource {
 	return fyne.NewStaticResource("NotoSansSC-Regular.ttf", chineseFont)
 }
 
-// uploadAndSubmitMaterial 上传文件并提交素材到京橙平台（批量上传+批量提交）
-func uploadAndSubmitMaterial(folderPath string, port int, mediaList, categoryList []string, releaseCopy string) string {
+// uploadAndSubmitMaterial 上传文件并提交素材到京橙平台（批量上传+批量提交），返回结果汇总和台账任务 ID
+func uploadAndSubmitMaterial(folderPath string, port int, mediaList, categoryList []string, categoryByFolder bool, releaseCopy string, copies copySetting, policy submitPolicySetting, names materialNameSetting, order string, filter source.Filter, preset pushPreset) (string, string) {
 	log.Printf("开始上传文件夹: %s", folderPath)
 
 	// 第一步：扫描文件夹获取所有文件
-	fileInfos := scanFolder(folderPath)
+	fileInfos := scanFolder(folderPath, order, filter)
 	if len(fileInfos) == 0 {
-		return "# ⚠️ 上传失败\n\n没有找到任何文件"
+		return "# ⚠️ 上传失败\n\n没有找到任何文件", ""
 	}
 
-	// 只处理非目录文件
+	// 只处理未被规则跳过的文件
 	var files []FileInfo
 	for _, f := range fileInfos {
-		if !f.IsDir {
+		if !f.IsDir && f.Skipped == "" {
 			files = append(files, f)
 		}
 	}
 
 	if len(files) == 0 {
-		return "# ⚠️ 上传失败\n\n没有找到任何可上传的文件"
+		return "# ⚠️ 上传失败\n\n没有找到任何可上传的文件", ""
 	}
 
 	log.Printf("找到 %d 个文件，开始上传...", len(files))
@@ -548,26 +1200,34 @@ func uploadAndSubmitMaterial(folderPath string, port int, mediaList, categoryLis
 	// 第二步：调用一次上传接口，后端会处理文件夹中的所有文件
 	reqBody := types.UploadRequest{
 		FolderPath: folderPath,
+		Order:      order,
+		Profile:    preset.Profile,
+		Preset:     preset.Name,
 	}
 
 	jsonData, err := json.Marshal(reqBody)
 	if err != nil {
-		return fmt.Sprintf("# ⚠️ 上传失败\n\n序列化请求失败: %v", err)
+		return fmt.Sprintf("# ⚠️ 上传失败\n\n序列化请求失败: %v", err), ""
 	}
 
 	url := fmt.Sprintf("http://127.0.0.1:%d/api/upload", port)
 	resp, err := http.Post(url, "application/json", bytes.NewBuffer(jsonData))
 	if err != nil {
-		return fmt.Sprintf("# ⚠️ 上传失败\n\n发送请求失败: %v", err)
+		return fmt.Sprintf("# ⚠️ 上传失败\n\n发送请求失败: %v", err), ""
 	}
 	defer resp.Body.Close()
 
 	var uploadResp types.UploadResponse
 	if err := json.NewDecoder(resp.Body).Decode(&uploadResp); err != nil {
-		return fmt.Sprintf("# ⚠️ 上传失败\n\n解析响应失败: %v", err)
+		return fmt.Sprintf("# ⚠️ 上传失败\n\n解析响应失败: %v", err), ""
+	}
+	// 上传被拒绝（参数有误、预设与账号不符等）时不提交
+	if uploadResp.Code != 200 {
+		return fmt.Sprintf("# ⚠️ 上传失败\n\n%s", uploadResp.Message), uploadResp.JobID
 	}
 
 	uploadResults := uploadResp.Data
+	jobID := uploadResp.JobID
 
 	// 统计上传结果
 	successCount := 0
@@ -582,32 +1242,39 @@ func uploadAndSubmitMaterial(folderPath string, port int, mediaList, categoryLis
 			sizeStr := formatFileSize(result.FileSize)
 			resultDetails += fmt.Sprintf("### ✅ %s\n", result.FileName)
 			resultDetails += fmt.Sprintf("- **大小:** %s\n", sizeStr)
+			if meta := formatMediaMeta(result.Width, result.Height, result.Duration, result.Codec); meta != "" {
+				resultDetails += fmt.Sprintf("- **规格:** %s\n", meta)
+			}
+			if result.UploadName != "" {
+				resultDetails += fmt.Sprintf("- **上传为:** %s\n", result.UploadName)
+			}
+			if result.OriginalSize > 0 {
+				resultDetails += fmt.Sprintf("- **原大小:** %s\n", formatFileSize(result.OriginalSize))
+			}
+			resultDetails += formatTransformSteps(result.Steps)
+			resultDetails += "\n"
 		} else {
 			failCount++
 			resultDetails += fmt.Sprintf("### ❌ %s\n", result.FileName)
-			resultDetails += fmt.Sprintf("- **错误:** %s\n\n", result.ErrorMsg)
+			resultDetails += fmt.Sprintf("- **错误:** %s\n", result.ErrorMsg)
+			resultDetails += formatTransformSteps(result.Steps) + "\n"
 		}
 	}
 
-	// 第三步：批量提交素材（每20条一批）
-	var submitResults []types.SubmitMaterialResponse
-	batchSize := 20
-
+	// 第三步：提交素材，后端按每批最多20个自动分批
+	var submitBatches []types.SubmitBatchResult
+	var held *types.HeldReport
+	var compliance []types.ComplianceHit
 	if len(successResults) > 0 {
-		log.Printf("开始批量提交素材，共 %d 个成功文件", len(successResults))
-
-		for i := 0; i < len(successResults); i += batchSize {
-			end := i + batchSize
-			if end > len(successResults) {
-				end = len(successResults)
-			}
-			batch := successResults[i:end]
-
-			log.Printf("提交批次 %d: %d-%d/%d", i/batchSize+1, i+1, end, len(successResults))
-
-			// 提交这一批素材
-			submitResp := submitMaterialBatch(batch, mediaList, categoryList, releaseCopy, port)
-			submitResults = append(submitResults, submitResp)
+		log.Printf("开始提交素材，共 %d 个成功文件", len(successResults))
+
+		submitResp := submitMaterialBatch(successResults, mediaList, categoryList, categoryByFolder, releaseCopy, copies, jobID, policy, names, preset, port)
+		submitBatches = submitResp.Batches
+		held = submitResp.Held
+		compliance = submitResp.Compliance
+		if len(submitBatches) == 0 && held == nil {
+			// 提交前校验失败，没有实际分批
+			submitBatches = []types.SubmitBatchResult{{Batch: 1, Success: false, Message: submitResp.Message}}
 		}
 	}
 
@@ -616,18 +1283,20 @@ func uploadAndSubmitMaterial(folderPath string, port int, mediaList, categoryLis
 	submitFailCount := 0
 	var submitDetails string
 
-	for idx, submitResp := range submitResults {
-		if submitResp.Code == 200 && submitResp.Result {
+	for _, batch := range submitBatches {
+		if batch.Success {
 			submitSuccessCount++
-			submitDetails += fmt.Sprintf("### ✅ 批次 %d\n", idx+1)
+			submitDetails += fmt.Sprintf("### ✅ %s\n", batchTitle(batch))
 			submitDetails += fmt.Sprintf("- **状态:** 提交成功\n")
-			submitDetails += fmt.Sprintf("- **信息:** %s\n\n", submitResp.Message)
 		} else {
 			submitFailCount++
-			submitDetails += fmt.Sprintf("### ❌ 批次 %d\n", idx+1)
+			submitDetails += fmt.Sprintf("### ❌ %s\n", batchTitle(batch))
 			submitDetails += fmt.Sprintf("- **状态:** 提交失败\n")
-			submitDetails += fmt.Sprintf("- **信息:** %s\n\n", submitResp.Message)
 		}
+		if len(batch.Materials) > 0 {
+			submitDetails += fmt.Sprintf("- **素材:** %d 个\n", len(batch.Materials))
+		}
+		submitDetails += fmt.Sprintf("- **信息:** %s\n\n", batch.Message)
 	}
 
 	// 构建最终汇总
@@ -640,33 +1309,50 @@ func uploadAndSubmitMaterial(folderPath string, port int, mediaList, categoryLis
 		"- **成功批次:** %d 批\n"+
 		"- **失败批次:** %d 批\n\n",
 		len(files), successCount, failCount,
-		len(submitResults), batchSize,
+		len(submitBatches), 20,
 		submitSuccessCount, submitFailCount)
+	if held != nil {
+		summary += "## ⏸️ 暂缓提交\n" + formatHeldReport(held)
+	}
+	if len(compliance) > 0 {
+		summary += "## 🔍 违禁词\n" + formatComplianceHits(compliance)
+	}
+	if len(uploadResp.Skipped) > 0 {
+		summary += "## 🚫 跳过的文件\n" + formatSkipped(uploadResp.Skipped)
+	}
+	if submitDetails != "" {
+		summary += "## 📮 提交明细\n" + submitDetails
+	}
+	if resultDetails != "" {
+		summary += "## 📁 文件明细\n" + resultDetails
+	}
 
 	log.Println(summary)
-	return summary
+	return summary, jobID
 }
 
 // submitMaterialBatch 批量提交素材到素材中心
-func submitMaterialBatch(uploadResults []types.UploadResult, mediaList, categoryList []string, releaseCopy string, port int) types.SubmitMaterialResponse {
+func submitMaterialBatch(uploadResults []types.UploadResult, mediaList, categoryList []string, categoryByFolder bool, releaseCopy string, copies copySetting, jobID string, policy submitPolicySetting, names materialNameSetting, preset pushPreset, port int) types.SubmitMaterialResponse {
 	// 构建素材列表
 	var materialList []types.MaterialItem
 	for _, result := range uploadResults {
 		if result.Success {
-			// 根据文件扩展名判断素材类型
-			materialType := 1 // 默认图片
-			ext := filepath.Ext(result.FileName)
-			ext = filepath.Ext(ext) // 去除扩展名前的点
-			if ext == ".mp4" || ext == ".avi" || ext == ".mov" {
-				materialType = 2 // 视频
+			// 素材类型由后端按文件内容识别；经过重命名等处理时使用实际上传的文件名
+			materialName := result.FileName
+			if result.UploadName != "" {
+				materialName = result.UploadName
 			}
-
 			materialList = append(materialList, types.MaterialItem{
-				MaterialName: result.FileName,
+				MaterialName: materialName,
 				MaterialSize: result.FileSize,
-				MaterialType: materialType,
+				MaterialType: result.MaterialType,
 				URL:          result.URL,
 				LocalURL:     result.LocalURL,
+				Width:        result.Width,
+				Height:       result.Height,
+				Duration:     result.Duration,
+				Codec:        result.Codec,
+				Folder:       source.Entry{Path: result.FileName}.Dir(),
 			})
 		}
 	}
@@ -686,6 +1372,18 @@ func submitMaterialBatch(uploadResults []types.UploadResult, mediaList, category
 		"mediaList":    mediaList,
 		"categoryList": categoryList,
 		"releaseCopy":  releaseCopy,
+		"jobId":        jobID,
+		// 批次被拒绝时由后端拆分重新提交，只有出问题的素材失败
+		"isolateFailures":    true,
+		"submitPolicy":       policy.Policy,
+		"minUploadedPercent": policy.MinUploadedPercent,
+		"nameTemplate":       names.Template,
+		"campaign":           names.Campaign,
+		"copyVariants":       copies.Variants,
+		"copyVars":           copies.Vars,
+		"profile":            preset.Profile,
+		"preset":             preset.Name,
+		"categoryByFolder":   categoryByFolder,
 	}
 
 	submitData, err := json.Marshal(submitReq)
@@ -722,6 +1420,614 @@ func submitMaterialBatch(uploadResults []types.UploadResult, mediaList, category
 	return materialResp
 }
 
+// validateFolder 调用预检接口检查文件夹和投放设置，按推送时的预设和处理方案决定跳过哪些文件
+func validateFolder(folderPath string, mediaList, categoryList []string, releaseCopy string, preset pushPreset, port int) (*types.ValidateResponse, error) {
+	reqData, err := json.Marshal(types.ValidateRequest{
+		FolderPath:   folderPath,
+		MediaList:    mediaList,
+		CategoryList: categoryList,
+		ReleaseCopy:  releaseCopy,
+		Profile:      preset.Profile,
+		Preset:       preset.Name,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("序列化预检请求失败: %v", err)
+	}
+
+	url := fmt.Sprintf("http://127.0.0.1:%d/api/validate", port)
+	resp, err := http.Post(url, "application/json", bytes.NewBuffer(reqData))
+	if err != nil {
+		return nil, fmt.Errorf("发送预检请求失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var validateResp types.ValidateResponse
+	if err := json.NewDecoder(resp.Body).Decode(&validateResp); err != nil {
+		return nil, fmt.Errorf("解析预检响应失败: %v", err)
+	}
+	if validateResp.Code != 200 {
+		return nil, fmt.Errorf("预检失败: %s", validateResp.Message)
+	}
+
+	return &validateResp, nil
+}
+
+// parseCopyVariants 解析文案变体输入，每行一个变体，"通配符 => 文案" 为按文件名分配的变体
+func parseCopyVariants(text string) []types.CopyVariant {
+	var variants []types.CopyVariant
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if match, copyText, ok := strings.Cut(line, "=>"); ok {
+			variants = append(variants, types.CopyVariant{Match: strings.TrimSpace(match), Text: strings.TrimSpace(copyText)})
+			continue
+		}
+		variants = append(variants, types.CopyVariant{Text: line})
+	}
+	return variants
+}
+
+// formatCopyVariants 将文案变体还原为文案变体框中的文本，与 parseCopyVariants 对应；含文案库引用时返回空
+func formatCopyVariants(variants []types.CopyVariant) string {
+	var lines []string
+	for _, v := range variants {
+		if v.CopyID != "" {
+			return ""
+		}
+		if v.Match != "" {
+			lines = append(lines, v.Match+" => "+v.Text)
+		} else {
+			lines = append(lines, v.Text)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// splitTags 按中英文逗号拆分标签
+func splitTags(text string) []string {
+	var tags []string
+	for _, t := range strings.FieldsFunc(text, func(r rune) bool { return r == ',' || r == '，' }) {
+		if t = strings.TrimSpace(t); t != "" {
+			tags = append(tags, t)
+		}
+	}
+	return tags
+}
+
+// listCopies 获取文案库中的文案
+func listCopies(tag string, port int) ([]types.CopyEntry, error) {
+	listURL := fmt.Sprintf("http://127.0.0.1:%d/api/copies?tag=%s", port, url.QueryEscape(tag))
+	resp, err := http.Get(listURL)
+	if err != nil {
+		return nil, fmt.Errorf("获取文案库失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var listResp types.CopyListResponse
+	if err := json.NewDecoder(resp.Body).Decode(&listResp); err != nil {
+		return nil, fmt.Errorf("解析文案库失败: %v", err)
+	}
+	if listResp.Code != 200 {
+		return nil, fmt.Errorf("获取文案库失败: %s", listResp.Message)
+	}
+	return listResp.Data, nil
+}
+
+// listPresets 获取推送预设和当前登录的京东账号
+func listPresets(port int) (*types.PresetListResponse, error) {
+	resp, err := http.Get(fmt.Sprintf("http://127.0.0.1:%d/api/presets", port))
+	if err != nil {
+		return nil, fmt.Errorf("获取推送预设失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var listResp types.PresetListResponse
+	if err := json.NewDecoder(resp.Body).Decode(&listResp); err != nil {
+		return nil, fmt.Errorf("解析推送预设失败: %v", err)
+	}
+	if listResp.Code != 200 {
+		return nil, fmt.Errorf("获取推送预设失败: %s", listResp.Message)
+	}
+	return &listResp, nil
+}
+
+// savePreset 保存推送预设，同名时覆盖
+func savePreset(p types.PresetInfo, port int) error {
+	reqData, err := json.Marshal(p)
+	if err != nil {
+		return fmt.Errorf("序列化预设失败: %v", err)
+	}
+
+	resp, err := http.Post(fmt.Sprintf("http://127.0.0.1:%d/api/presets/save", port), "application/json", bytes.NewBuffer(reqData))
+	if err != nil {
+		return fmt.Errorf("发送保存请求失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var saveResp types.PresetResponse
+	if err := json.NewDecoder(resp.Body).Decode(&saveResp); err != nil {
+		return fmt.Errorf("解析保存响应失败: %v", err)
+	}
+	if saveResp.Code != 200 {
+		return fmt.Errorf("保存失败: %s", saveResp.Message)
+	}
+	return nil
+}
+
+// importPresets 导入 YAML 格式的预设
+func importPresets(content string, overwrite bool, port int) (*types.ImportPresetsResponse, error) {
+	reqData, err := json.Marshal(types.ImportPresetsRequest{Content: content, Overwrite: overwrite})
+	if err != nil {
+		return nil, fmt.Errorf("序列化导入请求失败: %v", err)
+	}
+
+	resp, err := http.Post(fmt.Sprintf("http://127.0.0.1:%d/api/presets/import", port), "application/json", bytes.NewBuffer(reqData))
+	if err != nil {
+		return nil, fmt.Errorf("发送导入请求失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var importResp types.ImportPresetsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&importResp); err != nil {
+		return nil, fmt.Errorf("解析导入响应失败: %v", err)
+	}
+	if importResp.Code != 200 {
+		return nil, fmt.Errorf("导入失败: %s", importResp.Message)
+	}
+	return &importResp, nil
+}
+
+// exportPresets 导出全部预设为 YAML
+func exportPresets(port int) (string, error) {
+	resp, err := http.Get(fmt.Sprintf("http://127.0.0.1:%d/api/presets/export", port))
+	if err != nil {
+		return "", fmt.Errorf("导出预设失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var exportResp types.ExportPresetsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&exportResp); err != nil {
+		return "", fmt.Errorf("解析导出响应失败: %v", err)
+	}
+	if exportResp.Code != 200 {
+		return "", fmt.Errorf("导出预设失败: %s", exportResp.Message)
+	}
+	return exportResp.Content, nil
+}
+
+// saveCopy 将文案保存到文案库
+func saveCopy(text, name string, tags []string, port int) error {
+	reqData, err := json.Marshal(types.SaveCopyRequest{Name: name, Text: text, Tags: tags})
+	if err != nil {
+		return fmt.Errorf("序列化保存请求失败: %v", err)
+	}
+
+	saveURL := fmt.Sprintf("http://127.0.0.1:%d/api/copies/save", port)
+	resp, err := http.Post(saveURL, "application/json", bytes.NewBuffer(reqData))
+	if err != nil {
+		return fmt.Errorf("发送保存请求失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var saveResp types.CopyResponse
+	if err := json.NewDecoder(resp.Body).Decode(&saveResp); err != nil {
+		return fmt.Errorf("解析保存响应失败: %v", err)
+	}
+	if saveResp.Code != 200 {
+		return fmt.Errorf("保存失败: %s", saveResp.Message)
+	}
+	return nil
+}
+
+// showCopyLibraryDialog 显示文案库，可按标签筛选，选中后回调文案内容
+func showCopyLibraryDialog(port int, parent fyne.Window, onSelect func(text string)) {
+	byLabel := make(map[string]types.CopyEntry)
+	radio := widget.NewRadioGroup(nil, nil)
+	load := func(tag string) {
+		entries, err := listCopies(tag, port)
+		if err != nil {
+			dialog.ShowError(err, parent)
+			return
+		}
+		byLabel = make(map[string]types.CopyEntry, len(entries))
+		var labels []string
+		for _, e := range entries {
+			label := fmt.Sprintf("%s（%d 字", e.Name, e.Length)
+			if len(e.Tags) > 0 {
+				label += "，" + strings.Join(e.Tags, "/")
+			}
+			label += fmt.Sprintf("，使用 %d 次）\n%s", e.UseCount, e.Text)
+			byLabel[label] = e
+			labels = append(labels, label)
+		}
+		radio.Options = labels
+		radio.Selected = ""
+		radio.Refresh()
+	}
+
+	tagEntry := widget.NewEntry()
+	tagEntry.SetPlaceHolder("按标签筛选")
+	filterBtn := widget.NewButton("筛选", func() { load(strings.TrimSpace(tagEntry.Text)) })
+	load("")
+
+	scroll := container.NewVScroll(radio)
+	scroll.SetMinSize(fyne.NewSize(500, 350))
+	content := container.NewBorder(container.NewBorder(nil, nil, nil, filterBtn, tagEntry), nil, nil, nil, scroll)
+
+	dialog.ShowCustomConfirm("文案库", "使用", "取消", content, func(confirmed bool) {
+		if !confirmed || radio.Selected == "" {
+			return
+		}
+		onSelect(byLabel[radio.Selected].Text)
+	}, parent)
+}
+
+// previewNames 调用本地服务预览提交时的素材名称
+func previewNames(req types.PreviewNamesRequest, port int) (*types.PreviewNamesResponse, error) {
+	reqData, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("序列化预览请求失败: %v", err)
+	}
+
+	url := fmt.Sprintf("http://127.0.0.1:%d/api/names/preview", port)
+	resp, err := http.Post(url, "application/json", bytes.NewBuffer(reqData))
+	if err != nil {
+		return nil, fmt.Errorf("发送预览请求失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var previewResp types.PreviewNamesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&previewResp); err != nil {
+		return nil, fmt.Errorf("解析预览响应失败: %v", err)
+	}
+	if previewResp.Code != 200 {
+		return nil, fmt.Errorf("预览失败: %s", previewResp.Message)
+	}
+
+	return &previewResp, nil
+}
+
+// checkCompliance 调用本地服务检查违禁词
+func checkCompliance(req types.ComplianceCheckRequest, port int) (*types.ComplianceCheckResponse, error) {
+	reqData, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("序列化检查请求失败: %v", err)
+	}
+
+	url := fmt.Sprintf("http://127.0.0.1:%d/api/compliance/check", port)
+	resp, err := http.Post(url, "application/json", bytes.NewBuffer(reqData))
+	if err != nil {
+		return nil, fmt.Errorf("发送检查请求失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var checkResp types.ComplianceCheckResponse
+	if err := json.NewDecoder(resp.Body).Decode(&checkResp); err != nil {
+		return nil, fmt.Errorf("解析检查响应失败: %v", err)
+	}
+	if checkResp.Code != 200 {
+		return nil, fmt.Errorf("检查失败: %s", checkResp.Message)
+	}
+
+	return &checkResp, nil
+}
+
+// formatComplianceReport 将违禁词检查结果格式化为 Markdown
+func formatComplianceReport(resp *types.ComplianceCheckResponse) string {
+	title := "# ✅ 未发现违禁词"
+	switch {
+	case resp.LexiconMissing:
+		return "# ⚠️ 未检查违禁词\n\n" + resp.Message + "\n"
+	case !resp.Passed:
+		title = "# ❌ 含有违禁词，提交时将被阻止"
+	case len(resp.Data) > 0:
+		title = "# ⚠️ 含有需注意的用语"
+	}
+	if len(resp.Data) == 0 {
+		return title + "\n"
+	}
+	return title + "\n\n" + formatComplianceHits(resp.Data)
+}
+
+// formatComplianceHits 将命中的违禁词格式化为 Markdown 列表，命中处用【】标出
+func formatComplianceHits(hits []types.ComplianceHit) string {
+	text := ""
+	for _, h := range hits {
+		icon := "⚠️"
+		if h.Severity == "error" {
+			icon = "❌"
+		}
+		where := "投放文案"
+		if h.Field == "materialName" {
+			where = "素材名称"
+		}
+		suggest := "建议删除"
+		if len(h.Suggest) > 0 {
+			suggest = "建议改为 " + strings.Join(h.Suggest, "/")
+		}
+		category := ""
+		if h.Category != "" {
+			category = "（" + h.Category + "）"
+		}
+		text += fmt.Sprintf("- %s %s: %s —「%s」%s，%s\n", icon, where, h.Highlight, h.Term, category, suggest)
+	}
+	return text + "\n"
+}
+
+// formatNamePreview 将名称预览格式化为 Markdown
+func formatNamePreview(resp *types.PreviewNamesResponse) string {
+	if resp.Template == "" {
+		return "# 🏷️ 素材名称预览\n\n未设置名称模板，提交时使用原文件名\n"
+	}
+	text := fmt.Sprintf("# 🏷️ 素材名称预览\n\n- **模板:** %s\n", resp.Template)
+	if resp.Message != "success" {
+		text += fmt.Sprintf("- **提示:** %s\n", resp.Message)
+	}
+	text += "\n序号按全部上传成功计算\n\n"
+	for i, p := range resp.Data {
+		mark := ""
+		if p.Truncated {
+			mark = "（已截断）"
+		}
+		text += fmt.Sprintf("%d. %s → **%s**%s\n", i+1, p.FileName, p.Name, mark)
+	}
+	return text
+}
+
+// formatValidateReport 将预检结果格式化为 Markdown
+func formatValidateReport(resp *types.ValidateResponse) string {
+	title := "# ⚠️ 预检警告"
+	if !resp.Passed {
+		title = "# ❌ 预检未通过，已阻止推送"
+	}
+	text := fmt.Sprintf("%s\n\n- **错误:** %d 个\n- **警告:** %d 个\n\n", title, resp.ErrorCount, resp.WarningCount)
+	for _, f := range resp.Data {
+		icon := "⚠️"
+		if f.Severity == "error" {
+			icon = "❌"
+		}
+		file := f.File
+		if file == "" {
+			file = "投放设置"
+		}
+		text += fmt.Sprintf("### %s %s\n- %s\n\n", icon, file, f.Message)
+	}
+	return text
+}
+
+// formatSelectedOptions 按目录顺序将已选项格式化为 Markdown 列表
+func formatSelectedOptions(options []types.CatalogOption, selected []string) string {
+	selectedSet := make(map[string]bool, len(selected))
+	for _, val := range selected {
+		selectedSet[val] = true
+	}
+
+	mdText := fmt.Sprintf("**已选择 %d 项：**\n", len(selected))
+	i := 0
+	for _, option := range options {
+		if selectedSet[option.Value] {
+			i++
+			mdText += fmt.Sprintf("%d. %s\n", i, option.Label)
+		}
+	}
+	return mdText
+}
+
+// selectedInOrder 按目录顺序返回已选项的取值
+func selectedInOrder(options []types.CatalogOption, selected map[string]bool) []string {
+	values := []string{}
+	for _, option := range options {
+		if selected[option.Value] {
+			values = append(values, option.Value)
+		}
+	}
+	return values
+}
+
+// withdrawMaterial 撤回任务中已提交的素材，confirm 为 false 时只获取待撤回清单
+func withdrawMaterial(jobID string, confirm bool, port int) (*types.WithdrawMaterialResponse, error) {
+	reqData, err := json.Marshal(types.WithdrawMaterialRequest{
+		JobID:   jobID,
+		Confirm: confirm,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("序列化撤回请求失败: %v", err)
+	}
+
+	url := fmt.Sprintf("http://127.0.0.1:%d/api/withdraw-material", port)
+	resp, err := http.Post(url, "application/json", bytes.NewBuffer(reqData))
+	if err != nil {
+		return nil, fmt.Errorf("发送撤回请求失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var withdrawResp types.WithdrawMaterialResponse
+	if err := json.NewDecoder(resp.Body).Decode(&withdrawResp); err != nil {
+		return nil, fmt.Errorf("解析撤回响应失败: %v", err)
+	}
+
+	return &withdrawResp, nil
+}
+
+// retryJob 重试任务中失败的文件
+func retryJob(jobID string, port int) (*types.RetryJobResponse, error) {
+	reqData, err := json.Marshal(types.RetryJobRequest{
+		JobID: jobID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("序列化重试请求失败: %v", err)
+	}
+
+	url := fmt.Sprintf("http://127.0.0.1:%d/api/jobs/retry", port)
+	resp, err := http.Post(url, "application/json", bytes.NewBuffer(reqData))
+	if err != nil {
+		return nil, fmt.Errorf("发送重试请求失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var retryResp types.RetryJobResponse
+	if err := json.NewDecoder(resp.Body).Decode(&retryResp); err != nil {
+		return nil, fmt.Errorf("解析重试响应失败: %v", err)
+	}
+
+	return &retryResp, nil
+}
+
+// formatRetryResult 将重试结果格式化为 Markdown
+func formatRetryResult(resp *types.RetryJobResponse) string {
+	text := fmt.Sprintf("# 🔁 重试结果\n\n%s\n\n", resp.Message)
+	if resp.Held != nil {
+		text += "## ⏸️ 暂缓提交\n" + formatHeldReport(resp.Held)
+	}
+	if len(resp.Batches) > 0 {
+		text += "## 📮 提交明细\n"
+		for _, batch := range resp.Batches {
+			if batch.Success {
+				text += fmt.Sprintf("### ✅ %s\n", batchTitle(batch))
+			} else {
+				text += fmt.Sprintf("### ❌ %s\n", batchTitle(batch))
+			}
+			text += fmt.Sprintf("- **素材:** %d 个\n", len(batch.Materials))
+			text += fmt.Sprintf("- **信息:** %s\n\n", batch.Message)
+		}
+	}
+	if len(resp.Data) > 0 {
+		text += "## 📁 文件明细\n"
+		for _, result := range resp.Data {
+			if result.Success {
+				text += fmt.Sprintf("### ✅ %s\n", result.FileName)
+				text += fmt.Sprintf("- **大小:** %s\n\n", formatFileSize(result.FileSize))
+			} else {
+				text += fmt.Sprintf("### ❌ %s\n", result.FileName)
+				text += fmt.Sprintf("- **错误:** %s\n\n", result.ErrorMsg)
+			}
+		}
+	}
+	if len(resp.Skipped) > 0 {
+		text += "## 🚫 跳过的文件\n" + formatSkipped(resp.Skipped)
+	}
+	return text
+}
+
+// formatSkipped 将被规则跳过的文件格式化为 Markdown 列表
+func formatSkipped(skipped []types.SkippedFile) string {
+	text := ""
+	for _, s := range skipped {
+		text += fmt.Sprintf("- %s（%s）\n", s.FileName, s.Rule)
+	}
+	return text + "\n"
+}
+
+// submitHeld 补交任务中暂缓提交的素材，force 为 true 时不再检查提交策略
+func submitHeld(jobID string, force bool, port int) (*types.SubmitJobResponse, error) {
+	reqData, err := json.Marshal(types.SubmitHeldRequest{
+		JobID: jobID,
+		Force: force,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("序列化补交请求失败: %v", err)
+	}
+
+	url := fmt.Sprintf("http://127.0.0.1:%d/api/jobs/submit-held", port)
+	resp, err := http.Post(url, "application/json", bytes.NewBuffer(reqData))
+	if err != nil {
+		return nil, fmt.Errorf("发送补交请求失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var submitResp types.SubmitJobResponse
+	if err := json.NewDecoder(resp.Body).Decode(&submitResp); err != nil {
+		return nil, fmt.Errorf("解析补交响应失败: %v", err)
+	}
+
+	return &submitResp, nil
+}
+
+// formatSubmitHeldResult 将补交结果格式化为 Markdown
+func formatSubmitHeldResult(resp *types.SubmitJobResponse) string {
+	text := fmt.Sprintf("# 📮 补交结果\n\n%s\n\n", resp.Message)
+	if resp.Held != nil {
+		text += formatHeldReport(resp.Held)
+	}
+	for _, batch := range resp.Batches {
+		if batch.Success {
+			text += fmt.Sprintf("### ✅ %s\n", batchTitle(batch))
+		} else {
+			text += fmt.Sprintf("### ❌ %s\n", batchTitle(batch))
+		}
+		text += fmt.Sprintf("- **素材:** %d 个\n", len(batch.Materials))
+		text += fmt.Sprintf("- **信息:** %s\n\n", batch.Message)
+	}
+	return text
+}
+
+// batchTitle 批次标题，批次 0 为不符合投放媒体规格而未提交的素材
+func batchTitle(batch types.SubmitBatchResult) string {
+	if batch.Batch == 0 {
+		return "未提交：" + strings.Join(batch.Rejected, "、")
+	}
+	return fmt.Sprintf("批次 %d", batch.Batch)
+}
+
+// formatHeldReport 格式化暂缓提交的情况：策略、上传情况和上传失败的文件
+func formatHeldReport(report *types.HeldReport) string {
+	text := fmt.Sprintf("- **提交策略:** %s\n", report.Policy)
+	text += fmt.Sprintf("- **上传成功:** %d / %d 个文件\n", report.Uploaded, report.Total)
+	text += fmt.Sprintf("- **暂缓提交:** %d 个素材\n", len(report.HeldMaterials))
+	if len(report.FailedFiles) > 0 {
+		text += "- **上传失败:**\n"
+		for _, name := range report.FailedFiles {
+			text += fmt.Sprintf("  - %s\n", name)
+		}
+	}
+	text += "\n修复上传失败的文件后，点击\"重试失败文件\"重新上传并提交，或点击\"提交暂缓素材\"直接补交。\n\n"
+	return text
+}
+
+// formatWithdrawResult 将撤回结果格式化为 Markdown
+func formatWithdrawResult(resp *types.WithdrawMaterialResponse) string {
+	text := fmt.Sprintf("# 🗑️ 撤回结果\n\n%s\n\n", resp.Message)
+	for _, item := range resp.Data {
+		if item.Success {
+			text += fmt.Sprintf("### ✅ %s\n", item.MaterialName)
+		} else {
+			text += fmt.Sprintf("### ❌ %s\n", item.MaterialName)
+			text += fmt.Sprintf("- **错误:** %s\n\n", item.Message)
+		}
+	}
+	return text
+}
+
+// formatTransformSteps 格式化上传前处理步骤
+func formatTransformSteps(steps []types.TransformStep) string {
+	text := ""
+	for _, step := range steps {
+		mark := "✅"
+		if !step.Success {
+			mark = "⚠️"
+		}
+		text += fmt.Sprintf("- **处理 %s %s:** %s\n", mark, step.Step, step.Message)
+	}
+	return text
+}
+
+// formatMediaMeta 格式化尺寸、时长和编码，如 "1080×1920 · 15.0 秒 · avc1"
+func formatMediaMeta(width, height int, duration float64, codec string) string {
+	var parts []string
+	if width > 0 && height > 0 {
+		parts = append(parts, fmt.Sprintf("%d×%d", width, height))
+	}
+	if duration > 0 {
+		parts = append(parts, fmt.Sprintf("%.1f 秒", duration))
+	}
+	if codec != "" {
+		parts = append(parts, codec)
+	}
+	return strings.Join(parts, " · ")
+}
+
 // formatFileSize 格式化文件大小
 func formatFileSize(bytes int64) string {
 	const unit = 1024
@@ -751,3 +2057,362 @@ func showUploadResultDialog(content string, window fyne.Window) {
 	d.Resize(fyne.NewSize(750, 550))
 	d.Show()
 }
+
+// historyStatuses 推送历史中可筛选的任务状态
+var historyStatuses = []struct {
+	label string
+	value string
+}{
+	{label: "全部状态", value: ""},
+	{label: "全部成功", value: "success"},
+	{label: "部分失败", value: "partial"},
+	{label: "全部失败", value: "failed"},
+	{label: "暂缓提交", value: "held"},
+	{label: "已上传未提交", value: "uploaded"},
+	{label: "已撤回", value: "withdrawn"},
+	{label: "无素材", value: "empty"},
+}
+
+// historyStatusLabel 任务状态的显示名称
+func historyStatusLabel(status string) string {
+	for _, s := range historyStatuses {
+		if s.value == status && s.value != "" {
+			return s.label
+		}
+	}
+	return status
+}
+
+// newHistoryTab 创建推送历史页：按日期、账号、媒体、品类、状态和文件名筛选任务，点击任务查看详情；
+// 返回页面内容和刷新函数
+func newHistoryTab(catalogData types.Catalog, port int, window fyne.Window) (fyne.CanvasObject, func()) {
+	fromEntry := widget.NewEntry()
+	fromEntry.SetPlaceHolder("开始日期 2024-06-01")
+	toEntry := widget.NewEntry()
+	toEntry.SetPlaceHolder("结束日期 2024-06-30")
+	accountEntry := widget.NewEntry()
+	accountEntry.SetPlaceHolder("京东账号")
+	fileNameEntry := widget.NewEntry()
+	fileNameEntry.SetPlaceHolder("文件名包含")
+
+	mediaSelect := widget.NewSelect(append([]string{"全部媒体"}, optionLabels(catalogData.Media)...), nil)
+	mediaSelect.SetSelectedIndex(0)
+	categorySelect := widget.NewSelect(append([]string{"全部品类"}, optionLabels(catalogData.Categories)...), nil)
+	categorySelect.SetSelectedIndex(0)
+	var statusLabels []string
+	for _, s := range historyStatuses {
+		statusLabels = append(statusLabels, s.label)
+	}
+	statusSelect := widget.NewSelect(statusLabels, nil)
+	statusSelect.SetSelectedIndex(0)
+
+	totalLabel := widget.NewLabel("")
+	var jobs []types.HistoryJob
+	jobList := widget.NewList(
+		func() int { return len(jobs) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			job := jobs[id]
+			created, _ := time.Parse(time.RFC3339, job.CreatedAt)
+			source := job.FolderPath
+			if source == "" {
+				source = "（接口提交）"
+			}
+			obj.(*widget.Label).SetText(fmt.Sprintf("%s  %s  %s  共 %d 个，提交成功 %d，失败 %d  %s",
+				created.Format("2006-01-02 15:04"), historyStatusLabel(job.Status), job.Account, job.Total, job.Submitted, job.Failed, source))
+		},
+	)
+	jobList.OnSelected = func(id widget.ListItemID) {
+		jobList.Unselect(id)
+		detail, err := getHistoryDetail(jobs[id].ID, port)
+		if err != nil {
+			dialog.ShowError(err, window)
+			return
+		}
+		showHistoryDetailDialog(formatHistoryDetail(detail, catalogData), detail.Job.ID, port, window)
+	}
+
+	refresh := func() {
+		query := url.Values{}
+		set := func(key, value string) {
+			if value = strings.TrimSpace(value); value != "" {
+				query.Set(key, value)
+			}
+		}
+		set("from", fromEntry.Text)
+		set("to", toEntry.Text)
+		set("account", accountEntry.Text)
+		set("fileName", fileNameEntry.Text)
+		if i := mediaSelect.SelectedIndex(); i > 0 {
+			query.Set("media", catalogData.Media[i-1].Value)
+		}
+		if i := categorySelect.SelectedIndex(); i > 0 {
+			query.Set("category", catalogData.Categories[i-1].Value)
+		}
+		if i := statusSelect.SelectedIndex(); i > 0 {
+			query.Set("status", historyStatuses[i].value)
+		}
+		query.Set("pageSize", "200")
+
+		resp, err := queryHistory(query, port)
+		if err != nil {
+			dialog.ShowError(err, window)
+			return
+		}
+		jobs = resp.Data
+		totalLabel.SetText(fmt.Sprintf("共 %d 个任务", resp.Total))
+		if resp.Total > len(jobs) {
+			totalLabel.SetText(fmt.Sprintf("共 %d 个任务，显示最近 %d 个，请缩小筛选范围", resp.Total, len(jobs)))
+		}
+		jobList.Refresh()
+	}
+
+	searchBtn := widget.NewButton("查询", refresh)
+	filters := container.NewVBox(
+		container.NewGridWithColumns(3, fromEntry, toEntry, accountEntry),
+		container.NewGridWithColumns(3, mediaSelect, categorySelect, statusSelect),
+		container.NewBorder(nil, nil, nil, searchBtn, fileNameEntry),
+		totalLabel,
+	)
+	return container.NewBorder(filters, nil, nil, nil, jobList), refresh
+}
+
+// optionLabels 目录选项的显示名称
+func optionLabels(options []types.CatalogOption) []string {
+	labels := make([]string, 0, len(options))
+	for _, option := range options {
+		labels = append(labels, option.Label)
+	}
+	return labels
+}
+
+// queryHistory 按条件查询推送历史
+func queryHistory(query url.Values, port int) (*types.HistoryResponse, error) {
+	resp, err := http.Get(fmt.Sprintf("http://127.0.0.1:%d/api/history?%s", port, query.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("查询推送历史失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var historyResp types.HistoryResponse
+	if err := json.NewDecoder(resp.Body).Decode(&historyResp); err != nil {
+		return nil, fmt.Errorf("解析推送历史失败: %v", err)
+	}
+	if historyResp.Code != 200 {
+		return nil, fmt.Errorf("查询推送历史失败: %s", historyResp.Message)
+	}
+	return &historyResp, nil
+}
+
+// getHistoryDetail 获取任务详情
+func getHistoryDetail(jobID string, port int) (*types.HistoryDetailResponse, error) {
+	resp, err := http.Get(fmt.Sprintf("http://127.0.0.1:%d/api/history/detail?jobId=%s", port, url.QueryEscape(jobID)))
+	if err != nil {
+		return nil, fmt.Errorf("获取任务详情失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var detailResp types.HistoryDetailResponse
+	if err := json.NewDecoder(resp.Body).Decode(&detailResp); err != nil {
+		return nil, fmt.Errorf("解析任务详情失败: %v", err)
+	}
+	if detailResp.Code != 200 {
+		return nil, fmt.Errorf("获取任务详情失败: %s", detailResp.Message)
+	}
+	return &detailResp, nil
+}
+
+// formatHistoryDetail 格式化任务详情：提交设置、结果统计和每个素材的上传、提交及审核结果
+func formatHistoryDetail(resp *types.HistoryDetailResponse, catalogData types.Catalog) string {
+	job := resp.Job
+	var b strings.Builder
+	fmt.Fprintf(&b, "## 任务 %s\n\n", job.ID)
+	fmt.Fprintf(&b, "- **状态**: %s\n", historyStatusLabel(job.Status))
+	fmt.Fprintf(&b, "- **创建时间**: %s\n", job.CreatedAt)
+	if job.Account != "" {
+		fmt.Fprintf(&b, "- **账号**: %s\n", job.Account)
+	}
+	if job.FolderPath != "" {
+		fmt.Fprintf(&b, "- **来源**: %s\n", job.FolderPath)
+	}
+	if len(job.MediaList) > 0 {
+		fmt.Fprintf(&b, "- **投放媒体**: %s\n", strings.Join(selectedLabels(catalogData.Media, job.MediaList), "、"))
+	}
+	if len(job.CategoryList) > 0 {
+		fmt.Fprintf(&b, "- **素材品类**: %s\n", strings.Join(selectedLabels(catalogData.Categories, job.CategoryList), "、"))
+	}
+	if job.ReleaseCopy != "" {
+		fmt.Fprintf(&b, "- **投放文案**: %s\n", job.ReleaseCopy)
+	}
+	if job.Campaign != "" {
+		fmt.Fprintf(&b, "- **活动名**: %s\n", job.Campaign)
+	}
+	if job.Profile != "" {
+		fmt.Fprintf(&b, "- **处理方案**: %s\n", job.Profile)
+	}
+	fmt.Fprintf(&b, "- **结果**: 共 %d 个，上传成功 %d，提交成功 %d，失败 %d，暂缓 %d，撤回 %d；审核通过 %d，驳回 %d\n",
+		job.Total, job.Uploaded, job.Submitted, job.Failed, job.Held, job.Withdrawn, job.Approved, job.Rejected)
+	if len(job.Batches) > 0 {
+		fmt.Fprintf(&b, "- **批次**: %s\n", strings.Join(job.Batches, "、"))
+	}
+
+	b.WriteString("\n## 素材\n\n")
+	for i, m := range resp.Materials {
+		fmt.Fprintf(&b, "### %d. %s\n\n", i+1, m.MaterialName)
+		if m.FileName != m.MaterialName {
+			fmt.Fprintf(&b, "- 文件: %s\n", m.FileName)
+		}
+		fmt.Fprintf(&b, "- 大小: %s %s\n", formatFileSize(m.FileSize), formatMediaMeta(m.Width, m.Height, m.Duration, ""))
+		fmt.Fprintf(&b, "- 上传: %s，提交: %s，审核: %s\n", statusText(m.UploadStatus), statusText(m.SubmitStatus), statusText(m.ApprovalStatus))
+		if m.URL != "" {
+			fmt.Fprintf(&b, "- URL: %s\n", m.URL)
+		}
+		if m.BatchUUID != "" {
+			fmt.Fprintf(&b, "- 批次: %s\n", m.BatchUUID)
+		}
+		if m.Message != "" {
+			fmt.Fprintf(&b, "- 信息: %s\n", m.Message)
+		}
+		if m.ApprovalNote != "" {
+			fmt.Fprintf(&b, "- 审核意见: %s\n", m.ApprovalNote)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// selectedLabels 取已选值的显示名称，目录中已没有的值原样显示
+func selectedLabels(options []types.CatalogOption, values []string) []string {
+	labels := make([]string, 0, len(values))
+	for _, v := range values {
+		label := v
+		for _, option := range options {
+			if option.Value == v {
+				label = option.Label
+				break
+			}
+		}
+		labels = append(labels, label)
+	}
+	return labels
+}
+
+// statusText 素材上传、提交和审核状态的显示名称
+func statusText(status string) string {
+	switch status {
+	case "":
+		return "—"
+	case "uploaded", "submitted":
+		return "成功"
+	case "failed":
+		return "失败"
+	case "held":
+		return "暂缓"
+	case "withdrawn":
+		return "已撤回"
+	case "withdraw_failed":
+		return "撤回失败"
+	case "pending":
+		return "待审核"
+	case "approved":
+		return "通过"
+	case "rejected":
+		return "驳回"
+	}
+	return status
+}
+
+// showHistoryDetailDialog 显示任务详情，可导出交付报告
+func showHistoryDetailDialog(content, jobID string, port int, window fyne.Window) {
+	detailText := widget.NewRichTextFromMarkdown(content)
+	detailText.Wrapping = fyne.TextWrapWord
+
+	scroll := container.NewScroll(detailText)
+	scroll.SetMinSize(fyne.NewSize(700, 500))
+
+	exportBtn := widget.NewButton("导出报告", func() { showExportReportDialog(jobID, port, window) })
+	d := dialog.NewCustom("📋 任务 "+jobID, "关闭", container.NewBorder(nil, exportBtn, nil, nil, scroll), window)
+	d.Resize(fyne.NewSize(750, 550))
+	d.Show()
+}
+
+// reportFormats 可导出的报告格式
+var reportFormats = []struct {
+	label string
+	value string
+}{
+	{label: "HTML 素材画廊（带缩略图，可离线打开）", value: "html"},
+	{label: "CSV 素材清单（可用 Excel 打开）", value: "csv"},
+	{label: "JSON 素材清单", value: "json"},
+}
+
+// showExportReportDialog 选择格式后导出任务的交付报告
+func showExportReportDialog(jobID string, port int, window fyne.Window) {
+	var labels []string
+	for _, f := range reportFormats {
+		labels = append(labels, f.label)
+	}
+	formatRadio := widget.NewRadioGroup(labels, nil)
+	formatRadio.SetSelected(labels[0])
+
+	dialog.ShowCustomConfirm("导出报告", "导出", "取消", formatRadio, func(confirmed bool) {
+		if !confirmed {
+			return
+		}
+		format := reportFormats[0].value
+		for _, f := range reportFormats {
+			if f.label == formatRadio.Selected {
+				format = f.value
+			}
+		}
+
+		content, err := exportReport(jobID, format, port)
+		if err != nil {
+			dialog.ShowError(err, window)
+			return
+		}
+		saveDialog := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, window)
+				return
+			}
+			if writer == nil {
+				return
+			}
+			defer writer.Close()
+			if _, err := writer.Write(content); err != nil {
+				dialog.ShowError(err, window)
+				return
+			}
+			dialog.ShowInformation("导出报告", "已导出到 "+writer.URI().Path(), window)
+		}, window)
+		saveDialog.SetFileName(fmt.Sprintf("report-%s.%s", jobID, format))
+		saveDialog.Show()
+	}, window)
+}
+
+// exportReport 导出任务的交付报告，返回报告文件内容
+func exportReport(jobID, format string, port int) ([]byte, error) {
+	resp, err := http.Get(fmt.Sprintf("http://127.0.0.1:%d/api/history/report?jobId=%s&format=%s", port, url.QueryEscape(jobID), format))
+	if err != nil {
+		return nil, fmt.Errorf("导出报告失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("读取报告失败: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("导出报告失败: %s", strings.TrimSpace(string(body)))
+	}
+	// 成功时以附件返回报告文件，否则为 JSON 格式的错误信息
+	if resp.Header.Get("Content-Disposition") == "" {
+		var errResp types.ExportReportResponse
+		if err := json.Unmarshal(body, &errResp); err != nil {
+			return nil, fmt.Errorf("解析导出结果失败: %v", err)
+		}
+		return nil, fmt.Errorf("导出报告失败: %s", errResp.Message)
+	}
+	return body, nil
+}
@@ -0,0 +1,182 @@
+package applyattr
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"jd_material_push/internal/catalog"
+)
+
+// Build 按列定义校验列值并生成 extAddMaterial 所需的 applyAttr JSON 字符串
+// values: 列 key → 值，多选列传 []string，单选或输入列传 string
+func Build(columns []catalog.Column, values map[string]interface{}) (string, error) {
+	diyColumns, err := BuildColumns(columns, values)
+	if err != nil {
+		return "", err
+	}
+
+	applyAttrJSON, err := json.Marshal(map[string]interface{}{
+		"diyColumns": diyColumns,
+	})
+	if err != nil {
+		return "", fmt.Errorf("序列化 applyAttr 失败: %w", err)
+	}
+	return string(applyAttrJSON), nil
+}
+
+// Values 将投放媒体、素材品类、投放文案和其他列值合并为列 key → 值
+func Values(mediaList, categoryList []string, releaseCopy string, extra map[string]interface{}) map[string]interface{} {
+	values := map[string]interface{}{
+		catalog.ColumnKeyMedia:    mediaList,
+		catalog.ColumnKeyCategory: categoryList,
+		catalog.ColumnKeyRelease:  releaseCopy,
+	}
+	for key, value := range extra {
+		values[key] = value
+	}
+	return values
+}
+
+// BuildColumns 按列定义校验列值并生成 diyColumns 列表
+func BuildColumns(columns []catalog.Column, values map[string]interface{}) ([]map[string]interface{}, error) {
+	if problems := Validate(columns, values); len(problems) > 0 {
+		return nil, fmt.Errorf("applyAttr 校验失败: %s", strings.Join(problems, "；"))
+	}
+
+	diyColumns := make([]map[string]interface{}, 0, len(columns))
+	for _, col := range columns {
+		items, _ := normalize(values[col.Key])
+
+		var value interface{}
+		if col.IsMultiple == catalog.MultipleYes {
+			if items == nil {
+				items = []string{}
+			}
+			value = items
+		} else {
+			single := ""
+			if len(items) > 0 {
+				single = items[0]
+			}
+			value = single
+		}
+
+		columnEnum := col.ColumnEnum
+		if columnEnum == nil {
+			columnEnum = []catalog.Option{}
+		}
+
+		diyColumns = append(diyColumns, map[string]interface{}{
+			"isRequired": col.IsRequired,
+			"columnType": col.ColumnType,
+			"length":     col.Length,
+			"isMultiple": col.IsMultiple,
+			"label":      col.Label,
+			"value":      value,
+			"columnEnum": columnEnum,
+			"key":        col.Key,
+		})
+	}
+
+	return diyColumns, nil
+}
+
+// Validate 校验列值，返回全部问题描述；必填、枚举、单选/多选、长度均在此检查
+func Validate(columns []catalog.Column, values map[string]interface{}) []string {
+	var problems []string
+
+	known := make(map[string]bool, len(columns))
+	for _, col := range columns {
+		known[col.Key] = true
+		problems = append(problems, validateColumn(col, values[col.Key])...)
+	}
+
+	for key := range values {
+		if !known[key] {
+			problems = append(problems, fmt.Sprintf("未定义的列: %s", key))
+		}
+	}
+
+	return problems
+}
+
+func validateColumn(col catalog.Column, raw interface{}) []string {
+	items, err := normalize(raw)
+	if err != nil {
+		return []string{fmt.Sprintf("%s: %v", col.Label, err)}
+	}
+
+	if len(items) == 0 {
+		if col.IsRequired {
+			return []string{fmt.Sprintf("%s 为必填项", col.Label)}
+		}
+		return nil
+	}
+
+	var problems []string
+	if col.IsMultiple != catalog.MultipleYes && len(items) > 1 {
+		problems = append(problems, fmt.Sprintf("%s 只能选择一项，当前 %d 项", col.Label, len(items)))
+	}
+
+	var allowed map[string]bool
+	if col.ColumnType == catalog.ColumnTypeSelect {
+		allowed = make(map[string]bool, len(col.ColumnEnum))
+		for _, o := range col.ColumnEnum {
+			allowed[o.Value] = true
+		}
+	}
+
+	for _, item := range items {
+		if col.Length > 0 && utf8.RuneCountInString(item) > col.Length {
+			problems = append(problems, fmt.Sprintf("%s 超出长度限制 %d 字: %s", col.Label, col.Length, item))
+		}
+		if allowed != nil && !allowed[item] {
+			problems = append(problems, fmt.Sprintf("%s 的取值 %s 不在当前目录中（可能已失效）", col.Label, item))
+		}
+	}
+
+	return problems
+}
+
+// Strings 将列值统一为字符串列表，空串会被忽略
+func Strings(raw interface{}) ([]string, error) {
+	return normalize(raw)
+}
+
+// normalize 将 string、[]string 或 JSON 解析出的 []interface{} 统一为字符串列表，空串会被忽略
+func normalize(raw interface{}) ([]string, error) {
+	var items []string
+	switch v := raw.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		items = []string{v}
+	case []string:
+		items = v
+	case []interface{}:
+		for _, e := range v {
+			nested, err := normalize(e)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, nested...)
+		}
+	case float64:
+		items = []string{strconv.FormatFloat(v, 'f', -1, 64)}
+	case int, int64, json.Number:
+		items = []string{fmt.Sprint(v)}
+	default:
+		return nil, fmt.Errorf("不支持的值类型 %T", raw)
+	}
+
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result, nil
+}
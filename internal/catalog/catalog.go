@@ -0,0 +1,131 @@
+package catalog
+
+import (
+	"fmt"
+	"time"
+
+	"jd_material_push/etc"
+
+	"github.com/zeromicro/go-zero/core/conf"
+)
+
+// 素材中心中投放媒体、素材品类、投放文案对应的 diyColumns key
+const (
+	ColumnKeyMedia    = "media"
+	ColumnKeyCategory = "cate"
+	ColumnKeyRelease  = "release"
+)
+
+// diyColumns 列类型
+const (
+	ColumnTypeSelect = 2 // 下拉选择，值必须在 columnEnum 中
+	ColumnTypeInput  = 3 // 输入框，columnEnum 仅为推荐值
+)
+
+// diyColumns 选择方式
+const (
+	MultipleYes = 1 // 多选
+	MultipleNo  = 2 // 单选
+)
+
+// Option 目录中的一个可选项
+type Option struct {
+	Label string `json:"label,optional,omitempty"` // 显示名称
+	Value string `json:"value"`                    // 提交到素材中心的值
+}
+
+// Column 素材中心 diyColumns 的列定义
+type Column struct {
+	Key        string   `json:"key"`
+	Label      string   `json:"label"`
+	ColumnType int      `json:"columnType"`
+	Length     int      `json:"length,optional"`
+	IsRequired bool     `json:"isRequired,optional"`
+	IsMultiple int      `json:"isMultiple,default=2"`
+	ColumnEnum []Option `json:"columnEnum,optional"`
+}
+
+// Catalog 投放媒体与素材品类目录
+type Catalog struct {
+	Media      []Option  `json:"media"`             // 投放媒体
+	Categories []Option  `json:"categories"`        // 素材所属品类
+	Columns    []Column  `json:"columns"`           // applyAttr 列定义，同步后以素材中心为准
+	Version    string    `json:"version,optional"`  // 同步版本号，未同步时为空
+	SyncedAt   time.Time `json:"syncedAt,optional"` // 同步时间
+}
+
+// Load 从 YAML 文件加载目录
+func Load(path string) (*Catalog, error) {
+	var c Catalog
+	if err := conf.Load(path, &c); err != nil {
+		return nil, fmt.Errorf("加载目录文件失败: %w", err)
+	}
+	if len(c.Media) == 0 || len(c.Categories) == 0 || len(c.Columns) == 0 {
+		return nil, fmt.Errorf("目录文件 %s 中投放媒体、素材品类或列定义为空", path)
+	}
+	return &c, nil
+}
+
+// Default 返回编译时内置的 etc/catalog.yaml
+func Default() *Catalog {
+	var c Catalog
+	if err := conf.LoadFromYamlBytes(etc.CatalogYAML, &c); err != nil {
+		panic(fmt.Sprintf("内置目录有误: %v", err))
+	}
+	return &c
+}
+
+// LoadOrDefault 从 YAML 文件加载目录，文件缺失、有误或为空时返回内置默认目录和加载错误
+func LoadOrDefault(path string) (*Catalog, error) {
+	c, err := Load(path)
+	if err != nil {
+		return Default(), err
+	}
+	return c, nil
+}
+
+// MediaLabel 返回投放媒体值对应的显示名称，未知时原样返回
+func (c *Catalog) MediaLabel(value string) string {
+	return labelOf(c.Media, value)
+}
+
+// CategoryLabel 返回素材品类值对应的显示名称，未知时原样返回
+func (c *Catalog) CategoryLabel(value string) string {
+	return labelOf(c.Categories, value)
+}
+
+// Column 按 key 查找列定义
+func (c *Catalog) Column(key string) (Column, bool) {
+	for _, col := range c.Schema() {
+		if col.Key == key {
+			return col, true
+		}
+	}
+	return Column{}, false
+}
+
+// Schema 返回完整的列定义，media、cate 未配置枚举时使用目录中的媒体与品类
+func (c *Catalog) Schema() []Column {
+	columns := make([]Column, 0, len(c.Columns))
+	for _, col := range c.Columns {
+		if len(col.ColumnEnum) == 0 {
+			switch col.Key {
+			case ColumnKeyMedia:
+				col.ColumnEnum = c.Media
+			case ColumnKeyCategory:
+				col.ColumnEnum = c.Categories
+			}
+		}
+		columns = append(columns, col)
+	}
+	return columns
+}
+
+func labelOf(options []Option, value string) string {
+	for _, o := range options {
+		if o.Value == value {
+			return o.Label
+		}
+	}
+	return value
+}
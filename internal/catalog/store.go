@@ -0,0 +1,130 @@
+package catalog
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Snapshot 从素材中心同步到的列定义快照，缓存到本地文件
+type Snapshot struct {
+	Version  string    `json:"version"`
+	SyncedAt time.Time `json:"syncedAt"`
+	Columns  []Column  `json:"columns"`
+}
+
+// Store 持有当前生效的目录，同步后原子替换
+type Store struct {
+	base      *Catalog
+	cachePath string
+
+	mu      sync.RWMutex
+	current *Catalog
+}
+
+// NewStore 以 YAML 目录为基础创建目录存储，存在本地缓存时叠加缓存中的同步结果
+func NewStore(base *Catalog, cachePath string) (*Store, error) {
+	s := &Store{
+		base:      base,
+		cachePath: cachePath,
+		current:   base,
+	}
+
+	data, err := os.ReadFile(cachePath)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取目录缓存失败: %w", err)
+	}
+
+	var snap Snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, fmt.Errorf("解析目录缓存失败: %w", err)
+	}
+	s.current = merge(base, &snap)
+
+	return s, nil
+}
+
+// Current 返回当前生效的目录，调用方不得修改返回值
+func (s *Store) Current() *Catalog {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.current
+}
+
+// Apply 应用新的同步快照并写入本地缓存，返回快照版本是否发生变化
+func (s *Store) Apply(snap *Snapshot) (bool, error) {
+	if snap.Version == "" {
+		snap.Version = columnsVersion(snap.Columns)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	changed := snap.Version != s.current.Version
+	if err := s.saveCache(snap); err != nil {
+		return changed, err
+	}
+	s.current = merge(s.base, snap)
+
+	return changed, nil
+}
+
+func (s *Store) saveCache(snap *Snapshot) error {
+	if dir := filepath.Dir(s.cachePath); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("创建目录缓存文件夹失败: %w", err)
+		}
+	}
+
+	data, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化目录缓存失败: %w", err)
+	}
+
+	tmp := s.cachePath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("写入目录缓存失败: %w", err)
+	}
+	return os.Rename(tmp, s.cachePath)
+}
+
+// merge 用同步到的列定义覆盖 YAML 中的列定义、媒体与品类，同步结果缺少某项时保留 YAML 中的配置
+func merge(base *Catalog, snap *Snapshot) *Catalog {
+	c := &Catalog{
+		Media:      base.Media,
+		Categories: base.Categories,
+		Columns:    base.Columns,
+		Version:    snap.Version,
+		SyncedAt:   snap.SyncedAt,
+	}
+	if len(snap.Columns) > 0 {
+		c.Columns = snap.Columns
+	}
+	for _, col := range snap.Columns {
+		if len(col.ColumnEnum) == 0 {
+			continue
+		}
+		switch col.Key {
+		case ColumnKeyMedia:
+			c.Media = col.ColumnEnum
+		case ColumnKeyCategory:
+			c.Categories = col.ColumnEnum
+		}
+	}
+	return c
+}
+
+// columnsVersion 以列定义内容的摘要作为版本号，内容不变则版本不变
+func columnsVersion(columns []Column) string {
+	data, _ := json.Marshal(columns)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:6])
+}
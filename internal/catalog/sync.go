@@ -0,0 +1,84 @@
+package catalog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"jd_material_push/internal/materialcenter"
+)
+
+// syncResponse 素材中心查询业务线配置的响应
+type syncResponse struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Result  json.RawMessage `json:"result"`
+}
+
+// businessConfig 业务线配置，applyAttr 可能是对象也可能是 JSON 字符串
+type businessConfig struct {
+	DiyColumns []Column        `json:"diyColumns"`
+	ApplyAttr  json.RawMessage `json:"applyAttr"`
+}
+
+// Fetch 调用素材中心方法 method 拉取当前业务线的 diyColumns 列定义。
+// 仓库中没有该方法的接口文档或抓包记录（推测为 extGetBusinessConfig），方法名由配置项 CatalogSyncMethod 指定，
+// 未配置时不调用；素材中心返回的内容无法识别时，错误中提示方法名可能有误
+func Fetch(ctx context.Context, client *materialcenter.Client, method string) (*Snapshot, error) {
+	if method == "" {
+		return nil, fmt.Errorf("未配置目录同步方法 CatalogSyncMethod：素材中心查询业务线配置的方法尚未核实，确认方法名后在配置文件中设置")
+	}
+	respBody, err := client.Call(ctx, method, map[string]interface{}{})
+	if err != nil {
+		return nil, err
+	}
+	unverified := func(err error) error {
+		return fmt.Errorf("%w（目录同步方法 %s 未经核实，持续失败时请在素材中心确认方法名）", err, method)
+	}
+
+	var resp syncResponse
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return nil, unverified(fmt.Errorf("解析响应失败: %v, 响应内容: %s", err, string(respBody)))
+	}
+	if resp.Code != 200 {
+		return nil, unverified(fmt.Errorf("同步目录失败: code=%d, message=%s", resp.Code, resp.Message))
+	}
+
+	columns, err := parseColumns(resp.Result)
+	if err != nil {
+		return nil, unverified(err)
+	}
+	if len(columns) == 0 {
+		return nil, unverified(fmt.Errorf("素材中心未返回 diyColumns 列定义"))
+	}
+
+	return &Snapshot{
+		Version:  columnsVersion(columns),
+		SyncedAt: time.Now(),
+		Columns:  columns,
+	}, nil
+}
+
+func parseColumns(raw json.RawMessage) ([]Column, error) {
+	var cfg businessConfig
+	if err := json.Unmarshal(raw, &cfg); err != nil {
+		return nil, fmt.Errorf("解析业务线配置失败: %w", err)
+	}
+	if len(cfg.DiyColumns) > 0 || len(cfg.ApplyAttr) == 0 {
+		return cfg.DiyColumns, nil
+	}
+
+	// applyAttr 以字符串形式返回时需要再解析一次
+	attr := []byte(cfg.ApplyAttr)
+	var attrStr string
+	if err := json.Unmarshal(attr, &attrStr); err == nil {
+		attr = []byte(attrStr)
+	}
+
+	var nested businessConfig
+	if err := json.Unmarshal(attr, &nested); err != nil {
+		return nil, fmt.Errorf("解析 applyAttr 失败: %w", err)
+	}
+	return nested.DiyColumns, nil
+}
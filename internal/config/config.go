@@ -4,9 +4,41 @@
 package config
 
 import (
+	"jd_material_push/internal/imagenorm"
+	"jd_material_push/internal/preflight"
+	"jd_material_push/internal/staging"
+	"jd_material_push/internal/transform"
+
 	"github.com/zeromicro/go-zero/rest"
 )
 
 type Config struct {
 	rest.RestConf
+	Share              ShareConf           `json:",optional"`                        // 局域网共享，默认只允许本机访问
+	LedgerPath         string              `json:",default=data/ledger.json"`        // 本地推送台账文件
+	PortFile           string              `json:",default=data/server.port"`        // GUI 实际监听的端口，jdpush 命令据此连接服务
+	ThumbnailDir       string              `json:",default=data/thumbnails"`         // 上传时生成的素材缩略图，用于交付报告
+	CatalogPath        string              `json:",default=etc/catalog.yaml"`        // 投放媒体与素材品类目录文件
+	CatalogCachePath   string              `json:",default=data/catalog-cache.json"` // 从素材中心同步的目录缓存
+	CatalogSyncOnStart bool                `json:",optional"`                        // 启动时在后台同步一次目录
+	CatalogSyncMethod  string              `json:",optional"`                        // 同步目录调用的素材中心方法，核实后再配置，为空时不能同步
+	MediaSpecsPath     string              `json:",default=etc/media-specs.yaml"`    // 各投放媒体的素材规格文件
+	CopyLibraryPath    string              `json:",default=data/copy-library.json"`  // 投放文案库文件
+	LexiconPath        string              `json:",default=etc/lexicon.yaml"`        // 违禁词词库文件，修改后自动重新加载
+	PresetsPath        string              `json:",default=etc/presets.yaml"`        // 推送预设文件，可指向共享文件夹
+	Preflight          preflight.Rules     // 上传前预检规则
+	Normalize          imagenorm.Options   // 上传前图片规整
+	Profiles           []transform.Profile `json:",optional"` // 上传前处理方案
+	Staging            staging.Options     // 浏览器上传的暂存空间
+	SubmitConcurrency  int                 `json:",default=1"`                                    // 分多批提交素材时的并发批次数，为 1 时按顺序依次提交
+	UploadOrder        string              `json:",default=name,options=name|natural|mtime|size"` // 默认上传顺序
+	NameTemplate       string              `json:",optional"`                                     // 默认素材名称模板，为空时使用原文件名
+	NameMaxLength      int                 `json:",default=50"`                                   // 素材名称的最大长度（字符数）
+	WithdrawMethod     string              `json:",optional"`                                     // 撤回素材调用的素材中心方法，核实后再配置，为空时不能撤回
+}
+
+// ShareConf 局域网共享：接口使用本机登录的京东 Cookie，开启后其他电脑须携带共享口令
+type ShareConf struct {
+	Enabled bool   `json:",optional"` // 监听所有网卡，供同事通过本机地址调用浏览器上传等接口
+	Token   string `json:",optional"` // 共享口令，其他电脑在请求头 X-Share-Token 中携带；开启共享时必填
 }
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strings"
 	"sync"
 	"time"
 
@@ -70,6 +72,24 @@ func (m *Manager) GetCookie() (string, error) {
 	return m.cookie, nil
 }
 
+// Account 当前 Cookie 对应的京东账号（Cookie 中的 pin），无法识别时返回空
+func (m *Manager) Account() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, part := range strings.Split(m.cookie, ";") {
+		name, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok || name != "pin" {
+			continue
+		}
+		if pin, err := url.QueryUnescape(value); err == nil {
+			return pin
+		}
+		return value
+	}
+	return ""
+}
+
 // fetchCookie 从接口获取 Cookie
 func (m *Manager) fetchCookie() error {
 	// 创建请求
@@ -0,0 +1,234 @@
+package copylib
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxRevisions 每条文案保留的历史版本数
+const maxRevisions = 20
+
+// Entry 文案库中的一条文案
+type Entry struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`                // 名称，便于在列表中区分
+	Text       string     `json:"text"`                // 文案内容，可含模板变量
+	Tags       []string   `json:"tags,omitempty"`      // 标签
+	Revisions  []Revision `json:"revisions,omitempty"` // 修改前的历史版本，最新的在前
+	UseCount   int        `json:"useCount"`            // 提交时使用的次数
+	LastUsedAt time.Time  `json:"lastUsedAt,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	UpdatedAt  time.Time  `json:"updatedAt"`
+}
+
+// Revision 文案的一个历史版本
+type Revision struct {
+	Text      string    `json:"text"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// HasTag 是否带有该标签，标签为空时总是返回 true
+func (e Entry) HasTag(tag string) bool {
+	if tag == "" {
+		return true
+	}
+	for _, t := range e.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// Store 投放文案库，以 JSON 文件持久化
+type Store struct {
+	path    string
+	mu      sync.RWMutex
+	entries []*Entry
+}
+
+// NewStore 打开文案库文件，文件不存在时创建空文案库
+func NewStore(path string) (*Store, error) {
+	s := &Store{path: path}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取文案库失败: %w", err)
+	}
+	if len(data) == 0 {
+		return s, nil
+	}
+	if err := json.Unmarshal(data, &s.entries); err != nil {
+		return nil, fmt.Errorf("解析文案库失败: %w", err)
+	}
+
+	return s, nil
+}
+
+// List 返回带有该标签的文案副本，最近使用或修改的在前
+func (s *Store) List(tag string) []Entry {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entries := make([]Entry, 0, len(s.entries))
+	for _, e := range s.entries {
+		if e.HasTag(tag) {
+			entries = append(entries, cloneEntry(e))
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return recent(entries[i]).After(recent(entries[j])) })
+	return entries
+}
+
+// Get 获取文案副本
+func (s *Store) Get(id string) (Entry, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	e := s.find(id)
+	if e == nil {
+		return Entry{}, false
+	}
+	return cloneEntry(e), true
+}
+
+// Save 新建（ID 为空时）或修改文案并落盘；内容变化时原内容记入历史版本
+func (s *Store) Save(id, name, text string, tags []string) (Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	e := &Entry{ID: newEntryID(), CreatedAt: now}
+	if id != "" {
+		if e = s.find(id); e == nil {
+			return Entry{}, fmt.Errorf("文案不存在: %s", id)
+		}
+	} else {
+		s.entries = append(s.entries, e)
+	}
+
+	if e.Text != "" && e.Text != text {
+		e.Revisions = append([]Revision{{Text: e.Text, UpdatedAt: e.UpdatedAt}}, e.Revisions...)
+		if len(e.Revisions) > maxRevisions {
+			e.Revisions = e.Revisions[:maxRevisions]
+		}
+	}
+	e.Name = name
+	e.Text = text
+	e.Tags = cleanTags(tags)
+	e.UpdatedAt = now
+	if err := s.save(); err != nil {
+		return Entry{}, err
+	}
+	return cloneEntry(e), nil
+}
+
+// Delete 删除文案，不存在时返回 false
+func (s *Store) Delete(id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, e := range s.entries {
+		if e.ID == id {
+			s.entries = append(s.entries[:i], s.entries[i+1:]...)
+			return true, s.save()
+		}
+	}
+	return false, nil
+}
+
+// MarkUsed 记录文案在提交中被使用，忽略不存在的 ID
+func (s *Store) MarkUsed(ids ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	hits := 0
+	for _, id := range ids {
+		if e := s.find(id); e != nil {
+			e.UseCount++
+			e.LastUsedAt = now
+			hits++
+		}
+	}
+	if hits == 0 {
+		return nil
+	}
+	return s.save()
+}
+
+func (s *Store) find(id string) *Entry {
+	for _, e := range s.entries {
+		if e.ID == id {
+			return e
+		}
+	}
+	return nil
+}
+
+// save 先写临时文件再替换，避免写一半时进程退出导致文案库损坏
+func (s *Store) save() error {
+	if dir := filepath.Dir(s.path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("创建文案库目录失败: %w", err)
+		}
+	}
+
+	data, err := json.MarshalIndent(s.entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化文案库失败: %w", err)
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("写入文案库失败: %w", err)
+	}
+	return os.Rename(tmp, s.path)
+}
+
+func cloneEntry(e *Entry) Entry {
+	c := *e
+	c.Tags = append([]string(nil), e.Tags...)
+	c.Revisions = append([]Revision(nil), e.Revisions...)
+	return c
+}
+
+// recent 最近一次使用或修改的时间
+func recent(e Entry) time.Time {
+	if e.LastUsedAt.After(e.UpdatedAt) {
+		return e.LastUsedAt
+	}
+	return e.UpdatedAt
+}
+
+// cleanTags 去掉空标签和重复标签，保持原顺序
+func cleanTags(tags []string) []string {
+	var out []string
+	seen := make(map[string]bool, len(tags))
+	for _, t := range tags {
+		t = strings.TrimSpace(t)
+		if t == "" || seen[strings.ToLower(t)] {
+			continue
+		}
+		seen[strings.ToLower(t)] = true
+		out = append(out, t)
+	}
+	return out
+}
+
+func newEntryID() string {
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,105 @@
+package copylib
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// 文案模板变量: {product} 商品名，{price} 价格，{stem} 不含扩展名的文件名，
+// {part:N} 文件名按 _、-、空格切分后的第 N 段（从 1 开始）；其余变量取自提交时传入的 copyVars
+var placeholderRe = regexp.MustCompile(`\{([A-Za-z][A-Za-z0-9_]*)(?::(\d+))?\}`)
+
+// Variant 一个文案变体；Match 为空时参与轮流分配，否则分配给文件名匹配该通配符的素材
+type Variant struct {
+	Text  string
+	Match string
+}
+
+// Render 展开文案中的模板变量，变量未填写或文件名中没有对应的段时返回错误
+func Render(text string, vars map[string]string, fileName string) (string, error) {
+	base := path.Base(fileName)
+	stem := strings.TrimSuffix(base, path.Ext(base))
+
+	var missing []string
+	out := placeholderRe.ReplaceAllStringFunc(text, func(s string) string {
+		m := placeholderRe.FindStringSubmatch(s)
+		switch m[1] {
+		case "stem":
+			return stem
+		case "part":
+			n, _ := strconv.Atoi(m[2])
+			parts := strings.FieldsFunc(stem, func(r rune) bool { return r == '_' || r == '-' || r == ' ' })
+			if n < 1 || n > len(parts) {
+				missing = append(missing, fmt.Sprintf("%s（文件名 %s 没有第 %d 段）", s, base, n))
+				return s
+			}
+			return parts[n-1]
+		}
+		if v := strings.TrimSpace(vars[m[1]]); v != "" {
+			return v
+		}
+		missing = append(missing, s)
+		return s
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("文案变量未填写: %s", strings.Join(missing, "、"))
+	}
+	return out, nil
+}
+
+// Assign 为每个文件选择文案变体的下标：按顺序取第一个 Match 命中的变体（匹配文件名或完整路径），
+// 其余文件按顺序轮流使用没有 Match 的变体；都不适用时为 -1
+func Assign(variants []Variant, fileNames []string) ([]int, error) {
+	var pool []int
+	for i, v := range variants {
+		if v.Match == "" {
+			pool = append(pool, i)
+			continue
+		}
+		if _, err := path.Match(v.Match, ""); err != nil {
+			return nil, fmt.Errorf("文案变体 %d 的匹配规则有误: %s", i+1, v.Match)
+		}
+	}
+
+	assigned := make([]int, len(fileNames))
+	next := 0
+	for i, name := range fileNames {
+		assigned[i] = -1
+		for j, v := range variants {
+			if v.Match == "" {
+				continue
+			}
+			okBase, _ := path.Match(v.Match, path.Base(name))
+			okFull, _ := path.Match(v.Match, name)
+			if okBase || okFull {
+				assigned[i] = j
+				break
+			}
+		}
+		if assigned[i] < 0 && len(pool) > 0 {
+			assigned[i] = pool[next%len(pool)]
+			next++
+		}
+	}
+	return assigned, nil
+}
+
+// Variables 文案中的模板变量名，按出现顺序去重
+func Variables(text string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, m := range placeholderRe.FindAllStringSubmatch(text, -1) {
+		name := m[1]
+		if m[2] != "" {
+			name += ":" + m[2]
+		}
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
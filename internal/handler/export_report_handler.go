@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"net/http"
+	"net/url"
+
+	"jd_material_push/internal/logic"
+	"jd_material_push/internal/svc"
+	"jd_material_push/internal/types"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+func ExportReportHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.ExportReportRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+
+		l := logic.NewExportReportLogic(r.Context(), svcCtx)
+		resp, err := l.ExportReport(&req)
+		if err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+		if resp.Code != 200 {
+			httpx.OkJsonCtx(r.Context(), w, resp)
+			return
+		}
+
+		// 成功时以附件形式返回报告文件
+		w.Header().Set("Content-Type", resp.ContentType)
+		w.Header().Set("Content-Disposition", "attachment; filename*=UTF-8''"+url.PathEscape(resp.FileName))
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(resp.Content)
+	}
+}
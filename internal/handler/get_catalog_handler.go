@@ -0,0 +1,22 @@
+package handler
+
+import (
+	"net/http"
+
+	"jd_material_push/internal/logic"
+	"jd_material_push/internal/svc"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+func GetCatalogHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		l := logic.NewGetCatalogLogic(r.Context(), svcCtx)
+		resp, err := l.GetCatalog()
+		if err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+		} else {
+			httpx.OkJsonCtx(r.Context(), w, resp)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"net/http"
+
+	"jd_material_push/internal/logic"
+	"jd_material_push/internal/svc"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+func JobUploadHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// 以流的方式读取 multipart，文件直接写入暂存目录，不在内存中缓存整个请求
+		mr, err := r.MultipartReader()
+		if err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+
+		l := logic.NewJobUploadLogic(r.Context(), svcCtx)
+		resp, err := l.JobUpload(mr)
+		if err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+		} else {
+			httpx.OkJsonCtx(r.Context(), w, resp)
+		}
+	}
+}
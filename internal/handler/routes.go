@@ -1,10 +1,11 @@
-// Code generated by goctl. DO NOT EDIT.
+// Code scaffolded by goctl. Safe to edit.
 // goctl 1.9.2
 
 package handler
 
 import (
 	"net/http"
+	"time"
 
 	"jd_material_push/internal/svc"
 
@@ -12,6 +13,91 @@ import (
 )
 
 func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
+	server.AddRoutes(
+		[]rest.Route{
+			{
+				Method:  http.MethodGet,
+				Path:    "/api/catalog",
+				Handler: GetCatalogHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/api/names/preview",
+				Handler: PreviewNamesHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/api/copies",
+				Handler: ListCopiesHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/api/copies/save",
+				Handler: SaveCopyHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/api/copies/delete",
+				Handler: DeleteCopyHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/api/presets",
+				Handler: ListPresetsHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/api/presets/save",
+				Handler: SavePresetHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/api/presets/delete",
+				Handler: DeletePresetHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/api/presets/export",
+				Handler: ExportPresetsHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/api/presets/import",
+				Handler: ImportPresetsHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/api/history",
+				Handler: GetHistoryHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/api/history/detail",
+				Handler: GetHistoryDetailHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/api/history/report",
+				Handler: ExportReportHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/api/history/approval",
+				Handler: RecordApprovalHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/api/compliance/check",
+				Handler: ComplianceCheckHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/api/lexicon/reload",
+				Handler: ReloadLexiconHandler(serverCtx),
+			},
+		},
+	)
+
 	server.AddRoutes(
 		[]rest.Route{
 			{
@@ -24,6 +110,51 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 				Path:    "/api/submit-material-batch",
 				Handler: SubmitMaterialBatchHandler(serverCtx),
 			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/api/withdraw-material",
+				Handler: WithdrawMaterialHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/api/catalog/sync",
+				Handler: SyncCatalogHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/api/validate",
+				Handler: ValidateHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/api/jobs/submit",
+				Handler: SubmitJobHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/api/jobs/submit-held",
+				Handler: SubmitHeldHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/api/jobs/retry",
+				Handler: RetryJobHandler(serverCtx),
+			},
+		},
+		// 上传、提交和预检的耗时随文件数量增长，超时时间与浏览器上传相同
+		rest.WithTimeout(time.Duration(serverCtx.Config.Staging.TimeoutMinutes)*time.Minute),
+	)
+
+	server.AddRoutes(
+		[]rest.Route{
+			{
+				Method:  http.MethodPost,
+				Path:    "/api/jobs/upload",
+				Handler: JobUploadHandler(serverCtx),
+			},
 		},
+		// 请求体上限在文件配额之外预留 1MB 给任务清单和 multipart 头
+		rest.WithMaxBytes(serverCtx.Config.Staging.MaxUploadMB<<20+(1<<20)),
+		rest.WithTimeout(time.Duration(serverCtx.Config.Staging.TimeoutMinutes)*time.Minute),
 	)
 }
@@ -0,0 +1,233 @@
+package imagenorm
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	stddraw "image/draw"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"jd_material_push/internal/media"
+
+	"golang.org/x/image/draw"
+)
+
+// Options 上传前图片规整配置
+type Options struct {
+	Enabled     bool    `json:",optional"`                 // 是否启用
+	MaxLongEdge int     `json:",default=4096"`             // 长边像素上限，0 表示不限制
+	MaxSizeMB   float64 `json:",default=5"`                // 文件大小上限，0 表示不限制
+	Quality     int     `json:",default=90,range=[1:100]"` // JPEG 初始质量
+	MinQuality  int     `json:",default=60,range=[1:100]"` // 压缩体积时允许降到的最低质量
+}
+
+// Result 规整结果，原文件保持不变
+type Result struct {
+	Path         string   // 规整后的副本路径
+	Name         string   // 规整后的文件名（扩展名与新格式一致）
+	Size         int64    // 副本大小
+	OriginalSize int64    // 原文件大小
+	Actions      []string // 执行的处理，如 "WebP 转 JPEG"
+}
+
+// 压缩体积时最多缩小的次数和每次的比例
+const (
+	maxShrinkSteps = 5
+	shrinkRatio    = 0.8
+)
+
+// Normalize 按配置规整图片并把副本写入 workDir；无需处理（视频、动图或已符合要求）时返回 nil
+func Normalize(path, name, workDir string, opts Options) (*Result, error) {
+	typeInfo, err := media.DetectFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if typeInfo.MaterialType != media.MaterialTypeImage || typeInfo.Kind == media.KindGIF {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取图片失败: %w", err)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("解码图片失败: %w", err)
+	}
+
+	var actions []string
+	maxBytes := int64(opts.MaxSizeMB * 1024 * 1024)
+	tooLarge := maxBytes > 0 && int64(len(data)) > maxBytes
+
+	// 判断目标格式：有透明通道的保留 PNG，其余输出 JPEG；不透明 PNG 只在超出体积时转 JPEG
+	opaque := isOpaque(img)
+	format := media.KindJPEG
+	switch typeInfo.Kind {
+	case media.KindWebP:
+		if opaque {
+			actions = append(actions, "WebP 转 JPEG")
+		} else {
+			format = media.KindPNG
+			actions = append(actions, "WebP 转 PNG（保留透明通道）")
+		}
+	case media.KindPNG:
+		format = media.KindPNG
+		if opaque && tooLarge {
+			format = media.KindJPEG
+			actions = append(actions, "PNG 转 JPEG")
+		}
+	case media.KindJPEG:
+		if _, ok := img.(*image.CMYK); ok {
+			actions = append(actions, "CMYK 转 RGB")
+		}
+		if hasEXIF(data) {
+			actions = append(actions, "去除 EXIF/GPS")
+		}
+	}
+
+	if typeInfo.Kind == media.KindJPEG {
+		if o := exifOrientation(data); o > 1 {
+			img = applyOrientation(img, o)
+			actions = append(actions, "按 EXIF 方向旋转")
+		}
+	}
+
+	bounds := img.Bounds()
+	if long := max(bounds.Dx(), bounds.Dy()); opts.MaxLongEdge > 0 && long > opts.MaxLongEdge {
+		img = resize(img, float64(opts.MaxLongEdge)/float64(long))
+		actions = append(actions, fmt.Sprintf("缩放 %d×%d → %d×%d", bounds.Dx(), bounds.Dy(), img.Bounds().Dx(), img.Bounds().Dy()))
+	}
+
+	if len(actions) == 0 && !tooLarge {
+		return nil, nil
+	}
+
+	out, encodeActions, err := encode(img, format, opts, maxBytes)
+	if err != nil {
+		return nil, err
+	}
+	actions = append(actions, encodeActions...)
+
+	outName := strings.TrimSuffix(name, filepath.Ext(name)) + formatExt(format)
+	outPath := filepath.Join(workDir, outName)
+	if err := os.WriteFile(outPath, out, 0644); err != nil {
+		return nil, fmt.Errorf("写入规整后的图片失败: %w", err)
+	}
+
+	return &Result{
+		Path:         outPath,
+		Name:         outName,
+		Size:         int64(len(out)),
+		OriginalSize: int64(len(data)),
+		Actions:      actions,
+	}, nil
+}
+
+// encode 编码图片；超出体积上限时先降低 JPEG 质量，仍超出则逐步缩小尺寸
+func encode(img image.Image, format string, opts Options, maxBytes int64) ([]byte, []string, error) {
+	start := img.Bounds()
+	var out []byte
+	var quality int
+	for step := 0; ; step++ {
+		var err error
+		out, quality, err = encodeOnce(img, format, opts, maxBytes)
+		if err != nil {
+			return nil, nil, err
+		}
+		if maxBytes <= 0 || int64(len(out)) <= maxBytes || step == maxShrinkSteps {
+			break
+		}
+		img = resize(img, shrinkRatio)
+	}
+
+	var actions []string
+	if quality != 0 && quality != opts.Quality {
+		actions = append(actions, fmt.Sprintf("JPEG 质量降至 %d", quality))
+	}
+	if end := img.Bounds(); end.Dx() != start.Dx() || end.Dy() != start.Dy() {
+		actions = append(actions, fmt.Sprintf("为压缩体积缩小 %d×%d → %d×%d", start.Dx(), start.Dy(), end.Dx(), end.Dy()))
+	}
+	if maxBytes > 0 && int64(len(out)) > maxBytes {
+		actions = append(actions, fmt.Sprintf("压缩后仍超过 %g MB", opts.MaxSizeMB))
+	}
+	return out, actions, nil
+}
+
+// encodeOnce 按当前尺寸编码；JPEG 从初始质量每次降 10 直到符合体积或到达最低质量，PNG 返回的质量为 0
+func encodeOnce(img image.Image, format string, opts Options, maxBytes int64) ([]byte, int, error) {
+	if format == media.KindPNG {
+		out, err := encodePNG(img)
+		return out, 0, err
+	}
+
+	rgb := flatten(img)
+	for q := opts.Quality; ; q -= 10 {
+		if q < opts.MinQuality {
+			q = opts.MinQuality
+		}
+		out, err := encodeJPEG(rgb, q)
+		if err != nil {
+			return nil, 0, err
+		}
+		if maxBytes <= 0 || int64(len(out)) <= maxBytes || q <= opts.MinQuality {
+			return out, q, nil
+		}
+	}
+}
+
+func encodeJPEG(img image.Image, quality int) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, fmt.Errorf("编码 JPEG 失败: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func encodePNG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := png.Encoder{CompressionLevel: png.BestCompression}
+	if err := enc.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("编码 PNG 失败: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// flatten 将图片转为 RGB，透明区域填充白色（JPEG 不支持透明，CMYK 也在这里转换）
+func flatten(img image.Image) image.Image {
+	b := img.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	stddraw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, stddraw.Src)
+	stddraw.Draw(dst, dst.Bounds(), img, b.Min, stddraw.Over)
+	return dst
+}
+
+// resize 按比例缩放，使用 CatmullRom 插值
+func resize(img image.Image, scale float64) image.Image {
+	b := img.Bounds()
+	w := max(1, int(float64(b.Dx())*scale+0.5))
+	h := max(1, int(float64(b.Dy())*scale+0.5))
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, b, draw.Src, nil)
+	return dst
+}
+
+// isOpaque 图片是否完全不透明
+func isOpaque(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return o.Opaque()
+	}
+	return true
+}
+
+func formatExt(format string) string {
+	if format == media.KindPNG {
+		return ".png"
+	}
+	return ".jpg"
+}
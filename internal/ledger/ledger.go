@@ -0,0 +1,521 @@
+package ledger
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// 素材上传状态
+const (
+	UploadStatusUploaded = "uploaded"
+	UploadStatusFailed   = "failed"
+)
+
+// 素材提交状态
+const (
+	SubmitStatusNone           = ""
+	SubmitStatusSubmitted      = "submitted"
+	SubmitStatusFailed         = "failed"
+	SubmitStatusHeld           = "held" // 未满足任务的提交策略，暂缓提交
+	SubmitStatusWithdrawn      = "withdrawn"
+	SubmitStatusWithdrawFailed = "withdraw_failed"
+)
+
+// 素材审核状态，提交成功后为待审核；不从素材中心同步，审核结果由用户通过 /api/history/approval 手动录入
+const (
+	ApprovalStatusNone     = ""
+	ApprovalStatusPending  = "pending"
+	ApprovalStatusApproved = "approved"
+	ApprovalStatusRejected = "rejected"
+)
+
+// 任务状态，由素材记录汇总得出
+const (
+	JobStatusEmpty     = "empty"     // 没有素材
+	JobStatusUploaded  = "uploaded"  // 已上传未提交
+	JobStatusHeld      = "held"      // 有素材暂缓提交
+	JobStatusSuccess   = "success"   // 全部提交成功
+	JobStatusPartial   = "partial"   // 部分上传或提交失败
+	JobStatusFailed    = "failed"    // 全部失败
+	JobStatusWithdrawn = "withdrawn" // 已全部撤回
+)
+
+// MaterialRecord 单个素材在台账中的记录
+type MaterialRecord struct {
+	FileName       string    `json:"fileName"`
+	FilePath       string    `json:"filePath"`
+	FileSize       int64     `json:"fileSize"`
+	MaterialType   int       `json:"materialType"`
+	Width          int       `json:"width,omitempty"`
+	Height         int       `json:"height,omitempty"`
+	Duration       float64   `json:"duration,omitempty"`
+	Codec          string    `json:"codec,omitempty"`
+	UploadName     string    `json:"uploadName,omitempty"`
+	MaterialName   string    `json:"materialName,omitempty"`
+	ReleaseCopy    string    `json:"releaseCopy,omitempty"` // 附带文案，提交时作为该素材的投放文案
+	Thumbnail      string    `json:"thumbnail,omitempty"`   // 上传时生成的缩略图，ThumbnailDir 中的文件名
+	Steps          []StepLog `json:"steps,omitempty"`
+	URL            string    `json:"url"`
+	LocalURL       string    `json:"localUrl"`
+	UploadStatus   string    `json:"uploadStatus"`
+	SubmitStatus   string    `json:"submitStatus"`
+	BatchUUID      string    `json:"batchUuid"`
+	Message        string    `json:"message"`
+	ApprovalStatus string    `json:"approvalStatus,omitempty"` // 审核状态
+	ApprovalNote   string    `json:"approvalNote,omitempty"`   // 审核意见，如驳回原因
+	SubmittedAt    time.Time `json:"submittedAt"`              // 最近一次提交的时间
+	UpdatedAt      time.Time `json:"updatedAt"`
+}
+
+// StepLog 上传前处理步骤的执行记录
+type StepLog struct {
+	Step    string `json:"step"`
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+	Output  string `json:"output"`
+}
+
+// CopyVariant 提交时使用的投放文案变体，文案库中的文案已展开为内容
+type CopyVariant struct {
+	Text  string `json:"text"`
+	Match string `json:"match,omitempty"`
+}
+
+// Job 一次推送任务
+type Job struct {
+	ID           string           `json:"id"`
+	FolderPath   string           `json:"folderPath"`
+	Account      string           `json:"account,omitempty"` // 推送时登录的京东账号
+	MediaList    []string         `json:"mediaList"`
+	CategoryList []string         `json:"categoryList"`
+	ReleaseCopy  string           `json:"releaseCopy"`
+	Materials    []MaterialRecord `json:"materials"`
+
+	// 上传和提交时保存的其他设置，补交和重试时沿用
+	Columns            map[string]interface{} `json:"columns,omitempty"`
+	IsolateFailures    bool                   `json:"isolateFailures,omitempty"`
+	CategoryByFolder   bool                   `json:"categoryByFolder,omitempty"`
+	SubmitPolicy       string                 `json:"submitPolicy,omitempty"`
+	MinUploadedPercent int                    `json:"minUploadedPercent,omitempty"`
+	NameTemplate       string                 `json:"nameTemplate,omitempty"`
+	Campaign           string                 `json:"campaign,omitempty"`
+	CopyVariants       []CopyVariant          `json:"copyVariants,omitempty"`
+	CopyVars           map[string]string      `json:"copyVars,omitempty"`
+	Profile            string                 `json:"profile,omitempty"`
+	Order              string                 `json:"order,omitempty"` // 上传顺序，为空时为配置的 UploadOrder
+
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Material 按 URL 查找任务内的素材记录
+func (j *Job) Material(url string) *MaterialRecord {
+	for i := range j.Materials {
+		if j.Materials[i].URL == url {
+			return &j.Materials[i]
+		}
+	}
+	return nil
+}
+
+// Status 汇总素材记录得出任务状态
+func (j *Job) Status() string {
+	if len(j.Materials) == 0 {
+		return JobStatusEmpty
+	}
+	var submitted, failed, held, withdrawn int
+	for _, rec := range j.Materials {
+		switch {
+		case rec.UploadStatus == UploadStatusFailed, rec.SubmitStatus == SubmitStatusFailed:
+			failed++
+		case rec.SubmitStatus == SubmitStatusHeld:
+			held++
+		case rec.SubmitStatus == SubmitStatusWithdrawn:
+			withdrawn++
+		case rec.SubmitStatus == SubmitStatusSubmitted, rec.SubmitStatus == SubmitStatusWithdrawFailed:
+			submitted++
+		}
+	}
+	switch {
+	case held > 0:
+		return JobStatusHeld
+	case withdrawn == len(j.Materials):
+		return JobStatusWithdrawn
+	case submitted == 0 && failed == 0:
+		return JobStatusUploaded
+	case failed == len(j.Materials):
+		return JobStatusFailed
+	case failed > 0:
+		return JobStatusPartial
+	}
+	return JobStatusSuccess
+}
+
+// UpsertMaterial 新增或覆盖素材记录：有 URL 时按 URL 对应，同名但 URL 不同的素材各自保留；
+// 同名且尚无 URL 的记录（上传失败）由本次记录覆盖，重试上传成功后不会留下失败记录
+func (j *Job) UpsertMaterial(rec MaterialRecord) {
+	rec.UpdatedAt = time.Now()
+	for i := range j.Materials {
+		cur := &j.Materials[i]
+		if (rec.URL != "" && cur.URL == rec.URL) || (cur.URL == "" && cur.FileName == rec.FileName) {
+			*cur = rec
+			return
+		}
+	}
+	j.Materials = append(j.Materials, rec)
+}
+
+// Store 本地推送台账，以追加写入的 JSON Lines 文件持久化：每次修改只在文件末尾追加该任务的最新快照，
+// 读取时同一任务以最后一行为准，历史再长单次写入的开销也只与该任务有关；
+// 文件行数超过任务数的 2 倍时压缩，每个任务只保留最新快照
+type Store struct {
+	path  string
+	mu    sync.RWMutex
+	jobs  []*Job
+	lines int // 台账文件当前的行数
+}
+
+// NewStore 打开台账文件，文件不存在时创建空台账；被覆盖的旧快照超过任务数时先压缩文件。
+// 兼容旧版整个文件为一个 JSON 数组的台账，打开时转换为追加格式
+func NewStore(path string) (*Store, error) {
+	s := &Store{path: path}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取台账失败: %w", err)
+	}
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return s, nil
+	}
+
+	if data[0] == '[' {
+		if err := json.Unmarshal(data, &s.jobs); err != nil {
+			return nil, fmt.Errorf("解析台账失败: %w", err)
+		}
+		return s, s.compact()
+	}
+
+	index := make(map[string]int)
+	lines := bytes.Split(data, []byte("\n"))
+	truncated := false
+	for i, line := range lines {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		job := &Job{}
+		if err := json.Unmarshal(line, job); err != nil {
+			// 最后一行可能是写到一半时进程退出留下的，丢弃后重写文件；其他行有误说明文件已损坏
+			if i == len(lines)-1 {
+				truncated = true
+				continue
+			}
+			return nil, fmt.Errorf("解析台账第 %d 行失败: %w", i+1, err)
+		}
+		s.lines++
+		if n, ok := index[job.ID]; ok {
+			s.jobs[n] = job
+			continue
+		}
+		index[job.ID] = len(s.jobs)
+		s.jobs = append(s.jobs, job)
+	}
+
+	if truncated || s.lines > 2*len(s.jobs) {
+		return s, s.compact()
+	}
+	return s, nil
+}
+
+// CreateJob 新建任务并落盘，account 为推送时登录的京东账号
+func (s *Store) CreateJob(folderPath, account string) (Job, error) {
+	now := time.Now()
+	job := &Job{
+		ID:         newJobID(now),
+		FolderPath: folderPath,
+		Account:    account,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.appendJobs(job); err != nil {
+		return Job{}, err
+	}
+	s.jobs = append(s.jobs, job)
+	return cloneJob(job), nil
+}
+
+// Get 获取任务副本
+func (s *Store) Get(jobID string) (Job, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	job := s.find(jobID)
+	if job == nil {
+		return Job{}, false
+	}
+	return cloneJob(job), true
+}
+
+// List 按创建时间返回全部任务副本
+func (s *Store) List() []Job {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	jobs := make([]Job, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		jobs = append(jobs, cloneJob(job))
+	}
+	return jobs
+}
+
+// Filter 查询推送历史的条件，为空的条件不限制
+type Filter struct {
+	From     time.Time // 创建时间不早于
+	To       time.Time // 创建时间早于
+	Account  string    // 京东账号
+	Media    string    // 包含该投放媒体
+	Category string    // 包含该素材品类
+	Status   string    // 任务状态，见 JobStatus*
+	FileName string    // 文件名或素材名称包含该内容，不区分大小写
+}
+
+// Query 返回满足条件的任务副本，最新创建的在前
+func (s *Store) Query(f Filter) []Job {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var jobs []Job
+	for i := len(s.jobs) - 1; i >= 0; i-- {
+		if job := s.jobs[i]; f.match(job) {
+			jobs = append(jobs, cloneJob(job))
+		}
+	}
+	return jobs
+}
+
+func (f Filter) match(job *Job) bool {
+	switch {
+	case !f.From.IsZero() && job.CreatedAt.Before(f.From),
+		!f.To.IsZero() && !job.CreatedAt.Before(f.To),
+		f.Account != "" && job.Account != f.Account,
+		f.Media != "" && !slices.Contains(job.MediaList, f.Media),
+		f.Category != "" && !slices.Contains(job.CategoryList, f.Category),
+		f.Status != "" && job.Status() != f.Status:
+		return false
+	}
+	if f.FileName == "" {
+		return true
+	}
+	keyword := strings.ToLower(f.FileName)
+	for _, rec := range job.Materials {
+		if strings.Contains(strings.ToLower(rec.FileName), keyword) || strings.Contains(strings.ToLower(rec.MaterialName), keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// Update 在锁内修改任务并落盘；修改在副本上进行，写入失败时内存中的任务保持不变
+func (s *Store) Update(jobID string, fn func(job *Job)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.index(jobID)
+	if i < 0 {
+		return fmt.Errorf("任务不存在: %s", jobID)
+	}
+	job := cloneJob(s.jobs[i])
+	fn(&job)
+	job.UpdatedAt = time.Now()
+	if err := s.appendJobs(&job); err != nil {
+		return err
+	}
+	s.jobs[i] = &job
+	s.compactIfNeeded()
+	return nil
+}
+
+// UpdateMaterialByURL 修改所有任务中 URL 匹配的素材记录，返回命中数量；写入失败时不修改
+func (s *Store) UpdateMaterialByURL(url string, fn func(rec *MaterialRecord)) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	changed := make(map[int]*Job)
+	var snapshots []*Job
+	now := time.Now()
+	for i, cur := range s.jobs {
+		if cur.Material(url) == nil {
+			continue
+		}
+		job := cloneJob(cur)
+		rec := job.Material(url)
+		fn(rec)
+		rec.UpdatedAt = now
+		job.UpdatedAt = now
+		changed[i] = &job
+		snapshots = append(snapshots, &job)
+	}
+	if err := s.appendJobs(snapshots...); err != nil {
+		return 0, err
+	}
+	for i, job := range changed {
+		s.jobs[i] = job
+	}
+	s.compactIfNeeded()
+	return len(changed), nil
+}
+
+func (s *Store) find(jobID string) *Job {
+	if i := s.index(jobID); i >= 0 {
+		return s.jobs[i]
+	}
+	return nil
+}
+
+func (s *Store) index(jobID string) int {
+	for i, job := range s.jobs {
+		if job.ID == jobID {
+			return i
+		}
+	}
+	return -1
+}
+
+// appendJobs 在台账文件末尾追加任务的最新快照，每个任务一行
+func (s *Store) appendJobs(jobs ...*Job) error {
+	if len(jobs) == 0 {
+		return nil
+	}
+	var buf bytes.Buffer
+	for _, job := range jobs {
+		line, err := json.Marshal(job)
+		if err != nil {
+			return fmt.Errorf("序列化台账失败: %w", err)
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+
+	if err := s.mkdir(); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("写入台账失败: %w", err)
+	}
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		f.Close()
+		return fmt.Errorf("写入台账失败: %w", err)
+	}
+	s.lines += len(jobs)
+	return f.Close()
+}
+
+// compactIfNeeded 被覆盖的旧快照超过任务数时压缩台账；快照已追加成功，压缩失败只记录日志，下次写入时再试
+func (s *Store) compactIfNeeded() {
+	if s.lines <= 2*len(s.jobs) {
+		return
+	}
+	if err := s.compact(); err != nil {
+		logx.Errorf("压缩台账失败: %v", err)
+	}
+}
+
+// compact 每个任务只保留最新快照重写台账，先写临时文件再替换，避免写一半时进程退出导致台账损坏
+func (s *Store) compact() error {
+	var buf bytes.Buffer
+	for _, job := range s.jobs {
+		line, err := json.Marshal(job)
+		if err != nil {
+			return fmt.Errorf("序列化台账失败: %w", err)
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+
+	if err := s.mkdir(); err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("写入台账失败: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return err
+	}
+	s.lines = len(s.jobs)
+	return nil
+}
+
+func (s *Store) mkdir() error {
+	if dir := filepath.Dir(s.path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("创建台账目录失败: %w", err)
+		}
+	}
+	return nil
+}
+
+// cloneJob 深拷贝任务，调用方修改副本不会影响台账
+func cloneJob(job *Job) Job {
+	c := *job
+	c.MediaList = append([]string(nil), job.MediaList...)
+	c.CategoryList = append([]string(nil), job.CategoryList...)
+	c.Materials = append([]MaterialRecord(nil), job.Materials...)
+	for i := range c.Materials {
+		c.Materials[i].Steps = slices.Clone(c.Materials[i].Steps)
+	}
+	if job.Columns != nil {
+		c.Columns = cloneValue(job.Columns).(map[string]interface{})
+	}
+	c.CopyVariants = slices.Clone(job.CopyVariants)
+	c.CopyVars = maps.Clone(job.CopyVars)
+	return c
+}
+
+// cloneValue 深拷贝 applyAttr 列的取值，取值来自 JSON 解码或请求参数
+func cloneValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		c := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			c[k] = cloneValue(item)
+		}
+		return c
+	case []interface{}:
+		c := make([]interface{}, len(v))
+		for i, item := range v {
+			c[i] = cloneValue(item)
+		}
+		return c
+	case []string:
+		return slices.Clone(v)
+	}
+	return v
+}
+
+func newJobID(now time.Time) string {
+	b := make([]byte, 3)
+	_, _ = rand.Read(b)
+	return now.Format("20060102150405") + "-" + hex.EncodeToString(b)
+}
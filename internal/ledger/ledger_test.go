@@ -0,0 +1,249 @@
+package ledger
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// countLines 台账文件的行数
+func countLines(t *testing.T, path string) int {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bytes.Count(data, []byte("\n"))
+}
+
+func TestStoreRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ledger.jsonl")
+	s, err := NewStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := s.CreateJob("a", "pin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := s.CreateJob("b", "pin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = s.Update(a.ID, func(job *Job) {
+		job.MediaList = []string{"jlyq"}
+		job.Columns = map[string]interface{}{"tags": []interface{}{"x"}}
+		job.UpsertMaterial(MaterialRecord{FileName: "1.jpg", URL: "u1", UploadStatus: UploadStatusUploaded})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, err := s.UpdateMaterialByURL("u1", func(rec *MaterialRecord) { rec.SubmitStatus = SubmitStatusSubmitted }); err != nil || n != 1 {
+		t.Fatalf("UpdateMaterialByURL = %d, %v，应为 1", n, err)
+	}
+	if got := countLines(t, path); got != 4 {
+		t.Errorf("台账 %d 行，应为每次修改追加一行共 4 行", got)
+	}
+
+	reopened, err := NewStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(jobIDs(reopened.List()), []string{a.ID, b.ID}) {
+		t.Errorf("重新打开后的任务 = %v，应为 %v", jobIDs(reopened.List()), []string{a.ID, b.ID})
+	}
+	want, _ := s.Get(a.ID)
+	got, _ := reopened.Get(a.ID)
+	wantJSON, _ := json.Marshal(want)
+	gotJSON, _ := json.Marshal(got)
+	if !bytes.Equal(gotJSON, wantJSON) {
+		t.Errorf("重新打开后的任务 = %s，应为 %s", gotJSON, wantJSON)
+	}
+}
+
+func TestStoreTruncatedLastLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ledger.jsonl")
+	s, err := NewStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	job, err := s.CreateJob("a", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"id":"` + job.ID + `","folderPath":"b`)
+	f.Close()
+
+	reopened, err := NewStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := reopened.Get(job.ID); !ok || got.FolderPath != "a" {
+		t.Errorf("任务 = %+v，应保留写入完整的快照", got)
+	}
+	if got := countLines(t, path); got != 1 {
+		t.Errorf("台账 %d 行，应丢弃写到一半的行后重写为 1 行", got)
+	}
+
+	// 中间的行有误说明文件已损坏，不能丢弃
+	os.WriteFile(path, []byte("{\n{\"id\":\"x\"}\n"), 0644)
+	if _, err := NewStore(path); err == nil {
+		t.Error("中间的行有误时应返回错误")
+	}
+}
+
+func TestStoreCompact(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ledger.jsonl")
+	s, err := NewStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, _ := s.CreateJob("a", "")
+	b, _ := s.CreateJob("b", "")
+	for i := 0; i < 2; i++ {
+		if err := s.Update(a.ID, func(job *Job) { job.ReleaseCopy += "x" }); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 4 行 2 个任务，未超过任务数的 2 倍时不压缩
+	if _, err := NewStore(path); err != nil {
+		t.Fatal(err)
+	}
+	if got := countLines(t, path); got != 4 {
+		t.Errorf("台账 %d 行，未超过任务数 2 倍时应保持 4 行", got)
+	}
+
+	// 运行中超过任务数的 2 倍时即压缩，不必等到重新打开
+	if err := s.Update(a.ID, func(job *Job) { job.ReleaseCopy += "x" }); err != nil {
+		t.Fatal(err)
+	}
+	if got := countLines(t, path); got != 2 {
+		t.Errorf("台账 %d 行，超过任务数 2 倍时应压缩为每个任务 1 行", got)
+	}
+	reopened, err := NewStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := reopened.Get(a.ID); got.ReleaseCopy != "xxx" {
+		t.Errorf("压缩后的文案 = %q，应为最新快照 %q", got.ReleaseCopy, "xxx")
+	}
+	if !reflect.DeepEqual(jobIDs(reopened.List()), []string{a.ID, b.ID}) {
+		t.Errorf("压缩后的任务 = %v，应保持创建顺序", jobIDs(reopened.List()))
+	}
+}
+
+func TestStoreLegacyArray(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ledger.json")
+	legacy := `[
+  {"id": "1", "folderPath": "a", "materials": [{"fileName": "1.jpg", "url": "u1"}]},
+  {"id": "2", "folderPath": "b"}
+]`
+	if err := os.WriteFile(path, []byte(legacy), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := NewStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(jobIDs(s.List()), []string{"1", "2"}) {
+		t.Errorf("任务 = %v，应为 [1 2]", jobIDs(s.List()))
+	}
+	if got := countLines(t, path); got != 2 {
+		t.Errorf("转换后 %d 行，应为每个任务一行", got)
+	}
+	if err := s.Update("2", func(job *Job) { job.ReleaseCopy = "x" }); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := NewStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := reopened.Get("1"); len(got.Materials) != 1 || got.Materials[0].URL != "u1" {
+		t.Errorf("转换后的素材记录 = %+v", got.Materials)
+	}
+	if got, _ := reopened.Get("2"); got.ReleaseCopy != "x" {
+		t.Errorf("转换后追加的修改丢失: %+v", got)
+	}
+}
+
+func TestStoreCopiesAreIndependent(t *testing.T) {
+	s, err := NewStore(filepath.Join(t.TempDir(), "ledger.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	job, _ := s.CreateJob("a", "")
+	err = s.Update(job.ID, func(job *Job) {
+		job.Columns = map[string]interface{}{"tags": []interface{}{"x"}}
+		job.CopyVars = map[string]string{"k": "v"}
+		job.CopyVariants = []CopyVariant{{Text: "t"}}
+		job.UpsertMaterial(MaterialRecord{FileName: "1.jpg", URL: "u1", Steps: []StepLog{{Step: "s"}}})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, _ := s.Get(job.ID)
+	got.Columns["tags"].([]interface{})[0] = "y"
+	got.Columns["other"] = 1
+	got.CopyVars["k"] = "changed"
+	got.CopyVariants[0].Text = "changed"
+	got.Materials[0].Steps[0].Step = "changed"
+
+	stored, _ := s.Get(job.ID)
+	if stored.Columns["tags"].([]interface{})[0] != "x" || len(stored.Columns) != 1 ||
+		stored.CopyVars["k"] != "v" || stored.CopyVariants[0].Text != "t" || stored.Materials[0].Steps[0].Step != "s" {
+		t.Errorf("修改副本影响了台账中的任务: %+v", stored)
+	}
+}
+
+func TestStoreUpdateWriteFailure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ledger.jsonl")
+	s, err := NewStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	job, _ := s.CreateJob("a", "")
+	s.Update(job.ID, func(job *Job) {
+		job.UpsertMaterial(MaterialRecord{FileName: "1.jpg", URL: "u1"})
+	})
+
+	// 台账路径变成目录后无法追加写入
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(path, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Update(job.ID, func(job *Job) { job.ReleaseCopy = "x" }); err == nil {
+		t.Fatal("写入失败时 Update 应返回错误")
+	}
+	if _, err := s.UpdateMaterialByURL("u1", func(rec *MaterialRecord) { rec.Message = "x" }); err == nil {
+		t.Fatal("写入失败时 UpdateMaterialByURL 应返回错误")
+	}
+	if _, err := s.CreateJob("b", ""); err == nil {
+		t.Fatal("写入失败时 CreateJob 应返回错误")
+	}
+	got, _ := s.Get(job.ID)
+	if got.ReleaseCopy != "" || got.Materials[0].Message != "" || len(s.List()) != 1 {
+		t.Errorf("写入失败后内存中的台账被修改: %+v", s.List())
+	}
+}
+
+func jobIDs(jobs []Job) []string {
+	ids := make([]string, 0, len(jobs))
+	for _, job := range jobs {
+		ids = append(ids, job.ID)
+	}
+	return ids
+}
@@ -0,0 +1,294 @@
+package lexicon
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/conf"
+)
+
+// 命中词的严重程度，与预检结果一致
+const (
+	SeverityError   = "error"   // 阻止提交
+	SeverityWarning = "warning" // 提示但允许提交
+)
+
+// 处理方案的合规检查方式
+const (
+	ModeDefault = ""      // 按词库中每个词的级别
+	ModeBlock   = "block" // 命中即阻止
+	ModeWarn    = "warn"  // 命中只提示
+	ModeOff     = "off"   // 不检查
+)
+
+// Group 词库中的一组违禁词，同组共用类别、级别和替换建议
+type Group struct {
+	Words    []string
+	Category string   `json:",optional"`                            // 类别，如 极限词
+	Severity string   `json:",default=error,options=error|warning"` // 命中时的级别
+	Suggest  []string `json:",optional"`                            // 替换建议，为空时建议删除
+	Note     string   `json:",optional"`                            // 说明，如对应的广告法条款
+}
+
+// Lexicon 违禁词词库
+type Lexicon struct {
+	terms   []term
+	allow   []string
+	missing bool
+}
+
+type term struct {
+	word  []rune
+	group *Group
+}
+
+// Hit 文本中命中的一个违禁词
+type Hit struct {
+	Term     string   // 命中的词（原文）
+	Category string   // 类别
+	Severity string   // 级别
+	Suggest  []string // 替换建议
+	Note     string   // 说明
+	Start    int      // 在文本中的起始位置（字符）
+	End      int      // 结束位置（字符，不含）
+}
+
+// New 由词组和白名单创建词库；白名单中的短语（如 最近、第一次）内出现的词不算命中
+func New(groups []Group, allow []string) (*Lexicon, error) {
+	l := &Lexicon{}
+	for i := range groups {
+		g := &groups[i]
+		if g.Severity == "" {
+			g.Severity = SeverityError
+		}
+		if g.Severity != SeverityError && g.Severity != SeverityWarning {
+			return nil, fmt.Errorf("词组 %d 的级别有误: %s", i+1, g.Severity)
+		}
+		for _, w := range g.Words {
+			if w = strings.TrimSpace(w); w != "" {
+				l.terms = append(l.terms, term{word: []rune(strings.ToLower(w)), group: g})
+			}
+		}
+	}
+	for _, a := range allow {
+		if a = strings.TrimSpace(a); a != "" {
+			l.allow = append(l.allow, strings.ToLower(a))
+		}
+	}
+	// 同一位置优先匹配最长的词，如 最低价 优先于 最
+	sort.SliceStable(l.terms, func(i, j int) bool { return len(l.terms[i].word) > len(l.terms[j].word) })
+	return l, nil
+}
+
+// Load 加载词库文件，文件不存在时返回空词库，Missing 为 true
+func Load(path string) (*Lexicon, error) {
+	var c struct {
+		Allow []string `json:",optional"`
+		Terms []Group  `json:",optional"`
+	}
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return &Lexicon{missing: true}, nil
+	}
+	if err := conf.Load(path, &c); err != nil {
+		return nil, fmt.Errorf("加载违禁词词库失败: %w", err)
+	}
+	return New(c.Terms, c.Allow)
+}
+
+// Size 词库中的词数
+func (l *Lexicon) Size() int {
+	return len(l.terms)
+}
+
+// Missing 词库文件不存在，不会命中任何词
+func (l *Lexicon) Missing() bool {
+	return l.missing
+}
+
+// Check 返回文本中命中的违禁词，按出现顺序；英文不区分大小写
+func (l *Lexicon) Check(text string) []Hit {
+	if l == nil || len(l.terms) == 0 || text == "" {
+		return nil
+	}
+	orig := []rune(text)
+	lower := []rune(strings.ToLower(text))
+	if len(lower) != len(orig) {
+		lower = orig
+	}
+	allowed := l.allowedMask(lower)
+
+	var hits []Hit
+	for i := 0; i < len(lower); {
+		matched := false
+		for _, t := range l.terms {
+			end := i + len(t.word)
+			if end > len(lower) || !equalRunes(lower[i:end], t.word) || covered(allowed, i, end) {
+				continue
+			}
+			hits = append(hits, Hit{
+				Term:     string(orig[i:end]),
+				Category: t.group.Category,
+				Severity: t.group.Severity,
+				Suggest:  t.group.Suggest,
+				Note:     t.group.Note,
+				Start:    i,
+				End:      end,
+			})
+			i = end
+			matched = true
+			break
+		}
+		if !matched {
+			i++
+		}
+	}
+	return hits
+}
+
+// allowedMask 标出文本中属于白名单短语的位置
+func (l *Lexicon) allowedMask(text []rune) []bool {
+	mask := make([]bool, len(text))
+	for _, a := range l.allow {
+		phrase := []rune(a)
+		for i := 0; i+len(phrase) <= len(text); i++ {
+			if equalRunes(text[i:i+len(phrase)], phrase) {
+				for j := i; j < i+len(phrase); j++ {
+					mask[j] = true
+				}
+			}
+		}
+	}
+	return mask
+}
+
+func covered(mask []bool, start, end int) bool {
+	for i := start; i < end; i++ {
+		if !mask[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func equalRunes(a, b []rune) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Highlight 用【】标出文本中命中的词
+func Highlight(text string, hits []Hit) string {
+	runes := []rune(text)
+	var b strings.Builder
+	last := 0
+	for _, h := range hits {
+		b.WriteString(string(runes[last:h.Start]))
+		b.WriteString("【" + string(runes[h.Start:h.End]) + "】")
+		last = h.End
+	}
+	b.WriteString(string(runes[last:]))
+	return b.String()
+}
+
+// Describe 说明命中的词和替换建议，如 「最佳」（极限词），建议改为 优选/出色
+func Describe(h Hit) string {
+	text := "「" + h.Term + "」"
+	if h.Category != "" {
+		text += "（" + h.Category + "）"
+	}
+	if len(h.Suggest) > 0 {
+		return text + "，建议改为 " + strings.Join(h.Suggest, "/")
+	}
+	text += "，建议删除"
+	if h.Note != "" {
+		text += "；" + h.Note
+	}
+	return text
+}
+
+// Severity 按处理方案的检查方式得到命中词的实际级别，off 时返回空
+func Severity(h Hit, mode string) string {
+	switch mode {
+	case ModeBlock:
+		return SeverityError
+	case ModeWarn:
+		return SeverityWarning
+	case ModeOff:
+		return ""
+	}
+	return h.Severity
+}
+
+// Store 可热加载的词库：每次取用时检查文件修改时间，变化后重新加载；加载失败时继续使用上一版词库
+type Store struct {
+	path    string
+	mu      sync.Mutex
+	current *Lexicon
+	modTime time.Time
+	lastErr error
+}
+
+// NewStore 加载词库文件，首次加载失败时返回错误
+func NewStore(path string) (*Store, error) {
+	s := &Store{path: path}
+	if _, err := s.Reload(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Current 返回当前词库，文件有变化时先重新加载
+func (s *Store) Current() *Lexicon {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if info, err := os.Stat(s.path); err == nil && !info.ModTime().Equal(s.modTime) {
+		s.reload()
+	}
+	return s.current
+}
+
+// Reload 立即重新加载词库，返回新词库；失败时保留原词库并返回错误
+func (s *Store) Reload() (*Lexicon, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.reload(); err != nil {
+		return s.current, err
+	}
+	return s.current, nil
+}
+
+// LastError 最近一次加载失败的原因，成功后为 nil
+func (s *Store) LastError() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastErr
+}
+
+func (s *Store) reload() error {
+	var modTime time.Time
+	if info, err := os.Stat(s.path); err == nil {
+		modTime = info.ModTime()
+	}
+	lex, err := Load(s.path)
+	// 无论成功与否都记下修改时间，避免同一个有误的文件被反复加载
+	s.modTime = modTime
+	s.lastErr = err
+	if err != nil {
+		return err
+	}
+	s.current = lex
+	return nil
+}
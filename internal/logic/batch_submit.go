@@ -0,0 +1,340 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"jd_material_push/internal/applyattr"
+	"jd_material_push/internal/catalog"
+	"jd_material_push/internal/source"
+	"jd_material_push/internal/svc"
+	"jd_material_push/internal/types"
+)
+
+// submitBatchSize 素材中心单次提交的素材数上限
+const submitBatchSize = 20
+
+// isolateMaxCalls 开启 isolateFailures 时每批最多调用素材中心的次数（含首次提交），
+// 拒绝原因不指明具体素材时，所有素材都被拒绝的批次也不会无限拆分下去
+const isolateMaxCalls = 24
+
+// submitBatch 一次 extAddMaterial 调用：同一批素材共用一个 applyAttr
+type submitBatch struct {
+	index     int // 批次序号，从 1 开始
+	applyAttr string
+	items     []types.MaterialItem
+}
+
+// planBatches 为每个素材生成 applyAttr，按 applyAttr 相同（groupByType 时还要求素材类型相同）分组，
+// 组内按 20 个一批切分；分组和批次保持素材在请求中的先后顺序
+func planBatches(columns []catalog.Column, req *types.SubmitMaterialBatchRequest) ([]submitBatch, error) {
+	type group struct {
+		applyAttr string
+		items     []types.MaterialItem
+	}
+	var groups []*group
+	byKey := make(map[string]*group)
+	cache := make(map[string]string) // 投放设置 → applyAttr，避免重复构建
+
+	for _, item := range req.MaterialList {
+		mediaList, categoryList, releaseCopy := req.MediaList, req.CategoryList, req.ReleaseCopy
+		if len(item.MediaList) > 0 {
+			mediaList = item.MediaList
+		}
+		if len(item.CategoryList) > 0 {
+			categoryList = item.CategoryList
+		}
+		if item.ReleaseCopy != "" {
+			releaseCopy = item.ReleaseCopy
+		}
+
+		settings := fmt.Sprintf("%q|%q|%q", mediaList, categoryList, releaseCopy)
+		applyAttr, ok := cache[settings]
+		if !ok {
+			var err error
+			applyAttr, err = applyattr.Build(columns, applyattr.Values(mediaList, categoryList, releaseCopy, req.Columns))
+			if err != nil {
+				if len(item.MediaList) > 0 || len(item.CategoryList) > 0 || item.ReleaseCopy != "" {
+					return nil, fmt.Errorf("%s: %w", item.MaterialName, err)
+				}
+				return nil, err
+			}
+			cache[settings] = applyAttr
+		}
+
+		key := applyAttr
+		if req.GroupByType {
+			key = fmt.Sprintf("%d|%s", item.MaterialType, applyAttr)
+		}
+		g := byKey[key]
+		if g == nil {
+			g = &group{applyAttr: applyAttr}
+			byKey[key] = g
+			groups = append(groups, g)
+		}
+		g.items = append(g.items, item)
+	}
+
+	var batches []submitBatch
+	for _, g := range groups {
+		for start := 0; start < len(g.items); start += submitBatchSize {
+			end := min(start+submitBatchSize, len(g.items))
+			batches = append(batches, submitBatch{index: len(batches) + 1, applyAttr: g.applyAttr, items: g.items[start:end]})
+		}
+	}
+	return batches, nil
+}
+
+// attempt 一次 extAddMaterial 调用及其结果，调用出错时 resp 为 nil
+type attempt struct {
+	items []types.MaterialItem
+	resp  *types.SubmitMaterialResponse
+	err   error
+}
+
+func (a attempt) succeeded() bool {
+	return a.err == nil && a.resp.Code == 200 && a.resp.Result
+}
+
+// transient 是否为临时失败：调用出错（网络、Cookie、响应无法解析）或素材中心返回 5xx，这类失败拆分重试没有意义
+func (a attempt) transient() bool {
+	return a.err != nil || a.resp.Code >= 500
+}
+
+// batchWideRejections 素材中心拒绝原因中表明投放设置有误的关键字，这类拒绝与具体素材无关，拆分后仍会被拒绝
+var batchWideRejections = []string{"applyAttr", "投放媒体", "品类"}
+
+// batchWide 是否因投放媒体、品类等整批共用的设置被拒绝
+func (a attempt) batchWide() bool {
+	if a.succeeded() || a.transient() {
+		return false
+	}
+	for _, key := range batchWideRejections {
+		if strings.Contains(a.resp.Message, key) {
+			return true
+		}
+	}
+	return false
+}
+
+func (a attempt) message() string {
+	if a.err != nil {
+		return a.err.Error()
+	}
+	return a.resp.Message
+}
+
+// aggregate 汇总每批和每个素材的提交结果；results 与 batches 按下标对应，每批可能因拆分而包含多次调用
+func aggregate(batches []submitBatch, results [][]attempt) ([]types.SubmitBatchResult, []types.MaterialOutcome) {
+	var batchResults []types.SubmitBatchResult
+	var outcomes []types.MaterialOutcome
+	for i, b := range batches {
+		attempts := results[i]
+		result := types.SubmitBatchResult{Batch: b.index, Success: true}
+		for _, a := range attempts {
+			uuid := ""
+			if a.resp != nil {
+				uuid = a.resp.UUID
+			}
+			for _, item := range a.items {
+				result.Materials = append(result.Materials, item.MaterialName)
+				if !a.succeeded() {
+					result.Rejected = append(result.Rejected, item.MaterialName)
+				}
+				outcomes = append(outcomes, types.MaterialOutcome{
+					MaterialName: item.MaterialName,
+					URL:          item.URL,
+					Batch:        b.index,
+					Success:      a.succeeded(),
+					Message:      a.message(),
+					UUID:         uuid,
+				})
+			}
+			if !a.succeeded() {
+				result.Success = false
+			}
+		}
+
+		if len(attempts) == 1 {
+			result.Message = attempts[0].message()
+			if attempts[0].resp != nil {
+				result.UUID = attempts[0].resp.UUID
+			}
+		} else {
+			result.Message = fmt.Sprintf("批次被拒绝，拆分为 %d 次提交后定位到 %d 个被拒绝的素材", len(attempts), len(result.Rejected))
+		}
+		batchResults = append(batchResults, result)
+	}
+	return batchResults, outcomes
+}
+
+// summarize 汇总多批或拆分后的提交结果，Result 表示是否全部成功
+func summarize(req *types.SubmitMaterialBatchRequest, batches []submitBatch, results [][]attempt) *types.SubmitMaterialResponse {
+	resp := &types.SubmitMaterialResponse{Code: 200, TotalNum: len(req.MaterialList)}
+	resp.Batches, resp.Materials = aggregate(batches, results)
+
+	succeeded := 0
+	for _, m := range resp.Materials {
+		if m.Success {
+			succeeded++
+		}
+	}
+	resp.Result = succeeded == len(resp.Materials)
+	resp.Message = fmt.Sprintf("共 %d 个素材，分 %d 批提交，成功 %d 个", len(req.MaterialList), len(batches), succeeded)
+	return resp
+}
+
+// specCheck 投放媒体规格检查结果
+type specCheck struct {
+	passed     []types.MaterialItem
+	rejected   []specRejection
+	violations []types.SpecViolation
+}
+
+// specRejection 不符合 error 级别规格而未提交的素材
+type specRejection struct {
+	item    types.MaterialItem
+	message string
+}
+
+func (c *specCheck) message() string {
+	var parts []string
+	for _, r := range c.rejected {
+		parts = append(parts, r.item.MaterialName+" "+r.message)
+	}
+	return strings.Join(parts, "；")
+}
+
+// outcomes 未提交的素材作为批次 0 和逐个素材的失败结果
+func (c *specCheck) outcomes() ([]types.SubmitBatchResult, []types.MaterialOutcome) {
+	if len(c.rejected) == 0 {
+		return nil, nil
+	}
+	batch := types.SubmitBatchResult{Batch: 0, Success: false, Message: "不符合投放媒体规格，未提交"}
+	var outcomes []types.MaterialOutcome
+	for _, r := range c.rejected {
+		batch.Materials = append(batch.Materials, r.item.MaterialName)
+		batch.Rejected = append(batch.Rejected, r.item.MaterialName)
+		outcomes = append(outcomes, types.MaterialOutcome{
+			MaterialName: r.item.MaterialName,
+			URL:          r.item.URL,
+			Success:      false,
+			Message:      r.message,
+		})
+	}
+	return []types.SubmitBatchResult{batch}, outcomes
+}
+
+// attach 把规格检查结果并入提交响应，有素材未提交时 Result 为 false
+func (c *specCheck) attach(resp *types.SubmitMaterialResponse) {
+	resp.Specs = c.violations
+	if len(c.rejected) == 0 {
+		return
+	}
+	batches, outcomes := c.outcomes()
+	resp.Batches = append(resp.Batches, batches...)
+	resp.Materials = append(resp.Materials, outcomes...)
+	resp.Result = false
+	resp.Message += fmt.Sprintf("；另有 %d 个素材不符合投放媒体规格未提交", len(c.rejected))
+}
+
+// submitAll 将素材整体交给 SubmitMaterialBatch 分批提交，返回每批的结果；提交前的校验失败作为一个失败批次返回，
+// 未满足提交策略时不返回批次，只返回暂缓情况
+func submitAll(ctx context.Context, svcCtx *svc.ServiceContext, items []types.MaterialItem, base types.SubmitMaterialBatchRequest) ([]types.SubmitBatchResult, *types.HeldReport) {
+	req := base
+	req.MaterialList = items
+
+	fail := func(message string) []types.SubmitBatchResult {
+		result := types.SubmitBatchResult{Batch: 1, Message: message}
+		for _, item := range items {
+			result.Materials = append(result.Materials, item.MaterialName)
+		}
+		return []types.SubmitBatchResult{result}
+	}
+
+	resp, err := NewSubmitMaterialBatchLogic(ctx, svcCtx).SubmitMaterialBatch(&req)
+	if err != nil {
+		return fail(err.Error()), nil
+	}
+	if resp.Held != nil {
+		return nil, resp.Held
+	}
+	if len(resp.Batches) == 0 {
+		return fail(resp.Message), nil
+	}
+	return resp.Batches, nil
+}
+
+// materialItems 由上传成功的结果构建待提交的素材；经过重命名等处理时使用实际上传的文件名
+func materialItems(results []types.UploadResult) []types.MaterialItem {
+	var items []types.MaterialItem
+	for _, r := range results {
+		if !r.Success {
+			continue
+		}
+		name := r.FileName
+		if r.UploadName != "" {
+			name = r.UploadName
+		}
+		items = append(items, types.MaterialItem{
+			MaterialName: name,
+			MaterialSize: r.FileSize,
+			MaterialType: r.MaterialType,
+			URL:          r.URL,
+			LocalURL:     r.LocalURL,
+			Width:        r.Width,
+			Height:       r.Height,
+			Duration:     r.Duration,
+			Codec:        r.Codec,
+			ReleaseCopy:  r.ReleaseCopy,
+			Folder:       source.Entry{Path: r.FileName}.Dir(),
+		})
+	}
+	return items
+}
+
+// assignFolderCategories 为未单独指定品类的素材按所在子目录匹配素材品类：从最内层目录向外，
+// 目录名与品类的显示名称或取值相同即采用；返回未匹配到品类的子目录
+func assignFolderCategories(c *catalog.Catalog, items []types.MaterialItem) []string {
+	var unmatched []string
+	seen := make(map[string]bool)
+	for i := range items {
+		item := &items[i]
+		if item.Folder == "" || len(item.CategoryList) > 0 {
+			continue
+		}
+		if value, ok := folderCategory(c, item.Folder); ok {
+			item.CategoryList = []string{value}
+		} else if !seen[item.Folder] {
+			seen[item.Folder] = true
+			unmatched = append(unmatched, item.Folder)
+		}
+	}
+	return unmatched
+}
+
+// folderCategory 按列定义中的品类枚举匹配，目录同步后以素材中心为准
+func folderCategory(c *catalog.Catalog, folder string) (string, bool) {
+	col, _ := c.Column(catalog.ColumnKeyCategory)
+	parts := strings.Split(folder, "/")
+	for i := len(parts) - 1; i >= 0; i-- {
+		for _, o := range col.ColumnEnum {
+			if parts[i] == o.Label || parts[i] == o.Value {
+				return o.Value, true
+			}
+		}
+	}
+	return "", false
+}
+
+// countSubmitted 统计提交成功的批次数
+func countSubmitted(batches []types.SubmitBatchResult) int {
+	count := 0
+	for _, b := range batches {
+		if b.Success {
+			count++
+		}
+	}
+	return count
+}
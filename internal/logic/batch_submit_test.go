@@ -0,0 +1,332 @@
+package logic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"jd_material_push/internal/catalog"
+	"jd_material_push/internal/cookie"
+	"jd_material_push/internal/materialcenter"
+	"jd_material_push/internal/svc"
+	"jd_material_push/internal/types"
+)
+
+func testItems(names ...string) []types.MaterialItem {
+	items := make([]types.MaterialItem, 0, len(names))
+	for _, name := range names {
+		items = append(items, types.MaterialItem{MaterialName: name, MaterialSize: 100, MaterialType: 1, URL: "https://img.example.com/" + name})
+	}
+	return items
+}
+
+func itemNames(items []types.MaterialItem) []string {
+	names := make([]string, 0, len(items))
+	for _, item := range items {
+		names = append(names, item.MaterialName)
+	}
+	return names
+}
+
+func TestPlanBatches(t *testing.T) {
+	columns := catalog.Default().Schema()
+	many := make([]string, 45)
+	for i := range many {
+		many[i] = fmt.Sprintf("%02d.jpg", i+1)
+	}
+
+	tests := []struct {
+		name  string
+		req   types.SubmitMaterialBatchRequest
+		setup func(items []types.MaterialItem)
+		want  [][]string // 每批的素材
+	}{
+		{
+			name: "每批 20 个",
+			req:  types.SubmitMaterialBatchRequest{MaterialList: testItems(many...)},
+			want: [][]string{many[:20], many[20:40], many[40:]},
+		},
+		{
+			name: "单独指定品类的素材分到另一批，保持先后顺序",
+			req:  types.SubmitMaterialBatchRequest{MaterialList: testItems("a.jpg", "b.jpg", "c.jpg", "d.jpg")},
+			setup: func(items []types.MaterialItem) {
+				items[1].CategoryList = []string{"652"}
+				items[3].CategoryList = []string{"652"}
+			},
+			want: [][]string{{"a.jpg", "c.jpg"}, {"b.jpg", "d.jpg"}},
+		},
+		{
+			name: "单独指定的文案与请求相同时不拆分",
+			req:  types.SubmitMaterialBatchRequest{MaterialList: testItems("a.jpg", "b.jpg")},
+			setup: func(items []types.MaterialItem) {
+				items[1].ReleaseCopy = "新品上市"
+			},
+			want: [][]string{{"a.jpg", "b.jpg"}},
+		},
+		{
+			name: "groupByType 按素材类型分批",
+			req:  types.SubmitMaterialBatchRequest{MaterialList: testItems("a.jpg", "b.mp4", "c.jpg"), GroupByType: true},
+			setup: func(items []types.MaterialItem) {
+				items[1].MaterialType = 2
+			},
+			want: [][]string{{"a.jpg", "c.jpg"}, {"b.mp4"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := tt.req
+			req.MediaList, req.CategoryList, req.ReleaseCopy = []string{"jlyq"}, []string{"4938"}, "新品上市"
+			if tt.setup != nil {
+				tt.setup(req.MaterialList)
+			}
+			batches, err := planBatches(columns, &req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got [][]string
+			for i, b := range batches {
+				if b.index != i+1 {
+					t.Errorf("第 %d 批的序号为 %d", i+1, b.index)
+				}
+				got = append(got, itemNames(b.items))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("批次 = %v，应为 %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlanBatchesInvalidItem(t *testing.T) {
+	req := types.SubmitMaterialBatchRequest{
+		MaterialList: testItems("a.jpg", "b.jpg"),
+		MediaList:    []string{"jlyq"},
+		CategoryList: []string{"4938"},
+		ReleaseCopy:  "新品上市",
+	}
+	req.MaterialList[1].MediaList = []string{"nosuchmedia"}
+	_, err := planBatches(catalog.Default().Schema(), &req)
+	if err == nil || !strings.Contains(err.Error(), "b.jpg") {
+		t.Errorf("错误 = %v，应指出素材 b.jpg", err)
+	}
+}
+
+// fakeCenter 模拟素材中心：提交的素材中含有 rejected 中的素材时整批拒绝并指出第一个被拒绝的素材，
+// message 不为空时以该原因拒绝而不指出素材；rejectAll 时任何提交都被拒绝，status 不为 0 时直接返回该状态码
+type fakeCenter struct {
+	mu        sync.Mutex
+	rejected  map[string]bool
+	message   string
+	rejectAll bool
+	status    int
+	calls     [][]string
+}
+
+func (f *fakeCenter) RoundTrip(req *http.Request) (*http.Response, error) {
+	reply := func(body string) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(body))}, nil
+	}
+	if req.Body == nil {
+		// Cookie 接口
+		return reply(`{"code":200,"data":"pin=test"}`)
+	}
+	data, _ := io.ReadAll(req.Body)
+	form, _ := url.ParseQuery(string(data))
+	var call struct {
+		Param struct {
+			MaterialList []types.MaterialItem `json:"materialList"`
+		} `json:"param"`
+	}
+	if err := json.Unmarshal([]byte(form.Get("body")), &call); err != nil {
+		return nil, err
+	}
+	names := itemNames(call.Param.MaterialList)
+
+	f.mu.Lock()
+	f.calls = append(f.calls, names)
+	f.mu.Unlock()
+
+	if f.status != 0 {
+		return reply(fmt.Sprintf(`{"code":%d,"message":"服务繁忙","result":false}`, f.status))
+	}
+	reject := func(name string) (*http.Response, error) {
+		message := f.message
+		if message == "" {
+			message = fmt.Sprintf("素材 %s 不合规", name)
+		}
+		return reply(fmt.Sprintf(`{"code":400,"message":%q,"result":false}`, message))
+	}
+	if f.rejectAll {
+		return reject(names[0])
+	}
+	for _, name := range names {
+		if f.rejected[name] {
+			return reject(name)
+		}
+	}
+	return reply(`{"code":200,"message":"成功","result":true,"uuid":"batch-uuid"}`)
+}
+
+func newFakeCenter(t *testing.T, center *fakeCenter) *svc.ServiceContext {
+	t.Helper()
+	transport := http.DefaultTransport
+	http.DefaultTransport = center
+	cookieMgr := cookie.NewManager()
+	t.Cleanup(func() {
+		cookieMgr.Stop()
+		http.DefaultTransport = transport
+	})
+	return &svc.ServiceContext{MaterialCenter: materialcenter.NewClient(cookieMgr)}
+}
+
+func TestBisect(t *testing.T) {
+	type result struct {
+		Items     []string
+		Succeeded bool
+	}
+	tests := []struct {
+		name     string
+		rejected []string
+		message  string
+		isolate  bool
+		status   int
+		want     []result
+		calls    int
+	}{
+		{
+			name:     "不拆分",
+			rejected: []string{"c"},
+			want:     []result{{[]string{"a", "b", "c", "d"}, false}},
+			calls:    1,
+		},
+		{
+			name:     "定位一个被拒绝的素材",
+			rejected: []string{"c"},
+			isolate:  true,
+			want:     []result{{[]string{"a", "b"}, true}, {[]string{"c"}, false}, {[]string{"d"}, true}},
+			calls:    5,
+		},
+		{
+			name:     "定位分在两半的两个素材",
+			rejected: []string{"b", "d"},
+			isolate:  true,
+			want:     []result{{[]string{"a"}, true}, {[]string{"b"}, false}, {[]string{"c"}, true}, {[]string{"d"}, false}},
+			calls:    7,
+		},
+		{
+			name:     "拒绝原因不指明素材时定位分在两半的两个素材",
+			rejected: []string{"b", "d"},
+			message:  "素材不合规",
+			isolate:  true,
+			want:     []result{{[]string{"a"}, true}, {[]string{"b"}, false}, {[]string{"c"}, true}, {[]string{"d"}, false}},
+			calls:    7,
+		},
+		{
+			name:     "投放设置有误时不拆分",
+			rejected: []string{"b"},
+			message:  "投放媒体不存在",
+			isolate:  true,
+			want:     []result{{[]string{"a", "b", "c", "d"}, false}},
+			calls:    1,
+		},
+		{
+			name:     "定位相邻的两个素材",
+			rejected: []string{"a", "b"},
+			isolate:  true,
+			want:     []result{{[]string{"a"}, false}, {[]string{"b"}, false}, {[]string{"c", "d"}, true}},
+			calls:    5,
+		},
+		{
+			name:    "临时失败不拆分",
+			isolate: true,
+			status:  503,
+			want:    []result{{[]string{"a", "b", "c", "d"}, false}},
+			calls:   1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			center := &fakeCenter{rejected: make(map[string]bool), message: tt.message, status: tt.status}
+			for _, name := range tt.rejected {
+				center.rejected[name] = true
+			}
+			l := NewSubmitMaterialBatchLogic(context.Background(), newFakeCenter(t, center))
+
+			req := &types.SubmitMaterialBatchRequest{IsolateFailures: tt.isolate}
+			items := testItems("a", "b", "c", "d")
+			attempts := l.runBatch(req, submitBatch{index: 1, applyAttr: "{}", items: items})
+
+			var got []result
+			for _, a := range attempts {
+				got = append(got, result{itemNames(a.items), a.succeeded()})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("提交结果 = %+v，应为 %+v", got, tt.want)
+			}
+			if len(center.calls) != tt.calls {
+				t.Errorf("调用素材中心 %d 次 %v，应为 %d 次", len(center.calls), center.calls, tt.calls)
+			}
+		})
+	}
+}
+
+func TestBisectManyItems(t *testing.T) {
+	names := make([]string, 20)
+	for i := range names {
+		names[i] = fmt.Sprintf("%02d.jpg", i+1)
+	}
+
+	t.Run("拒绝原因不指明素材时逐个定位", func(t *testing.T) {
+		center := &fakeCenter{rejected: map[string]bool{"03.jpg": true, "15.jpg": true}, message: "素材不合规"}
+		l := NewSubmitMaterialBatchLogic(context.Background(), newFakeCenter(t, center))
+		attempts := l.runBatch(&types.SubmitMaterialBatchRequest{IsolateFailures: true}, submitBatch{index: 1, applyAttr: "{}", items: testItems(names...)})
+
+		var rejected, submitted []string
+		for _, a := range attempts {
+			if a.succeeded() {
+				submitted = append(submitted, itemNames(a.items)...)
+				continue
+			}
+			if len(a.items) != 1 {
+				t.Errorf("被拒绝的素材 %v 未逐个定位", itemNames(a.items))
+			}
+			rejected = append(rejected, itemNames(a.items)...)
+		}
+		if want := []string{"03.jpg", "15.jpg"}; !reflect.DeepEqual(rejected, want) {
+			t.Errorf("被拒绝的素材 = %v，应为 %v", rejected, want)
+		}
+		if len(submitted) != 18 {
+			t.Errorf("提交成功 %d 个素材，应为 18 个", len(submitted))
+		}
+		if len(center.calls) > isolateMaxCalls {
+			t.Errorf("调用素材中心 %d 次，超过上限 %d 次", len(center.calls), isolateMaxCalls)
+		}
+	})
+
+	t.Run("所有素材都被拒绝时在调用上限内停止", func(t *testing.T) {
+		center := &fakeCenter{rejectAll: true, message: "素材不合规"}
+		l := NewSubmitMaterialBatchLogic(context.Background(), newFakeCenter(t, center))
+		attempts := l.runBatch(&types.SubmitMaterialBatchRequest{IsolateFailures: true}, submitBatch{index: 1, applyAttr: "{}", items: testItems(names...)})
+
+		if len(center.calls) > isolateMaxCalls {
+			t.Errorf("调用素材中心 %d 次，超过上限 %d 次", len(center.calls), isolateMaxCalls)
+		}
+		var got []string
+		for _, a := range attempts {
+			if a.succeeded() {
+				t.Errorf("素材 %v 应被拒绝", itemNames(a.items))
+			}
+			got = append(got, itemNames(a.items)...)
+		}
+		if !reflect.DeepEqual(got, names) {
+			t.Errorf("提交结果 = %v，应按顺序包含全部素材", got)
+		}
+	})
+}
@@ -0,0 +1,106 @@
+package logic
+
+import (
+	"fmt"
+	"strings"
+
+	"jd_material_push/internal/lexicon"
+	"jd_material_push/internal/svc"
+	"jd_material_push/internal/transform"
+	"jd_material_push/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// 违禁词的来源
+const (
+	complianceFieldCopy = "releaseCopy"
+	complianceFieldName = "materialName"
+)
+
+// complianceMode 处理方案的违禁词检查方式
+func complianceMode(svcCtx *svc.ServiceContext, profileName string) (string, error) {
+	profile, err := transform.Lookup(svcCtx.Config.Profiles, profileName)
+	if err != nil {
+		return "", err
+	}
+	switch profile.Compliance {
+	case lexicon.ModeDefault, lexicon.ModeBlock, lexicon.ModeWarn, lexicon.ModeOff:
+		return profile.Compliance, nil
+	}
+	return "", fmt.Errorf("处理方案 %s 的违禁词检查方式有误: %s（可选 block、warn、off）", profile.Name, profile.Compliance)
+}
+
+// complianceChecker 按处理方案检查文案和素材名称中的违禁词，同一段文案只检查一次
+type complianceChecker struct {
+	lex     *lexicon.Lexicon
+	mode    string
+	checked map[string]bool
+	hits    []types.ComplianceHit
+}
+
+func newComplianceChecker(svcCtx *svc.ServiceContext, profileName string) (*complianceChecker, error) {
+	mode, err := complianceMode(svcCtx, profileName)
+	if err != nil {
+		return nil, err
+	}
+	lex := svcCtx.Lexicon.Current()
+	if lex.Missing() && mode != lexicon.ModeOff {
+		logx.Errorf("违禁词词库 %s 不存在，未检查违禁词", svcCtx.Config.LexiconPath)
+	}
+	return &complianceChecker{lex: lex, mode: mode, checked: make(map[string]bool)}, nil
+}
+
+func (c *complianceChecker) check(field, target, text string) {
+	if c.mode == lexicon.ModeOff || text == "" {
+		return
+	}
+	key := field + "\x00" + target + "\x00" + text
+	if field == complianceFieldCopy {
+		key = field + "\x00" + text
+	}
+	if c.checked[key] {
+		return
+	}
+	c.checked[key] = true
+
+	hits := c.lex.Check(text)
+	for _, h := range hits {
+		c.hits = append(c.hits, types.ComplianceHit{
+			Field:     field,
+			Target:    target,
+			Term:      h.Term,
+			Category:  h.Category,
+			Severity:  lexicon.Severity(h, c.mode),
+			Suggest:   h.Suggest,
+			Highlight: lexicon.Highlight(text, hits),
+		})
+	}
+}
+
+// blocked 是否有阻止提交的违禁词
+func (c *complianceChecker) blocked() bool {
+	for _, h := range c.hits {
+		if h.Severity == lexicon.SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// message 汇总 error 级别的违禁词
+func (c *complianceChecker) message() string {
+	var parts []string
+	for _, h := range c.hits {
+		if h.Severity != lexicon.SeverityError {
+			continue
+		}
+		where := "投放文案"
+		if h.Field == complianceFieldName {
+			where = "素材名称"
+		}
+		hit := lexicon.Hit{Term: h.Term, Category: h.Category, Suggest: h.Suggest}
+		parts = append(parts, fmt.Sprintf("%s %s 含%s", where, h.Highlight, lexicon.Describe(hit)))
+	}
+	return "含有违禁词: " + strings.Join(parts, "；")
+}
@@ -0,0 +1,70 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+
+	"jd_material_push/internal/lexicon"
+	"jd_material_push/internal/svc"
+	"jd_material_push/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ComplianceCheckLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewComplianceCheckLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ComplianceCheckLogic {
+	return &ComplianceCheckLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// ComplianceCheck 按提交时相同的规则检查投放文案和素材名称中的违禁词，不提交
+func (l *ComplianceCheckLogic) ComplianceCheck(req *types.ComplianceCheckRequest) (resp *types.ComplianceCheckResponse, err error) {
+	resp = &types.ComplianceCheckResponse{
+		Code:    200,
+		Message: "未发现违禁词",
+		Data:    []types.ComplianceHit{},
+	}
+
+	checker, err := newComplianceChecker(l.svcCtx, req.Profile)
+	if err != nil {
+		resp.Code = 400
+		resp.Message = err.Error()
+		return resp, nil
+	}
+	if checker.mode != lexicon.ModeOff && checker.lex.Missing() {
+		resp.LexiconMissing = true
+		resp.Message = fmt.Sprintf("违禁词词库 %s 不存在，未检查违禁词", l.svcCtx.Config.LexiconPath)
+	}
+	for _, text := range req.Texts {
+		checker.check(complianceFieldCopy, "", text)
+	}
+	for _, name := range req.MaterialNames {
+		checker.check(complianceFieldName, name, name)
+	}
+
+	resp.Passed = !checker.blocked()
+	if len(checker.hits) == 0 {
+		return resp, nil
+	}
+	resp.Data = checker.hits
+	if !resp.Passed {
+		resp.Message = checker.message()
+		return resp, nil
+	}
+	warnings := 0
+	for _, h := range checker.hits {
+		if h.Severity == lexicon.SeverityWarning {
+			warnings++
+		}
+	}
+	resp.Message = fmt.Sprintf("发现 %d 处需注意的用语，不影响提交", warnings)
+	return resp, nil
+}
@@ -0,0 +1,43 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+
+	"jd_material_push/internal/svc"
+	"jd_material_push/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type DeleteCopyLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewDeleteCopyLogic(ctx context.Context, svcCtx *svc.ServiceContext) *DeleteCopyLogic {
+	return &DeleteCopyLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// DeleteCopy 从文案库删除文案；已保存到台账任务中的文案内容不受影响
+func (l *DeleteCopyLogic) DeleteCopy(req *types.DeleteCopyRequest) (resp *types.CopyResponse, err error) {
+	resp = &types.CopyResponse{
+		Code:    200,
+		Message: "success",
+	}
+
+	ok, err := l.svcCtx.CopyLibrary.Delete(req.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		resp.Code = 404
+		resp.Message = fmt.Sprintf("文案不存在: %s", req.ID)
+	}
+	return resp, nil
+}
@@ -0,0 +1,43 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+
+	"jd_material_push/internal/svc"
+	"jd_material_push/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type DeletePresetLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewDeletePresetLogic(ctx context.Context, svcCtx *svc.ServiceContext) *DeletePresetLogic {
+	return &DeletePresetLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// DeletePreset 删除预设
+func (l *DeletePresetLogic) DeletePreset(req *types.DeletePresetRequest) (resp *types.PresetResponse, err error) {
+	resp = &types.PresetResponse{
+		Code:    200,
+		Message: "success",
+	}
+
+	ok, err := l.svcCtx.Presets.Delete(req.Name)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		resp.Code = 404
+		resp.Message = fmt.Sprintf("预设不存在: %s", req.Name)
+	}
+	return resp, nil
+}
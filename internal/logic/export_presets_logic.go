@@ -0,0 +1,48 @@
+package logic
+
+import (
+	"context"
+	"strings"
+
+	"jd_material_push/internal/svc"
+	"jd_material_push/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ExportPresetsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewExportPresetsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ExportPresetsLogic {
+	return &ExportPresetsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// ExportPresets 导出预设为 YAML，可直接保存为共享的预设文件或导入到其他电脑
+func (l *ExportPresetsLogic) ExportPresets(req *types.ExportPresetsRequest) (resp *types.ExportPresetsResponse, err error) {
+	resp = &types.ExportPresetsResponse{
+		Code:    200,
+		Message: "success",
+	}
+
+	var names []string
+	for _, name := range strings.Split(req.Names, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	data, err := l.svcCtx.Presets.Export(names)
+	if err != nil {
+		resp.Code = 400
+		resp.Message = err.Error()
+		return resp, nil
+	}
+	resp.Content = string(data)
+	return resp, nil
+}
@@ -0,0 +1,64 @@
+package logic
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"jd_material_push/internal/report"
+	"jd_material_push/internal/svc"
+	"jd_material_push/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ExportReportLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewExportReportLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ExportReportLogic {
+	return &ExportReportLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// ExportReport 生成任务的交付报告：CSV、JSON 为每个素材的文件名、大小、类型、URL、批次、提交信息和审核状态，
+// HTML 为带缩略图和可复制 URL 的单个离线页面，可直接发给客户
+func (l *ExportReportLogic) ExportReport(req *types.ExportReportRequest) (resp *types.ExportReportResponse, err error) {
+	resp = &types.ExportReportResponse{
+		Code:    200,
+		Message: "success",
+	}
+
+	job, ok := l.svcCtx.Ledger.Get(req.JobID)
+	if !ok {
+		resp.Code = 404
+		resp.Message = fmt.Sprintf("任务不存在: %s", req.JobID)
+		return resp, nil
+	}
+
+	c := l.svcCtx.Catalog.Current()
+	mediaList := make([]string, 0, len(job.MediaList))
+	for _, v := range job.MediaList {
+		mediaList = append(mediaList, c.MediaLabel(v))
+	}
+	categoryList := make([]string, 0, len(job.CategoryList))
+	for _, v := range job.CategoryList {
+		categoryList = append(categoryList, c.CategoryLabel(v))
+	}
+
+	var buf bytes.Buffer
+	if err := report.Write(&buf, report.New(job, mediaList, categoryList, l.svcCtx.Config.ThumbnailDir), req.Format); err != nil {
+		resp.Code = 400
+		resp.Message = err.Error()
+		return resp, nil
+	}
+	resp.FileName = fmt.Sprintf("report-%s.%s", job.ID, req.Format)
+	resp.ContentType = report.ContentType(req.Format)
+	resp.Content = buf.Bytes()
+	return resp, nil
+}
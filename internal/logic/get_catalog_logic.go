@@ -0,0 +1,53 @@
+package logic
+
+import (
+	"context"
+	"time"
+
+	"jd_material_push/internal/catalog"
+	"jd_material_push/internal/svc"
+	"jd_material_push/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetCatalogLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetCatalogLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetCatalogLogic {
+	return &GetCatalogLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetCatalog 返回投放媒体与素材品类目录
+func (l *GetCatalogLogic) GetCatalog() (resp *types.CatalogResponse, err error) {
+	c := l.svcCtx.Catalog.Current()
+	data := types.Catalog{
+		Media:      toCatalogOptions(c.Media),
+		Categories: toCatalogOptions(c.Categories),
+		Version:    c.Version,
+	}
+	if !c.SyncedAt.IsZero() {
+		data.SyncedAt = c.SyncedAt.Format(time.RFC3339)
+	}
+
+	return &types.CatalogResponse{
+		Code:    200,
+		Message: "success",
+		Data:    data,
+	}, nil
+}
+
+func toCatalogOptions(options []catalog.Option) []types.CatalogOption {
+	result := make([]types.CatalogOption, 0, len(options))
+	for _, o := range options {
+		result = append(result, types.CatalogOption{Label: o.Label, Value: o.Value})
+	}
+	return result
+}
@@ -0,0 +1,48 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+
+	"jd_material_push/internal/svc"
+	"jd_material_push/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetHistoryDetailLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetHistoryDetailLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetHistoryDetailLogic {
+	return &GetHistoryDetailLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetHistoryDetail 返回任务的提交设置和每个素材的上传、提交及审核结果
+func (l *GetHistoryDetailLogic) GetHistoryDetail(req *types.HistoryDetailRequest) (resp *types.HistoryDetailResponse, err error) {
+	resp = &types.HistoryDetailResponse{
+		Code:      200,
+		Message:   "success",
+		Materials: []types.HistoryMaterial{},
+	}
+
+	job, ok := l.svcCtx.Ledger.Get(req.JobID)
+	if !ok {
+		resp.Code = 404
+		resp.Message = fmt.Sprintf("任务不存在: %s", req.JobID)
+		return resp, nil
+	}
+
+	summary := historyJob(job)
+	resp.Job = &summary
+	for _, rec := range job.Materials {
+		resp.Materials = append(resp.Materials, historyMaterial(rec))
+	}
+	return resp, nil
+}
@@ -0,0 +1,52 @@
+package logic
+
+import (
+	"context"
+
+	"jd_material_push/internal/svc"
+	"jd_material_push/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetHistoryLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetHistoryLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetHistoryLogic {
+	return &GetHistoryLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetHistory 按条件查询推送历史，最新的任务在前，分页返回任务汇总
+func (l *GetHistoryLogic) GetHistory(req *types.HistoryRequest) (resp *types.HistoryResponse, err error) {
+	resp = &types.HistoryResponse{
+		Code:    200,
+		Message: "success",
+		Data:    []types.HistoryJob{},
+	}
+
+	filter, err := historyFilter(req)
+	if err != nil {
+		resp.Code = 400
+		resp.Message = err.Error()
+		return resp, nil
+	}
+
+	jobs := l.svcCtx.Ledger.Query(filter)
+	resp.Total = len(jobs)
+	start := (req.Page - 1) * req.PageSize
+	if start < 0 || start >= len(jobs) {
+		return resp, nil
+	}
+	end := min(start+req.PageSize, len(jobs))
+	for _, job := range jobs[start:end] {
+		resp.Data = append(resp.Data, historyJob(job))
+	}
+	return resp, nil
+}
@@ -0,0 +1,136 @@
+package logic
+
+import (
+	"fmt"
+	"time"
+
+	"jd_material_push/internal/ledger"
+	"jd_material_push/internal/types"
+)
+
+// historyDateLayout 推送历史按日期筛选时的日期格式
+const historyDateLayout = "2006-01-02"
+
+// historyFilter 将查询条件转为台账的筛选条件，结束日期当天的任务也包含在内
+func historyFilter(req *types.HistoryRequest) (ledger.Filter, error) {
+	f := ledger.Filter{
+		Account:  req.Account,
+		Media:    req.Media,
+		Category: req.Category,
+		Status:   req.Status,
+		FileName: req.FileName,
+	}
+	switch f.Status {
+	case "", ledger.JobStatusEmpty, ledger.JobStatusUploaded, ledger.JobStatusHeld, ledger.JobStatusSuccess,
+		ledger.JobStatusPartial, ledger.JobStatusFailed, ledger.JobStatusWithdrawn:
+	default:
+		return f, fmt.Errorf("任务状态有误: %s（可选 uploaded、held、success、partial、failed、withdrawn、empty）", f.Status)
+	}
+	if req.From != "" {
+		from, err := time.ParseInLocation(historyDateLayout, req.From, time.Local)
+		if err != nil {
+			return f, fmt.Errorf("开始日期有误: %s（格式如 2024-06-01）", req.From)
+		}
+		f.From = from
+	}
+	if req.To != "" {
+		to, err := time.ParseInLocation(historyDateLayout, req.To, time.Local)
+		if err != nil {
+			return f, fmt.Errorf("结束日期有误: %s（格式如 2024-06-30）", req.To)
+		}
+		f.To = to.AddDate(0, 0, 1)
+	}
+	if !f.From.IsZero() && !f.To.IsZero() && !f.From.Before(f.To) {
+		return f, fmt.Errorf("开始日期不能晚于结束日期")
+	}
+	return f, nil
+}
+
+// historyJob 任务在推送历史中的汇总
+func historyJob(job ledger.Job) types.HistoryJob {
+	out := types.HistoryJob{
+		ID:           job.ID,
+		FolderPath:   job.FolderPath,
+		Account:      job.Account,
+		MediaList:    append([]string{}, job.MediaList...),
+		CategoryList: append([]string{}, job.CategoryList...),
+		ReleaseCopy:  job.ReleaseCopy,
+		CopyVariants: jobVariants(job),
+		CopyVars:     job.CopyVars,
+		Campaign:     job.Campaign,
+		NameTemplate: job.NameTemplate,
+		Profile:      job.Profile,
+		SubmitPolicy: job.SubmitPolicy,
+		Status:       job.Status(),
+		Total:        len(job.Materials),
+		Batches:      []string{},
+		CreatedAt:    job.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:    job.UpdatedAt.Format(time.RFC3339),
+	}
+	if len(out.CopyVariants) == 0 {
+		out.CopyVariants = nil
+	}
+
+	seen := make(map[string]bool)
+	for _, rec := range job.Materials {
+		if rec.UploadStatus == ledger.UploadStatusUploaded {
+			out.Uploaded++
+		}
+		switch rec.SubmitStatus {
+		case ledger.SubmitStatusSubmitted, ledger.SubmitStatusWithdrawFailed:
+			out.Submitted++
+		case ledger.SubmitStatusWithdrawn:
+			out.Submitted++
+			out.Withdrawn++
+		case ledger.SubmitStatusHeld:
+			out.Held++
+		}
+		if rec.UploadStatus == ledger.UploadStatusFailed || rec.SubmitStatus == ledger.SubmitStatusFailed {
+			out.Failed++
+		}
+		switch rec.ApprovalStatus {
+		case ledger.ApprovalStatusApproved:
+			out.Approved++
+		case ledger.ApprovalStatusRejected:
+			out.Rejected++
+		}
+		if rec.BatchUUID != "" && !seen[rec.BatchUUID] {
+			seen[rec.BatchUUID] = true
+			out.Batches = append(out.Batches, rec.BatchUUID)
+		}
+	}
+	return out
+}
+
+// historyMaterial 素材记录在推送历史中的表示
+func historyMaterial(rec ledger.MaterialRecord) types.HistoryMaterial {
+	out := types.HistoryMaterial{
+		FileName:       rec.FileName,
+		FilePath:       rec.FilePath,
+		FileSize:       rec.FileSize,
+		MaterialType:   rec.MaterialType,
+		Width:          rec.Width,
+		Height:         rec.Height,
+		Duration:       rec.Duration,
+		MaterialName:   rec.MaterialName,
+		URL:            rec.URL,
+		LocalURL:       rec.LocalURL,
+		UploadStatus:   rec.UploadStatus,
+		SubmitStatus:   rec.SubmitStatus,
+		BatchUUID:      rec.BatchUUID,
+		Message:        rec.Message,
+		ApprovalStatus: rec.ApprovalStatus,
+		ApprovalNote:   rec.ApprovalNote,
+		UpdatedAt:      rec.UpdatedAt.Format(time.RFC3339),
+	}
+	if out.MaterialName == "" {
+		out.MaterialName = rec.FileName
+	}
+	if !rec.SubmittedAt.IsZero() {
+		out.SubmittedAt = rec.SubmittedAt.Format(time.RFC3339)
+	}
+	for _, step := range rec.Steps {
+		out.Steps = append(out.Steps, types.TransformStep{Step: step.Step, Success: step.Success, Message: step.Message, Output: step.Output})
+	}
+	return out
+}
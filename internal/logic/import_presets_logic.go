@@ -0,0 +1,60 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+
+	"jd_material_push/internal/preset"
+	"jd_material_push/internal/svc"
+	"jd_material_push/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ImportPresetsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewImportPresetsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ImportPresetsLogic {
+	return &ImportPresetsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// ImportPresets 导入 YAML 格式的预设，同名预设按 overwrite 覆盖或跳过
+func (l *ImportPresetsLogic) ImportPresets(req *types.ImportPresetsRequest) (resp *types.ImportPresetsResponse, err error) {
+	resp = &types.ImportPresetsResponse{
+		Code:     200,
+		Added:    []string{},
+		Replaced: []string{},
+		Skipped:  []string{},
+	}
+
+	incoming, err := preset.Parse([]byte(req.Content))
+	if err == nil {
+		for _, p := range incoming {
+			if err = checkPreset(l.svcCtx, p); err != nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		resp.Code = 400
+		resp.Message = err.Error()
+		return resp, nil
+	}
+
+	result, err := l.svcCtx.Presets.Import([]byte(req.Content), req.Overwrite)
+	if err != nil {
+		return nil, err
+	}
+	resp.Added = append(resp.Added, result.Added...)
+	resp.Replaced = append(resp.Replaced, result.Replaced...)
+	resp.Skipped = append(resp.Skipped, result.Skipped...)
+	resp.Message = fmt.Sprintf("新增 %d 个，覆盖 %d 个，跳过 %d 个", len(resp.Added), len(resp.Replaced), len(resp.Skipped))
+	return resp, nil
+}
@@ -0,0 +1,213 @@
+package logic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+
+	"jd_material_push/internal/applyattr"
+	"jd_material_push/internal/ledger"
+	"jd_material_push/internal/staging"
+	"jd_material_push/internal/svc"
+	"jd_material_push/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// manifestLimit 任务清单的最大字节数
+const manifestLimit = 1 << 20
+
+type JobUploadLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewJobUploadLogic(ctx context.Context, svcCtx *svc.ServiceContext) *JobUploadLogic {
+	return &JobUploadLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// JobUpload 将浏览器或脚本上传的文件暂存到独立的工作目录，按与 /api/upload 相同的流程上传到京橙；
+// 清单中指定了投放媒体时，上传成功的素材继续按批提交到素材中心。工作目录在请求结束时删除
+func (l *JobUploadLogic) JobUpload(mr *multipart.Reader) (resp *types.JobUploadResponse, err error) {
+	resp = &types.JobUploadResponse{
+		Code:    200,
+		Message: "success",
+		Data:    []types.UploadResult{},
+	}
+
+	ws, err := l.svcCtx.Staging.Create()
+	if err != nil {
+		l.Errorf("创建暂存目录失败: %v", err)
+		resp.Code = 500
+		resp.Message = err.Error()
+		return resp, nil
+	}
+	defer func() {
+		if err := ws.Remove(); err != nil {
+			l.Errorf("清理暂存目录失败 %s: %v", ws.Dir(), err)
+		}
+	}()
+
+	manifest, err := l.stage(mr, ws)
+	if err != nil {
+		resp.Code = 400
+		if errors.Is(err, staging.ErrQuotaExceeded) {
+			resp.Code = 413
+		}
+		resp.Message = err.Error()
+		return resp, nil
+	}
+	if ws.Len() == 0 {
+		resp.Code = 400
+		resp.Message = "没有收到文件，请通过 files 字段上传"
+		return resp, nil
+	}
+
+	// 用预设补全清单中未填写的设置
+	if err := applyPreset(l.svcCtx, manifest.Preset, presetFields{
+		mediaList:          &manifest.MediaList,
+		categoryList:       &manifest.CategoryList,
+		releaseCopy:        &manifest.ReleaseCopy,
+		copyVariants:       &manifest.CopyVariants,
+		copyVars:           &manifest.CopyVars,
+		columns:            &manifest.Columns,
+		nameTemplate:       &manifest.NameTemplate,
+		campaign:           &manifest.Campaign,
+		profile:            &manifest.Profile,
+		order:              &manifest.Order,
+		submitPolicy:       &manifest.SubmitPolicy,
+		minUploadedPercent: &manifest.MinUploadedPercent,
+		isolateFailures:    &manifest.IsolateFailures,
+	}); err != nil {
+		resp.Code = 400
+		resp.Message = err.Error()
+		return resp, nil
+	}
+
+	// 提交参数在上传前校验，避免上传完才发现投放设置有误
+	if err := checkPolicy(manifest.SubmitPolicy, manifest.MinUploadedPercent); err != nil {
+		resp.Code = 400
+		resp.Message = err.Error()
+		return resp, nil
+	}
+	if len(manifest.MediaList) > 0 {
+		values := applyattr.Values(manifest.MediaList, manifest.CategoryList, manifest.ReleaseCopy, manifest.Columns)
+		if _, err := applyattr.Build(l.svcCtx.Catalog.Current().Schema(), values); err != nil {
+			resp.Code = 400
+			resp.Message = err.Error()
+			return resp, nil
+		}
+	}
+
+	l.Infof("已暂存 %d 个文件到 %s", ws.Len(), ws.Dir())
+
+	uploadResp, err := NewUploadFilesLogic(l.ctx, l.svcCtx).UploadFiles(&types.UploadRequest{
+		FolderPath: ws.Dir(),
+		Profile:    manifest.Profile,
+		Order:      manifest.Order,
+		FileNames:  stagedOrder(ws, manifest.Order),
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp.JobID = uploadResp.JobID
+	resp.Data = uploadResp.Data
+	resp.Skipped = uploadResp.Skipped
+	if uploadResp.Code != 200 {
+		resp.Code = uploadResp.Code
+		resp.Message = uploadResp.Message
+		return resp, nil
+	}
+	l.relabelJob(resp.JobID, ws)
+
+	if len(manifest.MediaList) > 0 {
+		resp.Batches, resp.Held = submitAll(l.ctx, l.svcCtx, materialItems(resp.Data), types.SubmitMaterialBatchRequest{
+			MediaList:          manifest.MediaList,
+			CategoryList:       manifest.CategoryList,
+			ReleaseCopy:        manifest.ReleaseCopy,
+			JobID:              resp.JobID,
+			Columns:            manifest.Columns,
+			IsolateFailures:    manifest.IsolateFailures,
+			SubmitPolicy:       manifest.SubmitPolicy,
+			MinUploadedPercent: manifest.MinUploadedPercent,
+			NameTemplate:       manifest.NameTemplate,
+			Campaign:           manifest.Campaign,
+			CopyVariants:       manifest.CopyVariants,
+			CopyVars:           manifest.CopyVars,
+			Profile:            manifest.Profile,
+		})
+	}
+
+	resp.Message = fmt.Sprintf("上传 %d 个文件，成功 %d 个", len(resp.Data), countSuccessful(resp.Data))
+	if len(resp.Batches) > 0 {
+		resp.Message += fmt.Sprintf("；提交 %d 批，成功 %d 批", len(resp.Batches), countSubmitted(resp.Batches))
+	}
+	if resp.Held != nil {
+		resp.Message += "；" + heldMessage(resp.Held)
+	}
+	return resp, nil
+}
+
+// stage 读取 multipart：files 字段的文件写入工作目录，manifest 字段解析为任务清单，其他字段忽略
+func (l *JobUploadLogic) stage(mr *multipart.Reader, ws *staging.Workspace) (types.JobManifest, error) {
+	var manifest types.JobManifest
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			return manifest, nil
+		}
+		if err != nil {
+			return manifest, fmt.Errorf("读取上传内容失败: %w", err)
+		}
+
+		switch part.FormName() {
+		case "manifest":
+			if err := json.NewDecoder(io.LimitReader(part, manifestLimit)).Decode(&manifest); err != nil {
+				part.Close()
+				return manifest, fmt.Errorf("解析任务清单失败: %w", err)
+			}
+		case "files":
+			if part.FileName() == "" {
+				part.Close()
+				return manifest, errors.New("files 字段需要以文件形式上传")
+			}
+			if err := ws.Save(part.FileName(), part); err != nil {
+				part.Close()
+				return manifest, err
+			}
+		}
+		part.Close()
+	}
+}
+
+// stagedOrder 清单未指定上传顺序时按文件在请求中的先后顺序上传
+func stagedOrder(ws *staging.Workspace, order string) []string {
+	if order != "" {
+		return nil
+	}
+	return ws.Names()
+}
+
+// relabelJob 暂存文件在请求结束后即被删除，台账中改为以工作目录名记录来源，素材只保留文件名
+func (l *JobUploadLogic) relabelJob(jobID string, ws *staging.Workspace) {
+	if jobID == "" {
+		return
+	}
+	err := l.svcCtx.Ledger.Update(jobID, func(job *ledger.Job) {
+		job.FolderPath = "upload:" + ws.ID()
+		for i := range job.Materials {
+			job.Materials[i].FilePath = job.Materials[i].FileName
+		}
+	})
+	if err != nil {
+		l.Errorf("更新台账任务来源失败: %v", err)
+	}
+}
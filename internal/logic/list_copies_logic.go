@@ -0,0 +1,37 @@
+package logic
+
+import (
+	"context"
+
+	"jd_material_push/internal/svc"
+	"jd_material_push/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ListCopiesLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewListCopiesLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListCopiesLogic {
+	return &ListCopiesLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// ListCopies 返回文案库中的文案，最近使用或修改的在前；可按标签筛选
+func (l *ListCopiesLogic) ListCopies(req *types.CopyListRequest) (resp *types.CopyListResponse, err error) {
+	resp = &types.CopyListResponse{
+		Code:    200,
+		Message: "success",
+		Data:    []types.CopyEntry{},
+	}
+	for _, e := range l.svcCtx.CopyLibrary.List(req.Tag) {
+		resp.Data = append(resp.Data, copyEntry(e))
+	}
+	return resp, nil
+}
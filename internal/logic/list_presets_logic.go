@@ -0,0 +1,45 @@
+package logic
+
+import (
+	"context"
+
+	"jd_material_push/internal/svc"
+	"jd_material_push/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ListPresetsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewListPresetsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListPresetsLogic {
+	return &ListPresetsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// ListPresets 返回全部推送预设和当前登录的京东账号
+func (l *ListPresetsLogic) ListPresets() (resp *types.PresetListResponse, err error) {
+	resp = &types.PresetListResponse{
+		Code:    200,
+		Message: "success",
+		Account: l.svcCtx.CookieManager.Account(),
+		Data:    []types.PresetInfo{},
+	}
+
+	presets, err := l.svcCtx.Presets.List()
+	if err != nil {
+		resp.Code = 500
+		resp.Message = err.Error()
+		return resp, nil
+	}
+	for _, p := range presets {
+		resp.Data = append(resp.Data, presetInfo(p))
+	}
+	return resp, nil
+}
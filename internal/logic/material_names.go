@@ -0,0 +1,131 @@
+package logic
+
+import (
+	"time"
+
+	"jd_material_push/internal/naming"
+	"jd_material_push/internal/svc"
+	"jd_material_push/internal/types"
+)
+
+// nameSource 生成一个素材名称所需的原名称和投放设置
+type nameSource struct {
+	name         string
+	size         int64
+	mediaList    []string
+	categoryList []string
+	seq          int // 序号，为 0 时按在 sources 中的位置从 1 开始
+}
+
+// materialNames 按素材名称模板生成提交时的名称，模板为空时使用配置的 NameTemplate；都未配置时返回 nil，沿用原名称。
+// 生成的名称与 taken 中已使用的名称或彼此重复时追加 _2、_3
+func materialNames(svcCtx *svc.ServiceContext, template, campaign string, sources []nameSource, taken []string) (string, []naming.Result, error) {
+	if template == "" {
+		template = svcCtx.Config.NameTemplate
+	}
+	if template == "" {
+		return "", nil, nil
+	}
+	tmpl, err := naming.Parse(template, svcCtx.Config.NameMaxLength)
+	if err != nil {
+		return template, nil, err
+	}
+
+	cat := svcCtx.Catalog.Current()
+	labels := func(values []string, label func(string) string) []string {
+		out := make([]string, 0, len(values))
+		for _, v := range values {
+			out = append(out, label(v))
+		}
+		return out
+	}
+
+	now := time.Now()
+	results := make([]naming.Result, len(sources))
+	names := make([]string, len(sources))
+	for i, src := range sources {
+		seq := src.seq
+		if seq == 0 {
+			seq = i + 1
+		}
+		results[i] = tmpl.Render(naming.Vars{
+			Name:     src.name,
+			Size:     src.size,
+			Seq:      seq,
+			Campaign: campaign,
+			Media:    labels(src.mediaList, cat.MediaLabel),
+			Category: labels(src.categoryList, cat.CategoryLabel),
+			Now:      now,
+		})
+		names[i] = results[i].Name
+	}
+	for i, name := range tmpl.DedupeAgainst(taken, names) {
+		results[i].Name = name
+	}
+	return template, results, nil
+}
+
+// renameItems 按名称模板重命名待提交的素材，返回新的素材列表，不修改原列表；素材单独指定的投放设置优先
+func renameItems(svcCtx *svc.ServiceContext, req *types.SubmitMaterialBatchRequest) ([]types.MaterialItem, error) {
+	seqs, taken := jobNumbering(svcCtx, req)
+	sources := make([]nameSource, 0, len(req.MaterialList))
+	for i, item := range req.MaterialList {
+		src := nameSource{name: item.MaterialName, size: item.MaterialSize, mediaList: req.MediaList, categoryList: req.CategoryList, seq: seqs[i]}
+		if len(item.MediaList) > 0 {
+			src.mediaList = item.MediaList
+		}
+		if len(item.CategoryList) > 0 {
+			src.categoryList = item.CategoryList
+		}
+		sources = append(sources, src)
+	}
+
+	_, results, err := materialNames(svcCtx, req.NameTemplate, req.Campaign, sources, taken)
+	if err != nil || results == nil {
+		return req.MaterialList, err
+	}
+	items := append([]types.MaterialItem(nil), req.MaterialList...)
+	for i := range items {
+		items[i].MaterialName = results[i].Name
+	}
+	return items, nil
+}
+
+// jobNumbering 提交到已有任务（含重试和补交）时，素材按其在任务中的位置编号，任务中没有的素材接在最后，
+// 重复提交时序号不变；同时返回任务中其他素材已使用的名称。不属于任务时序号为 0，按提交顺序编号
+func jobNumbering(svcCtx *svc.ServiceContext, req *types.SubmitMaterialBatchRequest) ([]int, []string) {
+	seqs := make([]int, len(req.MaterialList))
+	if req.JobID == "" {
+		return seqs, nil
+	}
+	job, ok := svcCtx.Ledger.Get(req.JobID)
+	if !ok {
+		return seqs, nil
+	}
+
+	position := make(map[string]int, len(job.Materials))
+	for i, rec := range job.Materials {
+		if rec.URL != "" {
+			position[rec.URL] = i + 1
+		}
+	}
+	submitting := make(map[string]bool, len(req.MaterialList))
+	next := len(job.Materials)
+	for i, item := range req.MaterialList {
+		submitting[item.URL] = true
+		if seq, ok := position[item.URL]; ok {
+			seqs[i] = seq
+			continue
+		}
+		next++
+		seqs[i] = next
+	}
+
+	var taken []string
+	for _, rec := range job.Materials {
+		if rec.MaterialName != "" && !submitting[rec.URL] {
+			taken = append(taken, rec.MaterialName)
+		}
+	}
+	return seqs, taken
+}
@@ -0,0 +1,171 @@
+package logic
+
+import (
+	"fmt"
+
+	"jd_material_push/internal/preset"
+	"jd_material_push/internal/svc"
+	"jd_material_push/internal/transform"
+	"jd_material_push/internal/types"
+)
+
+// presetFields 请求中可由预设补全的字段，不支持的字段为 nil
+type presetFields struct {
+	mediaList          *[]string
+	categoryList       *[]string
+	releaseCopy        *string
+	copyVariants       *[]types.CopyVariant
+	copyVars           *map[string]string
+	columns            *map[string]interface{}
+	nameTemplate       *string
+	campaign           *string
+	profile            *string
+	order              *string
+	submitPolicy       *string
+	minUploadedPercent *int
+	isolateFailures    *bool
+}
+
+// applyPreset 按名称取预设补全请求中未填写的字段，列值和文案变量按 key 补全；
+// 预设要求了京东账号时，与当前 Cookie 的账号不一致则返回错误
+func applyPreset(svcCtx *svc.ServiceContext, name string, f presetFields) error {
+	if name == "" {
+		return nil
+	}
+	p, ok, err := svcCtx.Presets.Get(name)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("预设不存在: %s", name)
+	}
+	if p.Account != "" {
+		if account := svcCtx.CookieManager.Account(); account != p.Account {
+			return fmt.Errorf("预设 %s 要求使用账号 %s，当前登录的账号为 %s", p.Name, p.Account, displayAccount(account))
+		}
+	}
+
+	fillStrings(f.mediaList, p.MediaList)
+	fillStrings(f.categoryList, p.CategoryList)
+	fillString(f.releaseCopy, p.ReleaseCopy)
+	if f.copyVariants != nil && len(*f.copyVariants) == 0 {
+		*f.copyVariants = apiVariants(p.CopyVariants)
+	}
+	if f.copyVars != nil && len(p.CopyVars) > 0 {
+		if *f.copyVars == nil {
+			*f.copyVars = make(map[string]string, len(p.CopyVars))
+		}
+		for k, v := range p.CopyVars {
+			if (*f.copyVars)[k] == "" {
+				(*f.copyVars)[k] = v
+			}
+		}
+	}
+	if f.columns != nil && len(p.Columns) > 0 {
+		if *f.columns == nil {
+			*f.columns = make(map[string]interface{}, len(p.Columns))
+		}
+		for k, v := range p.Columns {
+			if _, ok := (*f.columns)[k]; !ok {
+				(*f.columns)[k] = v
+			}
+		}
+	}
+	fillString(f.nameTemplate, p.NameTemplate)
+	fillString(f.campaign, p.Campaign)
+	fillString(f.profile, p.Profile)
+	fillString(f.order, p.Order)
+	fillString(f.submitPolicy, p.SubmitPolicy)
+	if f.minUploadedPercent != nil && *f.minUploadedPercent == 0 {
+		*f.minUploadedPercent = p.MinUploadedPercent
+	}
+	if f.isolateFailures != nil && p.IsolateFailures {
+		*f.isolateFailures = true
+	}
+	return nil
+}
+
+func fillString(dst *string, v string) {
+	if dst != nil && *dst == "" {
+		*dst = v
+	}
+}
+
+func fillStrings(dst *[]string, v []string) {
+	if dst != nil && len(*dst) == 0 && len(v) > 0 {
+		*dst = append([]string(nil), v...)
+	}
+}
+
+func displayAccount(account string) string {
+	if account == "" {
+		return "（无法识别）"
+	}
+	return account
+}
+
+// checkPreset 按当前配置检查预设中的处理方案
+func checkPreset(svcCtx *svc.ServiceContext, p preset.Preset) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	if _, err := transform.Lookup(svcCtx.Config.Profiles, p.Profile); err != nil {
+		return fmt.Errorf("预设 %s: %w", p.Name, err)
+	}
+	return nil
+}
+
+// presetInfo 预设的接口表示
+func presetInfo(p preset.Preset) types.PresetInfo {
+	return types.PresetInfo{
+		Name:               p.Name,
+		Description:        p.Description,
+		Account:            p.Account,
+		MediaList:          p.MediaList,
+		CategoryList:       p.CategoryList,
+		ReleaseCopy:        p.ReleaseCopy,
+		CopyVariants:       apiVariants(p.CopyVariants),
+		CopyVars:           p.CopyVars,
+		Columns:            p.Columns,
+		NameTemplate:       p.NameTemplate,
+		Campaign:           p.Campaign,
+		Profile:            p.Profile,
+		Order:              p.Order,
+		SubmitPolicy:       p.SubmitPolicy,
+		MinUploadedPercent: p.MinUploadedPercent,
+		IsolateFailures:    p.IsolateFailures,
+	}
+}
+
+// fromPresetInfo 由接口请求构建预设
+func fromPresetInfo(in *types.PresetInfo) preset.Preset {
+	p := preset.Preset{
+		Name:               in.Name,
+		Description:        in.Description,
+		Account:            in.Account,
+		MediaList:          in.MediaList,
+		CategoryList:       in.CategoryList,
+		ReleaseCopy:        in.ReleaseCopy,
+		CopyVars:           in.CopyVars,
+		Columns:            in.Columns,
+		NameTemplate:       in.NameTemplate,
+		Campaign:           in.Campaign,
+		Profile:            in.Profile,
+		Order:              in.Order,
+		SubmitPolicy:       in.SubmitPolicy,
+		MinUploadedPercent: in.MinUploadedPercent,
+		IsolateFailures:    in.IsolateFailures,
+	}
+	for _, v := range in.CopyVariants {
+		p.CopyVariants = append(p.CopyVariants, preset.CopyVariant{CopyID: v.CopyID, Text: v.Text, Match: v.Match})
+	}
+	return p
+}
+
+func apiVariants(in []preset.CopyVariant) []types.CopyVariant {
+	out := make([]types.CopyVariant, 0, len(in))
+	for _, v := range in {
+		out = append(out, types.CopyVariant{CopyID: v.CopyID, Text: v.Text, Match: v.Match})
+	}
+	return out
+}
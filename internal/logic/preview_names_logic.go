@@ -0,0 +1,63 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+
+	"jd_material_push/internal/svc"
+	"jd_material_push/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type PreviewNamesLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewPreviewNamesLogic(ctx context.Context, svcCtx *svc.ServiceContext) *PreviewNamesLogic {
+	return &PreviewNamesLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// PreviewNames 按提交时相同的规则生成素材名称，不上传也不提交；序号按 files 的顺序，假定全部上传成功
+func (l *PreviewNamesLogic) PreviewNames(req *types.PreviewNamesRequest) (resp *types.PreviewNamesResponse, err error) {
+	resp = &types.PreviewNamesResponse{
+		Code:    200,
+		Message: "success",
+		Data:    []types.NamePreview{},
+	}
+
+	sources := make([]nameSource, 0, len(req.Files))
+	for _, f := range req.Files {
+		sources = append(sources, nameSource{name: f.FileName, size: f.FileSize, mediaList: req.MediaList, categoryList: req.CategoryList})
+	}
+	template, results, err := materialNames(l.svcCtx, req.NameTemplate, req.Campaign, sources, nil)
+	if err != nil {
+		resp.Code = 400
+		resp.Message = err.Error()
+		return resp, nil
+	}
+	resp.Template = template
+
+	truncated := 0
+	for i, f := range req.Files {
+		preview := types.NamePreview{FileName: f.FileName, Name: f.FileName}
+		if results != nil {
+			preview.Name = results[i].Name
+			preview.Truncated = results[i].Truncated
+		}
+		if preview.Truncated {
+			truncated++
+		}
+		resp.Data = append(resp.Data, preview)
+	}
+	if truncated > 0 {
+		resp.Message = fmt.Sprintf("%d 个名称超过 %d 个字符，已截断", truncated, l.svcCtx.Config.NameMaxLength)
+	}
+	return resp, nil
+}
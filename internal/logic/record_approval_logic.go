@@ -0,0 +1,49 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+
+	"jd_material_push/internal/ledger"
+	"jd_material_push/internal/svc"
+	"jd_material_push/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type RecordApprovalLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewRecordApprovalLogic(ctx context.Context, svcCtx *svc.ServiceContext) *RecordApprovalLogic {
+	return &RecordApprovalLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// RecordApproval 回填素材中心的审核结果；提交时只记录为待审核，通过或驳回需在素材中心查看后回填
+func (l *RecordApprovalLogic) RecordApproval(req *types.RecordApprovalRequest) (resp *types.RecordApprovalResponse, err error) {
+	resp = &types.RecordApprovalResponse{
+		Code:    200,
+		Message: "success",
+	}
+
+	updated, err := l.svcCtx.Ledger.UpdateMaterialByURL(req.URL, func(rec *ledger.MaterialRecord) {
+		rec.ApprovalStatus = req.Status
+		rec.ApprovalNote = req.Note
+	})
+	if err != nil {
+		return nil, err
+	}
+	if updated == 0 {
+		resp.Code = 404
+		resp.Message = fmt.Sprintf("台账中没有该素材: %s", req.URL)
+		return resp, nil
+	}
+	resp.Updated = updated
+	return resp, nil
+}
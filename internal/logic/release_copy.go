@@ -0,0 +1,174 @@
+package logic
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"jd_material_push/internal/catalog"
+	"jd_material_push/internal/copylib"
+	"jd_material_push/internal/ledger"
+	"jd_material_push/internal/svc"
+	"jd_material_push/internal/types"
+)
+
+// sidecarCopies 未单独指定投放文案的素材使用任务台账中记录的附带文案，返回新的素材列表，不修改原列表
+func sidecarCopies(svcCtx *svc.ServiceContext, jobID string, items []types.MaterialItem) []types.MaterialItem {
+	if jobID == "" {
+		return items
+	}
+	job, ok := svcCtx.Ledger.Get(jobID)
+	if !ok {
+		return items
+	}
+	out := append([]types.MaterialItem(nil), items...)
+	for i := range out {
+		if out[i].ReleaseCopy != "" {
+			continue
+		}
+		if rec := job.Material(out[i].URL); rec != nil {
+			out[i].ReleaseCopy = rec.ReleaseCopy
+		}
+	}
+	return out
+}
+
+// applyCopies 为每个素材确定投放文案：单独指定了文案的素材不变，其余按 copyVariants 的规则或轮流分配，
+// 都未分配到时使用 releaseCopy；展开模板变量后逐个按列定义的 length 校验。返回新的素材列表和用到的文案库 ID
+func applyCopies(svcCtx *svc.ServiceContext, req *types.SubmitMaterialBatchRequest) ([]types.MaterialItem, []string, error) {
+	variants, copyIDs, err := resolveVariants(svcCtx, req.CopyVariants)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	names := make([]string, len(req.MaterialList))
+	for i, item := range req.MaterialList {
+		names[i] = item.MaterialName
+	}
+	assigned, err := copylib.Assign(variants, names)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	limit := releaseLength(svcCtx.Catalog.Current().Schema())
+	var problems []string
+	check := func(source, fileName, text string) {
+		if limit > 0 && utf8.RuneCountInString(text) > limit {
+			problems = append(problems, fmt.Sprintf("%s（%s）超出长度限制 %d 字，当前 %d 字", source, fileName, limit, utf8.RuneCountInString(text)))
+		}
+	}
+
+	items := append([]types.MaterialItem(nil), req.MaterialList...)
+	used := make([]bool, len(variants))
+	for i := range items {
+		text, source := req.ReleaseCopy, "投放文案"
+		switch {
+		case items[i].ReleaseCopy != "":
+			text = items[i].ReleaseCopy
+		case assigned[i] >= 0:
+			text, source = variants[assigned[i]].Text, fmt.Sprintf("文案变体 %d", assigned[i]+1)
+			used[assigned[i]] = true
+		}
+
+		rendered, err := copylib.Render(text, req.CopyVars, items[i].MaterialName)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s（%s）: %v", source, items[i].MaterialName, err))
+			continue
+		}
+		check(source, items[i].MaterialName, rendered)
+		if rendered != req.ReleaseCopy {
+			items[i].ReleaseCopy = rendered
+		}
+	}
+
+	// 没有分配到素材的变体也要校验，避免保存到任务后重试时才发现超长
+	for i, v := range variants {
+		if used[i] || len(items) == 0 {
+			continue
+		}
+		if rendered, err := copylib.Render(v.Text, req.CopyVars, items[0].MaterialName); err == nil {
+			check(fmt.Sprintf("文案变体 %d", i+1), "未分配到素材", rendered)
+		}
+	}
+
+	if len(problems) > 0 {
+		return nil, nil, fmt.Errorf("投放文案不符合要求: %s", strings.Join(problems, "；"))
+	}
+	return items, copyIDs, nil
+}
+
+// resolveVariants 将文案库中的文案展开为内容
+func resolveVariants(svcCtx *svc.ServiceContext, in []types.CopyVariant) ([]copylib.Variant, []string, error) {
+	var variants []copylib.Variant
+	var copyIDs []string
+	for i, v := range in {
+		text := v.Text
+		if v.CopyID != "" {
+			entry, ok := svcCtx.CopyLibrary.Get(v.CopyID)
+			if !ok {
+				return nil, nil, fmt.Errorf("文案变体 %d: 文案库中不存在文案 %s", i+1, v.CopyID)
+			}
+			text = entry.Text
+			copyIDs = append(copyIDs, v.CopyID)
+		}
+		if strings.TrimSpace(text) == "" {
+			return nil, nil, fmt.Errorf("文案变体 %d 的内容不能为空", i+1)
+		}
+		variants = append(variants, copylib.Variant{Text: text, Match: strings.TrimSpace(v.Match)})
+	}
+	return variants, copyIDs, nil
+}
+
+// releaseLength 投放文案列的长度限制，未限制时为 0
+func releaseLength(columns []catalog.Column) int {
+	for _, col := range columns {
+		if col.Key == catalog.ColumnKeyRelease {
+			return col.Length
+		}
+	}
+	return 0
+}
+
+// ledgerVariants 保存到任务的文案变体，文案库中的文案以提交时的内容保存
+func ledgerVariants(svcCtx *svc.ServiceContext, in []types.CopyVariant) []ledger.CopyVariant {
+	variants, _, err := resolveVariants(svcCtx, in)
+	if err != nil {
+		return nil
+	}
+	out := make([]ledger.CopyVariant, 0, len(variants))
+	for _, v := range variants {
+		out = append(out, ledger.CopyVariant{Text: v.Text, Match: v.Match})
+	}
+	return out
+}
+
+// jobVariants 任务中保存的文案变体，用于补交和重试
+func jobVariants(job ledger.Job) []types.CopyVariant {
+	out := make([]types.CopyVariant, 0, len(job.CopyVariants))
+	for _, v := range job.CopyVariants {
+		out = append(out, types.CopyVariant{Text: v.Text, Match: v.Match})
+	}
+	return out
+}
+
+// copyEntry 文案库条目的接口表示
+func copyEntry(e copylib.Entry) types.CopyEntry {
+	out := types.CopyEntry{
+		ID:        e.ID,
+		Name:      e.Name,
+		Text:      e.Text,
+		Tags:      append([]string{}, e.Tags...),
+		Length:    utf8.RuneCountInString(e.Text),
+		Variables: copylib.Variables(e.Text),
+		UseCount:  e.UseCount,
+		UpdatedAt: e.UpdatedAt.Format(time.RFC3339),
+	}
+	if !e.LastUsedAt.IsZero() {
+		out.LastUsedAt = e.LastUsedAt.Format(time.RFC3339)
+	}
+	for _, r := range e.Revisions {
+		out.Revisions = append(out.Revisions, types.CopyRevision{Text: r.Text, UpdatedAt: r.UpdatedAt.Format(time.RFC3339)})
+	}
+	return out
+}
@@ -0,0 +1,44 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+
+	"jd_material_push/internal/svc"
+	"jd_material_push/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ReloadLexiconLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewReloadLexiconLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ReloadLexiconLogic {
+	return &ReloadLexiconLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// ReloadLexicon 立即重新加载违禁词词库；加载失败时继续使用原词库
+func (l *ReloadLexiconLogic) ReloadLexicon() (resp *types.ReloadLexiconResponse, err error) {
+	lex, err := l.svcCtx.Lexicon.Reload()
+	if err != nil {
+		l.Errorf("重新加载违禁词词库失败: %v", err)
+		return &types.ReloadLexiconResponse{
+			Code:    500,
+			Message: fmt.Sprintf("%v，继续使用原词库", err),
+			Terms:   lex.Size(),
+		}, nil
+	}
+
+	return &types.ReloadLexiconResponse{
+		Code:    200,
+		Message: fmt.Sprintf("词库已重新加载，共 %d 个词", lex.Size()),
+		Terms:   lex.Size(),
+	}, nil
+}
@@ -0,0 +1,144 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"jd_material_push/internal/ledger"
+	"jd_material_push/internal/svc"
+	"jd_material_push/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type RetryJobLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewRetryJobLogic(ctx context.Context, svcCtx *svc.ServiceContext) *RetryJobLogic {
+	return &RetryJobLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// RetryJob 重试已完成的任务：从原上传源只重新上传失败的文件，再按任务的投放设置提交尚未提交成功的素材，
+// 结果记录在同一个台账任务中。任务从未提交过（没有投放设置）时只重新上传
+func (l *RetryJobLogic) RetryJob(req *types.RetryJobRequest) (resp *types.RetryJobResponse, err error) {
+	resp = &types.RetryJobResponse{
+		Code:    200,
+		Message: "success",
+		JobID:   req.JobID,
+		Data:    []types.UploadResult{},
+	}
+
+	job, ok := l.svcCtx.Ledger.Get(req.JobID)
+	if !ok {
+		resp.Code = 404
+		resp.Message = fmt.Sprintf("任务不存在: %s", req.JobID)
+		return resp, nil
+	}
+
+	// 未指定处理方案时沿用任务的，重新上传的文件与首次上传的处理方式一致
+	profile := req.Profile
+	if profile == "" {
+		profile = job.Profile
+	}
+
+	failed := failedFiles(job)
+	if len(failed) > 0 {
+		if strings.HasPrefix(job.FolderPath, "upload:") {
+			resp.Code = 400
+			resp.Message = "浏览器上传的文件在请求结束后已删除，请重新上传失败的文件"
+			return resp, nil
+		}
+
+		l.Infof("任务 %s 重新上传 %d 个失败的文件", job.ID, len(failed))
+		uploadResp, err := NewUploadFilesLogic(l.ctx, l.svcCtx).UploadFiles(&types.UploadRequest{
+			FolderPath: job.FolderPath,
+			JobID:      job.ID,
+			Profile:    profile,
+			FileNames:  failed,
+			Order:      job.Order,
+		})
+		if err != nil {
+			return nil, err
+		}
+		resp.Data = uploadResp.Data
+		resp.Skipped = uploadResp.Skipped
+		if uploadResp.Code != 200 {
+			resp.Code = uploadResp.Code
+			resp.Message = uploadResp.Message
+			return resp, nil
+		}
+		job, _ = l.svcCtx.Ledger.Get(job.ID)
+	}
+
+	var items []types.MaterialItem
+	if len(job.MediaList) > 0 {
+		items = recordItems(unsubmittedRecords(job))
+	}
+	if len(failed) == 0 && len(items) == 0 {
+		resp.Code = 400
+		resp.Message = fmt.Sprintf("任务 %s 没有失败的文件或待提交的素材", job.ID)
+		return resp, nil
+	}
+
+	if len(items) > 0 {
+		resp.Batches, resp.Held = submitAll(l.ctx, l.svcCtx, items, types.SubmitMaterialBatchRequest{
+			MediaList:          job.MediaList,
+			CategoryList:       job.CategoryList,
+			ReleaseCopy:        job.ReleaseCopy,
+			JobID:              job.ID,
+			Columns:            job.Columns,
+			IsolateFailures:    job.IsolateFailures,
+			CategoryByFolder:   job.CategoryByFolder,
+			SubmitPolicy:       job.SubmitPolicy,
+			MinUploadedPercent: job.MinUploadedPercent,
+			NameTemplate:       job.NameTemplate,
+			Campaign:           job.Campaign,
+			CopyVariants:       jobVariants(job),
+			CopyVars:           job.CopyVars,
+			Profile:            profile,
+		})
+	}
+
+	resp.Message = fmt.Sprintf("重新上传 %d 个文件，成功 %d 个", len(resp.Data), countSuccessful(resp.Data))
+	if len(resp.Batches) > 0 {
+		resp.Message += fmt.Sprintf("；提交 %d 个素材，共 %d 批，成功 %d 批", len(items), len(resp.Batches), countSubmitted(resp.Batches))
+	}
+	if resp.Held != nil {
+		resp.Message += "；" + heldMessage(resp.Held)
+	}
+	return resp, nil
+}
+
+// failedFiles 任务中上传失败的文件（源内的相对路径）
+func failedFiles(job ledger.Job) []string {
+	var names []string
+	for _, rec := range job.Materials {
+		if rec.UploadStatus == ledger.UploadStatusFailed {
+			names = append(names, rec.FileName)
+		}
+	}
+	return names
+}
+
+// unsubmittedRecords 已上传但尚未提交成功的素材：未提交、提交失败或暂缓提交；已提交和已撤回的不再提交
+func unsubmittedRecords(job ledger.Job) []ledger.MaterialRecord {
+	var records []ledger.MaterialRecord
+	for _, rec := range job.Materials {
+		if rec.UploadStatus != ledger.UploadStatusUploaded || rec.URL == "" {
+			continue
+		}
+		switch rec.SubmitStatus {
+		case ledger.SubmitStatusNone, ledger.SubmitStatusFailed, ledger.SubmitStatusHeld:
+			records = append(records, rec)
+		}
+	}
+	return records
+}
@@ -0,0 +1,79 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"jd_material_push/internal/copylib"
+	"jd_material_push/internal/svc"
+	"jd_material_push/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// copyNameLength 未填写名称时取文案开头的字数
+const copyNameLength = 20
+
+type SaveCopyLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewSaveCopyLogic(ctx context.Context, svcCtx *svc.ServiceContext) *SaveCopyLogic {
+	return &SaveCopyLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// SaveCopy 新建或修改文案库中的文案；不含模板变量的文案按列定义的 length 校验，含变量的在提交时展开后校验
+func (l *SaveCopyLogic) SaveCopy(req *types.SaveCopyRequest) (resp *types.CopyResponse, err error) {
+	resp = &types.CopyResponse{
+		Code:    200,
+		Message: "success",
+	}
+
+	text := strings.TrimSpace(req.Text)
+	if text == "" {
+		resp.Code = 400
+		resp.Message = "文案内容不能为空"
+		return resp, nil
+	}
+	limit := releaseLength(l.svcCtx.Catalog.Current().Schema())
+	if len(copylib.Variables(text)) == 0 && limit > 0 && utf8.RuneCountInString(text) > limit {
+		resp.Code = 400
+		resp.Message = fmt.Sprintf("文案超出长度限制 %d 字，当前 %d 字", limit, utf8.RuneCountInString(text))
+		return resp, nil
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if req.ID != "" {
+		existing, ok := l.svcCtx.CopyLibrary.Get(req.ID)
+		if !ok {
+			resp.Code = 404
+			resp.Message = fmt.Sprintf("文案不存在: %s", req.ID)
+			return resp, nil
+		}
+		if name == "" {
+			name = existing.Name
+		}
+	}
+	if name == "" {
+		name = text
+		if runes := []rune(text); len(runes) > copyNameLength {
+			name = string(runes[:copyNameLength]) + "…"
+		}
+	}
+
+	entry, err := l.svcCtx.CopyLibrary.Save(req.ID, name, text, req.Tags)
+	if err != nil {
+		return nil, err
+	}
+	saved := copyEntry(entry)
+	resp.Data = &saved
+	return resp, nil
+}
@@ -0,0 +1,50 @@
+package logic
+
+import (
+	"context"
+
+	"jd_material_push/internal/svc"
+	"jd_material_push/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type SavePresetLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewSavePresetLogic(ctx context.Context, svcCtx *svc.ServiceContext) *SavePresetLogic {
+	return &SavePresetLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// SavePreset 新增预设或覆盖同名预设
+func (l *SavePresetLogic) SavePreset(req *types.PresetInfo) (resp *types.PresetResponse, err error) {
+	resp = &types.PresetResponse{
+		Code:    200,
+		Message: "success",
+	}
+
+	p := fromPresetInfo(req)
+	if err := checkPreset(l.svcCtx, p); err != nil {
+		resp.Code = 400
+		resp.Message = err.Error()
+		return resp, nil
+	}
+	if err := l.svcCtx.Presets.Save(p); err != nil {
+		return nil, err
+	}
+
+	saved, _, err := l.svcCtx.Presets.Get(p.Name)
+	if err != nil {
+		return nil, err
+	}
+	info := presetInfo(saved)
+	resp.Data = &info
+	return resp, nil
+}
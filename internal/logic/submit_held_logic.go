@@ -0,0 +1,96 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+
+	"jd_material_push/internal/ledger"
+	"jd_material_push/internal/svc"
+	"jd_material_push/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type SubmitHeldLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewSubmitHeldLogic(ctx context.Context, svcCtx *svc.ServiceContext) *SubmitHeldLogic {
+	return &SubmitHeldLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// SubmitHeld 补交任务中因提交策略暂缓的素材，连同修复后重新上传到该任务、尚未提交的素材一起，沿用暂缓时的提交设置；
+// 仍未满足提交策略时返回 409，force 为 true 时照常提交
+func (l *SubmitHeldLogic) SubmitHeld(req *types.SubmitHeldRequest) (resp *types.SubmitJobResponse, err error) {
+	resp = &types.SubmitJobResponse{
+		Code:    200,
+		Message: "success",
+		JobID:   req.JobID,
+	}
+
+	job, ok := l.svcCtx.Ledger.Get(req.JobID)
+	if !ok {
+		resp.Code = 404
+		resp.Message = fmt.Sprintf("任务不存在: %s", req.JobID)
+		return resp, nil
+	}
+
+	held := false
+	var records []ledger.MaterialRecord
+	for _, rec := range job.Materials {
+		if rec.UploadStatus != ledger.UploadStatusUploaded || rec.URL == "" {
+			continue
+		}
+		switch rec.SubmitStatus {
+		case ledger.SubmitStatusHeld:
+			held = true
+			records = append(records, rec)
+		case ledger.SubmitStatusNone:
+			records = append(records, rec)
+		}
+	}
+	if !held {
+		resp.Code = 400
+		resp.Message = fmt.Sprintf("任务 %s 没有暂缓提交的素材", job.ID)
+		return resp, nil
+	}
+	items := recordItems(records)
+
+	if !req.Force {
+		if report := evaluatePolicy(job, job.SubmitPolicy, job.MinUploadedPercent); report != nil {
+			for _, item := range items {
+				report.HeldMaterials = append(report.HeldMaterials, item.MaterialName)
+			}
+			resp.Code = 409
+			resp.Message = heldMessage(report)
+			resp.Total = len(items)
+			resp.Held = report
+			return resp, nil
+		}
+	}
+
+	resp.Total = len(items)
+	resp.Batches, _ = submitAll(l.ctx, l.svcCtx, items, types.SubmitMaterialBatchRequest{
+		MediaList:        job.MediaList,
+		CategoryList:     job.CategoryList,
+		ReleaseCopy:      job.ReleaseCopy,
+		JobID:            job.ID,
+		Columns:          job.Columns,
+		IsolateFailures:  job.IsolateFailures,
+		CategoryByFolder: job.CategoryByFolder,
+		NameTemplate:     job.NameTemplate,
+		Campaign:         job.Campaign,
+		CopyVariants:     jobVariants(job),
+		CopyVars:         job.CopyVars,
+		Profile:          job.Profile,
+	})
+
+	resp.Message = fmt.Sprintf("补交 %d 个素材，共 %d 批，成功 %d 批", resp.Total, len(resp.Batches), countSubmitted(resp.Batches))
+	return resp, nil
+}
@@ -0,0 +1,257 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"jd_material_push/internal/applyattr"
+	"jd_material_push/internal/ledger"
+	"jd_material_push/internal/media"
+	"jd_material_push/internal/source"
+	"jd_material_push/internal/svc"
+	"jd_material_push/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type SubmitJobLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewSubmitJobLogic(ctx context.Context, svcCtx *svc.ServiceContext) *SubmitJobLogic {
+	return &SubmitJobLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// SubmitJob 不重新上传，直接提交已在京东存储上的素材；素材来自请求中的清单或台账任务，按批提交并记录到新的台账任务
+func (l *SubmitJobLogic) SubmitJob(req *types.SubmitJobRequest) (resp *types.SubmitJobResponse, err error) {
+	resp = &types.SubmitJobResponse{
+		Code:    200,
+		Message: "success",
+	}
+
+	// 用预设补全未填写的投放设置
+	if err := applyPreset(l.svcCtx, req.Preset, presetFields{
+		mediaList:       &req.MediaList,
+		categoryList:    &req.CategoryList,
+		releaseCopy:     &req.ReleaseCopy,
+		copyVariants:    &req.CopyVariants,
+		copyVars:        &req.CopyVars,
+		columns:         &req.Columns,
+		nameTemplate:    &req.NameTemplate,
+		campaign:        &req.Campaign,
+		profile:         &req.Profile,
+		isolateFailures: &req.IsolateFailures,
+	}); err != nil {
+		resp.Code = 400
+		resp.Message = err.Error()
+		return resp, nil
+	}
+
+	var items []types.MaterialItem
+	var records []ledger.MaterialRecord
+	var source string
+	switch {
+	case len(req.Materials) > 0 && req.FromJobID != "":
+		resp.Code = 400
+		resp.Message = "materials 与 fromJobId 只能指定一个"
+		return resp, nil
+	case req.FromJobID != "":
+		job, ok := l.svcCtx.Ledger.Get(req.FromJobID)
+		if !ok {
+			resp.Code = 404
+			resp.Message = fmt.Sprintf("任务不存在: %s", req.FromJobID)
+			return resp, nil
+		}
+		records, err = uploadedRecords(job, req.FileNames)
+		if err != nil {
+			resp.Code = 400
+			resp.Message = err.Error()
+			return resp, nil
+		}
+		items = recordItems(records)
+		source = "resubmit:" + job.ID
+	case len(req.Materials) > 0:
+		if problems := validateHosted(req.Materials); len(problems) > 0 {
+			resp.Code = 400
+			resp.Message = "素材清单有误: " + strings.Join(problems, "；")
+			return resp, nil
+		}
+		items = hostedItems(req.Materials)
+		source = "manifest"
+	default:
+		resp.Code = 400
+		resp.Message = "请提供素材清单 materials 或台账任务 fromJobId"
+		return resp, nil
+	}
+	if len(items) == 0 {
+		resp.Code = 400
+		resp.Message = "没有可提交的素材"
+		return resp, nil
+	}
+
+	values := applyattr.Values(req.MediaList, req.CategoryList, req.ReleaseCopy, req.Columns)
+	if _, err := applyattr.Build(l.svcCtx.Catalog.Current().Schema(), values); err != nil {
+		resp.Code = 400
+		resp.Message = err.Error()
+		return resp, nil
+	}
+
+	resp.JobID = l.createJob(source, records)
+	resp.Total = len(items)
+	resp.Batches, _ = submitAll(l.ctx, l.svcCtx, items, types.SubmitMaterialBatchRequest{
+		MediaList:       req.MediaList,
+		CategoryList:    req.CategoryList,
+		ReleaseCopy:     req.ReleaseCopy,
+		JobID:           resp.JobID,
+		Columns:         req.Columns,
+		IsolateFailures: req.IsolateFailures,
+		NameTemplate:    req.NameTemplate,
+		Campaign:        req.Campaign,
+		CopyVariants:    req.CopyVariants,
+		CopyVars:        req.CopyVars,
+		Profile:         req.Profile,
+	})
+
+	resp.Message = fmt.Sprintf("提交 %d 个素材，共 %d 批，成功 %d 批", resp.Total, len(resp.Batches), countSubmitted(resp.Batches))
+	return resp, nil
+}
+
+// createJob 为本次提交新建台账任务；来自台账的素材先复制原记录，保留文件路径和元数据
+func (l *SubmitJobLogic) createJob(source string, records []ledger.MaterialRecord) string {
+	job, err := l.svcCtx.Ledger.CreateJob(source, l.svcCtx.CookieManager.Account())
+	if err != nil {
+		l.Errorf("创建台账任务失败: %v", err)
+		return ""
+	}
+	if len(records) == 0 {
+		return job.ID
+	}
+
+	err = l.svcCtx.Ledger.Update(job.ID, func(job *ledger.Job) {
+		for _, rec := range records {
+			rec.SubmitStatus = ledger.SubmitStatusNone
+			rec.BatchUUID = ""
+			rec.Message = ""
+			job.UpsertMaterial(rec)
+		}
+	})
+	if err != nil {
+		l.Errorf("复制台账记录失败: %v", err)
+	}
+	return job.ID
+}
+
+// uploadedRecords 取任务中已上传成功的素材，fileNames 非空时只取这些文件（按文件名或实际上传的文件名匹配）
+func uploadedRecords(job ledger.Job, fileNames []string) ([]ledger.MaterialRecord, error) {
+	wanted := make(map[string]bool, len(fileNames))
+	for _, name := range fileNames {
+		wanted[name] = true
+	}
+
+	var records []ledger.MaterialRecord
+	found := make(map[string]bool)
+	for _, rec := range job.Materials {
+		if rec.UploadStatus != ledger.UploadStatusUploaded || rec.URL == "" {
+			continue
+		}
+		if len(wanted) > 0 {
+			switch {
+			case wanted[rec.FileName]:
+				found[rec.FileName] = true
+			case rec.UploadName != "" && wanted[rec.UploadName]:
+				found[rec.UploadName] = true
+			default:
+				continue
+			}
+		}
+		records = append(records, rec)
+	}
+
+	var missing []string
+	for _, name := range fileNames {
+		if !found[name] {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("任务 %s 中没有已上传的素材: %s", job.ID, strings.Join(missing, "、"))
+	}
+	return records, nil
+}
+
+// recordItems 由台账记录构建待提交的素材
+func recordItems(records []ledger.MaterialRecord) []types.MaterialItem {
+	items := make([]types.MaterialItem, 0, len(records))
+	for _, rec := range records {
+		name := rec.FileName
+		if rec.UploadName != "" {
+			name = rec.UploadName
+		}
+		items = append(items, types.MaterialItem{
+			MaterialName: name,
+			MaterialSize: rec.FileSize,
+			MaterialType: rec.MaterialType,
+			URL:          rec.URL,
+			LocalURL:     rec.LocalURL,
+			Width:        rec.Width,
+			Height:       rec.Height,
+			Duration:     rec.Duration,
+			Codec:        rec.Codec,
+			ReleaseCopy:  rec.ReleaseCopy,
+			Folder:       source.Entry{Path: rec.FileName}.Dir(),
+		})
+	}
+	return items
+}
+
+func hostedItems(materials []types.HostedMaterial) []types.MaterialItem {
+	items := make([]types.MaterialItem, 0, len(materials))
+	for _, m := range materials {
+		items = append(items, types.MaterialItem{
+			MaterialName: strings.TrimSpace(m.Name),
+			MaterialSize: m.Size,
+			MaterialType: m.Type,
+			URL:          m.URL,
+			LocalURL:     m.LocalURL,
+		})
+	}
+	return items
+}
+
+// validateHosted 检查清单中每个素材的名称、URL、大小和类型，以及重复的 URL
+func validateHosted(materials []types.HostedMaterial) []string {
+	var problems []string
+	seen := make(map[string]int)
+	for i, m := range materials {
+		label := fmt.Sprintf("第 %d 个素材", i+1)
+		if name := strings.TrimSpace(m.Name); name != "" {
+			label += fmt.Sprintf("（%s）", name)
+		} else {
+			problems = append(problems, label+"缺少名称")
+		}
+
+		if u, err := url.Parse(m.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			problems = append(problems, label+"的 URL 无效")
+		} else if first, dup := seen[m.URL]; dup {
+			problems = append(problems, fmt.Sprintf("%s与第 %d 个素材的 URL 重复", label, first))
+		} else {
+			seen[m.URL] = i + 1
+		}
+
+		if m.Size <= 0 {
+			problems = append(problems, label+"的大小无效")
+		}
+		if m.Type != media.MaterialTypeImage && m.Type != media.MaterialTypeVideo {
+			problems = append(problems, label+"的类型无效（1 图片，2 视频）")
+		}
+	}
+	return problems
+}
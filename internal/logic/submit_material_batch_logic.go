@@ -1,15 +1,17 @@
 package logic
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
-	"log"
-	"net/http"
-	"net/url"
+	"strings"
+	"sync"
+	"time"
 
+	"jd_material_push/internal/ledger"
+	"jd_material_push/internal/lexicon"
+	"jd_material_push/internal/media"
+	"jd_material_push/internal/mediaspec"
 	"jd_material_push/internal/svc"
 	"jd_material_push/internal/types"
 
@@ -30,7 +32,31 @@ func NewSubmitMaterialBatchLogic(ctx context.Context, svcCtx *svc.ServiceContext
 	}
 }
 
+// SubmitMaterialBatch 提交任意数量的素材：按生成的 applyAttr（及可选的素材类型）分组，每组按 20 个一批，
+// 多批时并发提交；只有一批时返回素材中心的原始响应
 func (l *SubmitMaterialBatchLogic) SubmitMaterialBatch(req *types.SubmitMaterialBatchRequest) (resp *types.SubmitMaterialResponse, err error) {
+	// 用预设补全未填写的投放设置
+	if err := applyPreset(l.svcCtx, req.Preset, presetFields{
+		mediaList:          &req.MediaList,
+		categoryList:       &req.CategoryList,
+		releaseCopy:        &req.ReleaseCopy,
+		copyVariants:       &req.CopyVariants,
+		copyVars:           &req.CopyVars,
+		columns:            &req.Columns,
+		nameTemplate:       &req.NameTemplate,
+		campaign:           &req.Campaign,
+		profile:            &req.Profile,
+		submitPolicy:       &req.SubmitPolicy,
+		minUploadedPercent: &req.MinUploadedPercent,
+		isolateFailures:    &req.IsolateFailures,
+	}); err != nil {
+		return &types.SubmitMaterialResponse{
+			Code:    400,
+			Message: err.Error(),
+			Result:  false,
+		}, nil
+	}
+
 	// 检查素材列表
 	if len(req.MaterialList) == 0 {
 		return &types.SubmitMaterialResponse{
@@ -40,158 +66,449 @@ func (l *SubmitMaterialBatchLogic) SubmitMaterialBatch(req *types.SubmitMaterial
 		}, nil
 	}
 
-	if len(req.MaterialList) > 20 {
+	// 按子目录名称匹配素材品类，须在检查规格、生成名称和 applyAttr 之前
+	if req.CategoryByFolder {
+		for _, folder := range assignFolderCategories(l.svcCtx.Catalog.Current(), req.MaterialList) {
+			l.Infof("子目录 %s 未匹配到素材品类，使用请求中的品类", folder)
+		}
+	}
+
+	// 按所选投放媒体的规格检查素材，不符合 error 级别规格的素材不提交，作为失败结果返回，其余照常提交
+	specs := l.checkSpecs(req)
+	if len(specs.rejected) == len(req.MaterialList) {
+		l.recordRejected(req, specs)
+		resp = &types.SubmitMaterialResponse{
+			Code:    400,
+			Message: "素材均不符合投放媒体规格: " + specs.message(),
+			Result:  false,
+			Specs:   specs.violations,
+			JobID:   req.JobID,
+		}
+		resp.Batches, resp.Materials = specs.outcomes()
+		return resp, nil
+	}
+	req.MaterialList = specs.passed
+
+	// 上传时读到附带文案的素材以附带文案为投放文案，优先于文案变体；按文案变体和模板变量确定其余素材的投放文案，
+	// 在重命名之前按原文件名匹配规则
+	req.MaterialList = sidecarCopies(l.svcCtx, req.JobID, req.MaterialList)
+	withCopies, copyIDs, err := applyCopies(l.svcCtx, req)
+	if err != nil {
 		return &types.SubmitMaterialResponse{
 			Code:    400,
-			Message: "单次最多提交20个素材",
+			Message: err.Error(),
 			Result:  false,
 		}, nil
 	}
+	req.MaterialList = withCopies
 
-	// 构建 applyAttr
-	columnEnum := buildColumnEnum(req.MediaList, req.CategoryList)
-	applyAttr := map[string]interface{}{
-		"diyColumns": []map[string]interface{}{
-			{
-				"isRequired": true,
-				"columnType": 2,
-				"length":     30,
-				"isMultiple": 1,
-				"label":      "投放媒体",
-				"value":      req.MediaList,
-				"columnEnum": columnEnum["media"],
-				"key":        "media",
-			},
-			{
-				"isRequired": true,
-				"columnType": 2,
-				"length":     30,
-				"isMultiple": 1,
-				"label":      "素材所属品类",
-				"value":      req.CategoryList,
-				"columnEnum": columnEnum["category"],
-				"key":        "cate",
-			},
-			{
-				"isRequired": true,
-				"columnType": 3,
-				"length":     30,
-				"isMultiple": 2,
-				"label":      "投放文案",
-				"columnEnum": []map[string]string{{"value": "使用媒体平台推荐文案"}},
-				"key":        "release",
-				"value":      req.ReleaseCopy,
-			},
-		},
+	// 按素材名称模板生成提交的名称，台账仍按 URL 对应原记录
+	renamed, err := renameItems(l.svcCtx, req)
+	if err != nil {
+		return &types.SubmitMaterialResponse{
+			Code:    400,
+			Message: err.Error(),
+			Result:  false,
+		}, nil
 	}
-	log.Println("media:", req.MediaList)
-	log.Println("category:", req.CategoryList)
+	req.MaterialList = renamed
 
-	applyAttrJSON, _ := json.Marshal(applyAttr)
+	// 检查最终提交的投放文案和素材名称中的违禁词，error 级别阻止提交
+	compliance, err := l.checkCompliance(req)
+	if err != nil {
+		return &types.SubmitMaterialResponse{
+			Code:    400,
+			Message: err.Error(),
+			Result:  false,
+		}, nil
+	}
+	if compliance.blocked() {
+		return &types.SubmitMaterialResponse{
+			Code:       400,
+			Message:    compliance.message(),
+			Result:     false,
+			Compliance: compliance.hits,
+		}, nil
+	}
 
-	// 构建请求参数
-	param := map[string]interface{}{
-		"funName": "extAddMaterial",
-		"param": map[string]interface{}{
-			"isApproval":   1,
-			"materialList": req.MaterialList,
-			"systemCode":   "jdOrange",
-			"businessCode": "伙伴计划--美数科技",
-			"applyAttr":    string(applyAttrJSON),
-		},
-		"loginType": "3",
+	// 按列定义构建并校验 applyAttr，目录同步后已失效的取值会在这里被拦截
+	batches, err := planBatches(l.svcCtx.Catalog.Current().Schema(), req)
+	if err != nil {
+		return &types.SubmitMaterialResponse{
+			Code:    400,
+			Message: err.Error(),
+			Result:  false,
+		}, nil
 	}
 
-	bodyJSON, _ := json.Marshal(param)
+	// 按任务的提交策略判断是否提交，不满足时素材在台账中标记为暂缓，修复后通过 /api/jobs/submit-held 补交
+	held, errResp := l.checkHeld(req)
+	if errResp != nil {
+		return errResp, nil
+	}
+	if held != nil {
+		l.recordRejected(req, specs)
+		return &types.SubmitMaterialResponse{
+			Code:       409,
+			Message:    heldMessage(held),
+			Result:     false,
+			TotalNum:   len(req.MaterialList),
+			Held:       held,
+			Compliance: compliance.hits,
+			Specs:      specs.violations,
+		}, nil
+	}
+	// 未经本地上传、直接调用接口提交的素材也建立任务，记入推送历史
+	if req.JobID == "" {
+		job, err := l.svcCtx.Ledger.CreateJob("", l.svcCtx.CookieManager.Account())
+		if err != nil {
+			l.Errorf("创建台账任务失败: %v", err)
+		} else {
+			req.JobID = job.ID
+		}
+	}
+	if len(copyIDs) > 0 {
+		if err := l.svcCtx.CopyLibrary.MarkUsed(copyIDs...); err != nil {
+			l.Errorf("记录文案使用情况失败: %v", err)
+		}
+	}
+	l.recordRejected(req, specs)
 
-	// 获取 Cookie
-	cookie, err := l.svcCtx.CookieManager.GetCookie()
-	if err != nil {
-		return nil, fmt.Errorf("获取 Cookie 失败: %w", err)
+	if len(batches) == 1 {
+		attempts := l.runBatch(req, batches[0])
+		if len(attempts) == 1 && attempts[0].err == nil {
+			// 未拆分时返回素材中心的原始响应；网络错误等没有响应时与多批一样汇总，保留任务和未提交素材的结果
+			submitResp := attempts[0].resp
+			submitResp.Batches, submitResp.Materials = aggregate(batches, [][]attempt{attempts})
+			submitResp.Compliance = compliance.hits
+			submitResp.JobID = req.JobID
+			specs.attach(submitResp)
+			return submitResp, nil
+		}
+		resp = summarize(req, batches, [][]attempt{attempts})
+		resp.Compliance = compliance.hits
+		resp.JobID = req.JobID
+		specs.attach(resp)
+		return resp, nil
 	}
 
-	// 构建表单数据
-	formData := url.Values{}
-	formData.Set("appid", "materialCenter")
-	formData.Set("functionId", "material_center_api")
-	formData.Set("_", "1770018106")
-	formData.Set("loginType", "3")
-	formData.Set("body", string(bodyJSON))
+	// 多批时按顺序取出批次提交，SubmitConcurrency 为 1 时依次提交，平台上的顺序与批次顺序一致；单批失败不影响其他批次
+	l.Infof("共 %d 个素材，分 %d 批提交", len(req.MaterialList), len(batches))
+	results := make([][]attempt, len(batches))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(max(1, l.svcCtx.Config.SubmitConcurrency), len(batches)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				results[i] = l.runBatch(req, batches[i])
+			}
+		}()
+	}
+	for i := range batches {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
 
-	// 创建请求
-	apiURL := "https://api.m.jd.com/?functionId=material_center_api&appid=materialCenter"
-	httpReq, err := http.NewRequest("POST", apiURL, bytes.NewBufferString(formData.Encode()))
+	resp = summarize(req, batches, results)
+	resp.Compliance = compliance.hits
+	resp.JobID = req.JobID
+	specs.attach(resp)
+	return resp, nil
+}
+
+// checkCompliance 按处理方案的检查方式检查每个素材的投放文案和名称中的违禁词
+func (l *SubmitMaterialBatchLogic) checkCompliance(req *types.SubmitMaterialBatchRequest) (*complianceChecker, error) {
+	checker, err := newComplianceChecker(l.svcCtx, req.Profile)
 	if err != nil {
 		return nil, err
 	}
+	for _, item := range req.MaterialList {
+		releaseCopy := req.ReleaseCopy
+		if item.ReleaseCopy != "" {
+			releaseCopy = item.ReleaseCopy
+		}
+		checker.check(complianceFieldCopy, "", releaseCopy)
+		checker.check(complianceFieldName, item.MaterialName, item.MaterialName)
+	}
+	for _, h := range checker.hits {
+		if h.Severity != lexicon.SeverityError {
+			l.Infof("违禁词提示: %s 含「%s」", h.Highlight, h.Term)
+		}
+	}
+	return checker, nil
+}
+
+// runBatch 提交一个批次；开启 isolateFailures 时，被素材中心明确拒绝的批次对半拆分后递归重新提交，
+// 直到定位出被拒绝的单个素材，其余素材正常提交。网络错误等临时失败和投放媒体、品类等整批问题不拆分；
+// 每批最多调用 isolateMaxCalls 次，用完后剩余未定位的素材按最后一次的结果记为被拒绝
+func (l *SubmitMaterialBatchLogic) runBatch(req *types.SubmitMaterialBatchRequest, b submitBatch) []attempt {
+	budget := isolateMaxCalls - 1
+	return l.bisect(req, b, l.try(req, b, b.items), &budget)
+}
+
+// bisect 拆分被拒绝的一组素材，budget 为本批剩余的调用次数
+func (l *SubmitMaterialBatchLogic) bisect(req *types.SubmitMaterialBatchRequest, b submitBatch, a attempt, budget *int) []attempt {
+	if !req.IsolateFailures || len(a.items) == 1 || a.succeeded() || a.transient() {
+		return []attempt{a}
+	}
+	if a.batchWide() {
+		l.Infof("批次 %d 因投放设置被拒绝: %s，不拆分", b.index, a.message())
+		return []attempt{a}
+	}
+	if *budget < 2 {
+		l.Errorf("批次 %d 拆分次数已达上限 %d 次，%d 个素材未能逐个定位: %s", b.index, isolateMaxCalls, len(a.items), a.message())
+		return []attempt{a}
+	}
+	*budget -= 2
 
-	// 设置请求头
-	httpReq.Header.Set("Cookie", cookie)
-	httpReq.Header.Set("Origin", "https://jcheng.jd.com")
-	httpReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	l.Infof("批次 %d 中 %d 个素材被拒绝: %s，拆分后重新提交", b.index, len(a.items), a.message())
+	mid := len(a.items) / 2
+	left := l.try(req, b, a.items[:mid])
+	right := l.try(req, b, a.items[mid:])
+	return append(l.bisect(req, b, left, budget), l.bisect(req, b, right, budget)...)
+}
 
-	// 发送请求
-	client := &http.Client{}
-	httpResp, err := client.Do(httpReq)
+// try 提交一组素材
+func (l *SubmitMaterialBatchLogic) try(req *types.SubmitMaterialBatchRequest, b submitBatch, items []types.MaterialItem) attempt {
+	resp, err := l.submitItems(req, b.applyAttr, items)
 	if err != nil {
-		return nil, err
+		l.Errorf("提交批次 %d 失败: %v", b.index, err)
 	}
-	defer httpResp.Body.Close()
+	return attempt{items: items, resp: resp, err: err}
+}
 
-	// 读取响应
-	respBody, err := io.ReadAll(httpResp.Body)
+// submitItems 调用素材中心批量新增素材，并记录到台账
+func (l *SubmitMaterialBatchLogic) submitItems(req *types.SubmitMaterialBatchRequest, applyAttr string, items []types.MaterialItem) (*types.SubmitMaterialResponse, error) {
+	respBody, err := l.svcCtx.MaterialCenter.Call(l.ctx, "extAddMaterial", map[string]interface{}{
+		"isApproval":   1,
+		"materialList": materialPayload(items),
+		"applyAttr":    applyAttr,
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	logx.Infof("素材批量提交响应: %s", string(respBody))
-
 	// 解析响应
 	var submitResp types.SubmitMaterialResponse
 	if err := json.Unmarshal(respBody, &submitResp); err != nil {
 		return nil, fmt.Errorf("解析响应失败: %v, 响应内容: %s", err, string(respBody))
 	}
 
+	// 记录到台账，任务创建失败时不记录
+	if req.JobID != "" {
+		l.recordSubmit(req, items, &submitResp)
+	}
+
 	return &submitResp, nil
 }
 
-// buildColumnEnum 构建 columnEnum 数据
-func buildColumnEnum(mediaList, categoryList []string) map[string]interface{} {
-	// 投放媒体选项
-	mediaOptions := []map[string]string{
-		{"label": "巨量引擎", "value": "jlyq"},
-		{"label": "巨量星图", "value": "jlxt"},
-		{"label": "快手磁力智投", "value": "ksclzt"},
-		{"label": "快手磁力聚星", "value": "kscljx"},
-		{"label": "百度营销", "value": "bdyx"},
-		{"label": "广点通", "value": "gdt"},
-		{"label": "B站", "value": "bz"},
-		{"label": "趣头条", "value": "qtt"},
-	}
-
-	// 素材品类选项
-	categoryOptions := []map[string]string{
-		{"label": "本地生活/旅游出行", "value": "4938"},
-		{"label": "家庭清洁/纸品", "value": "15901"},
-		{"label": "鲜花/奢侈品", "value": "1672"},
-		{"label": "数码", "value": "652"},
-		{"label": "家用电器", "value": "737"},
-		{"label": "食品饮料", "value": "1320"},
-		{"label": "厨具", "value": "6196"},
-		{"label": "美妆护肤", "value": "1316"},
-		{"label": "手机通讯", "value": "9987"},
-		{"label": "服饰内衣", "value": "1315"},
-		{"label": "生活日用", "value": "1620"},
-		{"label": "个人护理", "value": "16750"},
-		{"label": "鞋靴", "value": "11729"},
-		{"label": "电脑、办公", "value": "670"},
-		{"label": "运动户外", "value": "1318"},
-		{"label": "生鲜", "value": "12218"},
-		{"label": "母婴", "value": "1319"},
-	}
-
-	return map[string]interface{}{
-		"media":    mediaOptions,
-		"category": categoryOptions,
+// checkHeld 按请求的提交策略检查任务的上传情况；不满足时将素材标记为暂缓并返回暂缓情况，参数有误时返回错误响应
+func (l *SubmitMaterialBatchLogic) checkHeld(req *types.SubmitMaterialBatchRequest) (*types.HeldReport, *types.SubmitMaterialResponse) {
+	fail := func(code int, message string) (*types.HeldReport, *types.SubmitMaterialResponse) {
+		return nil, &types.SubmitMaterialResponse{Code: code, Message: message, Result: false}
+	}
+	if err := checkPolicy(req.SubmitPolicy, req.MinUploadedPercent); err != nil {
+		return fail(400, err.Error())
+	}
+	if req.SubmitPolicy == "" || req.SubmitPolicy == submitPolicyPartial {
+		return nil, nil
+	}
+	if req.JobID == "" {
+		return fail(400, "使用提交策略时需要指定 jobId")
+	}
+	job, ok := l.svcCtx.Ledger.Get(req.JobID)
+	if !ok {
+		return fail(404, fmt.Sprintf("任务不存在: %s", req.JobID))
+	}
+
+	report := evaluatePolicy(job, req.SubmitPolicy, req.MinUploadedPercent)
+	if report == nil {
+		return nil, nil
+	}
+	for _, item := range req.MaterialList {
+		report.HeldMaterials = append(report.HeldMaterials, item.MaterialName)
+	}
+	l.Infof("任务 %s %s", req.JobID, heldMessage(report))
+	l.recordHeld(req, report)
+	return report, nil
+}
+
+// recordHeld 将暂缓提交的素材和本次的提交设置写入台账，补交时沿用
+func (l *SubmitMaterialBatchLogic) recordHeld(req *types.SubmitMaterialBatchRequest, report *types.HeldReport) {
+	err := l.svcCtx.Ledger.Update(req.JobID, func(job *ledger.Job) {
+		job.MediaList = req.MediaList
+		job.CategoryList = req.CategoryList
+		job.ReleaseCopy = req.ReleaseCopy
+		job.Columns = req.Columns
+		job.IsolateFailures = req.IsolateFailures
+		job.CategoryByFolder = req.CategoryByFolder
+		job.SubmitPolicy = req.SubmitPolicy
+		job.MinUploadedPercent = req.MinUploadedPercent
+		job.NameTemplate = req.NameTemplate
+		job.Campaign = req.Campaign
+		job.CopyVariants = ledgerVariants(l.svcCtx, req.CopyVariants)
+		job.CopyVars = req.CopyVars
+		if req.Profile != "" {
+			job.Profile = req.Profile
+		}
+		for _, item := range req.MaterialList {
+			rec := jobMaterial(job, item)
+			rec.MaterialName = item.MaterialName
+			rec.SubmitStatus = ledger.SubmitStatusHeld
+			rec.BatchUUID = ""
+			rec.Message = heldMessage(report)
+		}
+	})
+	if err != nil {
+		l.Errorf("记录暂缓提交到台账失败: %v", err)
+	}
+}
+
+// checkSpecs 按投放媒体规格检查每个素材（素材单独指定了投放媒体时按其设置），不符合 error 级别规格的素材剔除，
+// warning 级别只提示。元数据来自上传结果，未携带元数据的素材只检查大小
+func (l *SubmitMaterialBatchLogic) checkSpecs(req *types.SubmitMaterialBatchRequest) *specCheck {
+	c := &specCheck{}
+	for _, item := range req.MaterialList {
+		file := mediaspec.File{
+			Name:         item.MaterialName,
+			Size:         item.MaterialSize,
+			MaterialType: item.MaterialType,
+			Meta: media.Metadata{
+				Width:    item.Width,
+				Height:   item.Height,
+				Duration: item.Duration,
+				Codec:    item.Codec,
+			},
+		}
+		mediaList := req.MediaList
+		if len(item.MediaList) > 0 {
+			mediaList = item.MediaList
+		}
+		var problems []string
+		for _, v := range l.svcCtx.MediaSpecs.Check(mediaList, file) {
+			c.violations = append(c.violations, types.SpecViolation{
+				MaterialName: item.MaterialName,
+				URL:          item.URL,
+				Media:        v.Media,
+				Severity:     v.Severity,
+				Message:      v.Message,
+			})
+			msg := fmt.Sprintf("不符合%s规格: %s", v.Media, v.Message)
+			if v.Severity == mediaspec.SeverityWarning {
+				l.Infof("规格提示: %s %s", item.MaterialName, msg)
+				continue
+			}
+			problems = append(problems, msg)
+		}
+		if len(problems) > 0 {
+			c.rejected = append(c.rejected, specRejection{item: item, message: strings.Join(problems, "；")})
+		} else {
+			c.passed = append(c.passed, item)
+		}
+	}
+	return c
+}
+
+// recordRejected 将不符合规格而未提交的素材在台账中记为提交失败，任务创建失败时不记录
+func (l *SubmitMaterialBatchLogic) recordRejected(req *types.SubmitMaterialBatchRequest, specs *specCheck) {
+	if req.JobID == "" || len(specs.rejected) == 0 {
+		return
+	}
+	err := l.svcCtx.Ledger.Update(req.JobID, func(job *ledger.Job) {
+		for _, r := range specs.rejected {
+			rec := jobMaterial(job, r.item)
+			rec.MaterialName = r.item.MaterialName
+			rec.SubmitStatus = ledger.SubmitStatusFailed
+			rec.BatchUUID = ""
+			rec.Message = r.message
+		}
+	})
+	if err != nil {
+		l.Errorf("记录规格检查结果到台账失败: %v", err)
+	}
+}
+
+// recordSubmit 将一个批次的提交结果写入台账
+func (l *SubmitMaterialBatchLogic) recordSubmit(req *types.SubmitMaterialBatchRequest, items []types.MaterialItem, submitResp *types.SubmitMaterialResponse) {
+	status, approval := ledger.SubmitStatusFailed, ledger.ApprovalStatusNone
+	if submitResp.Code == 200 && submitResp.Result {
+		status, approval = ledger.SubmitStatusSubmitted, ledger.ApprovalStatusPending
+	}
+
+	now := time.Now()
+	err := l.svcCtx.Ledger.Update(req.JobID, func(job *ledger.Job) {
+		if job.Account == "" {
+			job.Account = l.svcCtx.CookieManager.Account()
+		}
+		job.MediaList = req.MediaList
+		job.CategoryList = req.CategoryList
+		job.ReleaseCopy = req.ReleaseCopy
+		job.Columns = req.Columns
+		job.IsolateFailures = req.IsolateFailures
+		job.CategoryByFolder = req.CategoryByFolder
+		if req.SubmitPolicy != "" {
+			// 补交时不带策略，保留任务原来的策略供之后重试
+			job.SubmitPolicy = req.SubmitPolicy
+			job.MinUploadedPercent = req.MinUploadedPercent
+		}
+		job.NameTemplate = req.NameTemplate
+		job.Campaign = req.Campaign
+		job.CopyVariants = ledgerVariants(l.svcCtx, req.CopyVariants)
+		job.CopyVars = req.CopyVars
+		if req.Profile != "" {
+			job.Profile = req.Profile
+		}
+		for _, item := range items {
+			rec := jobMaterial(job, item)
+			rec.MaterialName = item.MaterialName
+			rec.MaterialType = item.MaterialType
+			rec.SubmitStatus = status
+			rec.BatchUUID = submitResp.UUID
+			rec.Message = submitResp.Message
+			rec.ApprovalStatus = approval
+			rec.ApprovalNote = ""
+			rec.SubmittedAt = now
+		}
+	})
+	if err != nil {
+		l.Errorf("记录提交结果到台账失败: %v", err)
+	}
+}
+
+// jobMaterial 按 URL 取任务中的素材记录，不存在时（如直接提交未经本地上传的素材）新建
+func jobMaterial(job *ledger.Job, item types.MaterialItem) *ledger.MaterialRecord {
+	if rec := job.Material(item.URL); rec != nil {
+		return rec
+	}
+	job.UpsertMaterial(ledger.MaterialRecord{
+		FileName:     item.MaterialName,
+		FileSize:     item.MaterialSize,
+		MaterialType: item.MaterialType,
+		Width:        item.Width,
+		Height:       item.Height,
+		Duration:     item.Duration,
+		Codec:        item.Codec,
+		URL:          item.URL,
+		LocalURL:     item.LocalURL,
+		UploadStatus: ledger.UploadStatusUploaded,
+	})
+	return job.Material(item.URL)
+}
+
+// materialPayload 只保留素材中心接口认可的字段，本地解析的元数据和单个素材的投放设置不提交
+func materialPayload(items []types.MaterialItem) []map[string]interface{} {
+	payload := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		payload = append(payload, map[string]interface{}{
+			"materialName": item.MaterialName,
+			"materialSize": item.MaterialSize,
+			"materialType": item.MaterialType,
+			"url":          item.URL,
+			"localUrl":     item.LocalURL,
+		})
 	}
+	return payload
 }
@@ -0,0 +1,77 @@
+package logic
+
+import (
+	"fmt"
+
+	"jd_material_push/internal/ledger"
+	"jd_material_push/internal/types"
+)
+
+// 提交策略：任务中有文件上传失败时，上传成功的素材是否提交
+const (
+	submitPolicyPartial   = "partial"   // 上传成功的素材照常提交（默认）
+	submitPolicyAll       = "all"       // 全部文件上传成功才提交
+	submitPolicyThreshold = "threshold" // 上传成功比例达到 minUploadedPercent 才提交
+)
+
+// checkPolicy 校验提交策略及其参数
+func checkPolicy(policy string, minPercent int) error {
+	switch policy {
+	case "", submitPolicyPartial, submitPolicyAll:
+		return nil
+	case submitPolicyThreshold:
+		if minPercent < 1 || minPercent > 100 {
+			return fmt.Errorf("threshold 策略的 minUploadedPercent 需在 1-100 之间: %d", minPercent)
+		}
+		return nil
+	default:
+		return fmt.Errorf("未知的提交策略: %s（可选 partial、all、threshold）", policy)
+	}
+}
+
+// describePolicy 提交策略的说明
+func describePolicy(policy string, minPercent int) string {
+	switch policy {
+	case submitPolicyAll:
+		return "全部上传成功才提交"
+	case submitPolicyThreshold:
+		return fmt.Sprintf("上传成功 %d%% 以上才提交", minPercent)
+	default:
+		return "上传成功的素材照常提交"
+	}
+}
+
+// evaluatePolicy 按任务中各文件的上传状态判断是否满足提交策略；不满足时返回暂缓情况，HeldMaterials 由调用方填写
+func evaluatePolicy(job ledger.Job, policy string, minPercent int) *types.HeldReport {
+	report := &types.HeldReport{
+		Policy: describePolicy(policy, minPercent),
+		Total:  len(job.Materials),
+	}
+	for _, rec := range job.Materials {
+		if rec.UploadStatus == ledger.UploadStatusUploaded {
+			report.Uploaded++
+		} else {
+			report.FailedFiles = append(report.FailedFiles, rec.FileName)
+		}
+	}
+
+	switch policy {
+	case submitPolicyAll:
+		if report.Uploaded == report.Total {
+			return nil
+		}
+	case submitPolicyThreshold:
+		if report.Uploaded*100 >= minPercent*report.Total {
+			return nil
+		}
+	default:
+		return nil
+	}
+	return report
+}
+
+// heldMessage 暂缓提交的说明
+func heldMessage(report *types.HeldReport) string {
+	return fmt.Sprintf("未满足提交策略（%s）：%d 个文件上传成功 %d 个，%d 个素材暂缓提交，修复失败的文件后可补交",
+		report.Policy, report.Total, report.Uploaded, len(report.HeldMaterials))
+}
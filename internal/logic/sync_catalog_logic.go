@@ -0,0 +1,56 @@
+package logic
+
+import (
+	"context"
+	"time"
+
+	"jd_material_push/internal/catalog"
+	"jd_material_push/internal/svc"
+	"jd_material_push/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type SyncCatalogLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewSyncCatalogLogic(ctx context.Context, svcCtx *svc.ServiceContext) *SyncCatalogLogic {
+	return &SyncCatalogLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// SyncCatalog 从素材中心同步 diyColumns 列定义并更新本地目录缓存
+func (l *SyncCatalogLogic) SyncCatalog() (resp *types.SyncCatalogResponse, err error) {
+	snap, err := catalog.Fetch(l.ctx, l.svcCtx.MaterialCenter, l.svcCtx.Config.CatalogSyncMethod)
+	if err != nil {
+		l.Errorf("同步目录失败: %v", err)
+		return &types.SyncCatalogResponse{
+			Code:    500,
+			Message: err.Error(),
+		}, nil
+	}
+
+	changed, err := l.svcCtx.Catalog.Apply(snap)
+	if err != nil {
+		l.Errorf("保存目录缓存失败: %v", err)
+		return &types.SyncCatalogResponse{
+			Code:    500,
+			Message: err.Error(),
+		}, nil
+	}
+
+	l.Infof("目录同步完成，版本: %s，是否变化: %v", snap.Version, changed)
+	return &types.SyncCatalogResponse{
+		Code:     200,
+		Message:  "success",
+		Version:  snap.Version,
+		SyncedAt: snap.SyncedAt.Format(time.RFC3339),
+		Changed:  changed,
+	}, nil
+}
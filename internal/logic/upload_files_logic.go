@@ -12,7 +12,12 @@ import (
 	"path/filepath"
 	"sync"
 
+	"jd_material_push/internal/ledger"
+	"jd_material_push/internal/media"
+	"jd_material_push/internal/source"
 	"jd_material_push/internal/svc"
+	"jd_material_push/internal/thumbnail"
+	"jd_material_push/internal/transform"
 	"jd_material_push/internal/types"
 
 	"github.com/zeromicro/go-zero/core/logx"
@@ -39,6 +44,13 @@ func (l *UploadFilesLogic) UploadFiles(req *types.UploadRequest) (resp *types.Up
 		Data:    []types.UploadResult{},
 	}
 
+	// 用预设补全未填写的处理方案和上传顺序
+	if err := applyPreset(l.svcCtx, req.Preset, presetFields{profile: &req.Profile, order: &req.Order}); err != nil {
+		resp.Code = 400
+		resp.Message = err.Error()
+		return resp, nil
+	}
+
 	// 从内存中获取京橙平台的 Cookie
 	cookie, err := l.svcCtx.CookieManager.GetCookie()
 	if err != nil {
@@ -48,133 +60,381 @@ func (l *UploadFilesLogic) UploadFiles(req *types.UploadRequest) (resp *types.Up
 		return resp, nil
 	}
 
-	// 读取文件夹下的所有文件
-	files, err := os.ReadDir(req.FolderPath)
+	// 打开上传源（文件夹或压缩包），已跳过子目录和隐藏文件
+	src, err := source.Open(req.FolderPath)
 	if err != nil {
-		l.Errorf("读取文件夹失败: %v", err)
+		l.Errorf("读取上传源失败: %v", err)
 		resp.Code = 500
-		resp.Message = fmt.Sprintf("读取文件夹失败: %v", err)
+		resp.Message = fmt.Sprintf("读取上传源失败: %v", err)
 		return resp, nil
 	}
+	defer src.Close()
 
-	// 收集需要上传的文件
-	var filesToUpload []string
-	for _, file := range files {
-		if file.IsDir() {
+	// 按上传顺序排序，上传结果、提交分批和平台上的素材都保持这个顺序；指定了 fileNames 时按 fileNames 的顺序
+	order := req.Order
+	if order == "" {
+		order = l.svcCtx.Config.UploadOrder
+	}
+	// 按方案和 .pushignore 的规则跳过不需要上传的文件
+	profile, err := transform.Lookup(l.svcCtx.Config.Profiles, req.Profile)
+	if err != nil {
+		resp.Code = 400
+		resp.Message = err.Error()
+		return resp, nil
+	}
+	entries, skipped, err := source.Select(src, profile.Filter())
+	if err != nil {
+		resp.Code = 400
+		resp.Message = err.Error()
+		return resp, nil
+	}
+	// 指定了 fileNames 时只列出其中被跳过的文件
+	wanted := make(map[string]bool, len(req.FileNames))
+	for _, name := range req.FileNames {
+		wanted[name] = true
+	}
+	skippedRules := make(map[string]string, len(skipped))
+	for _, s := range skipped {
+		if len(wanted) > 0 && !wanted[s.Path] {
 			continue
 		}
-		fileName := file.Name()
-		// 跳过隐藏文件
-		if len(fileName) > 0 && fileName[0] == '.' {
-			continue
+		resp.Skipped = append(resp.Skipped, types.SkippedFile{FileName: s.Path, Rule: s.Rule})
+		skippedRules[s.Path] = s.Rule
+	}
+
+	entries, err = source.Sorted(entries, order)
+	if err != nil {
+		resp.Code = 400
+		resp.Message = err.Error()
+		return resp, nil
+	}
+
+	filesToUpload, missing := selectEntries(entries, req.FileNames)
+	var missingResults []types.UploadResult
+	for _, name := range missing {
+		// 被规则跳过的文件已列在 skipped 中，不算上传失败
+		if _, ok := skippedRules[name]; !ok {
+			missingResults = append(missingResults, types.UploadResult{FileName: name, ErrorMsg: "上传源中找不到该文件"})
 		}
-		filePath := filepath.Join(req.FolderPath, fileName)
-		filesToUpload = append(filesToUpload, filePath)
+	}
+	if len(filesToUpload) == 0 && len(missingResults) == 0 {
+		resp.Message = "没有找到可上传的文件"
+		if len(resp.Skipped) > 0 {
+			resp.Message += fmt.Sprintf("（%d 个文件被规则跳过）", len(resp.Skipped))
+		}
+		return resp, nil
 	}
 
 	if len(filesToUpload) == 0 {
-		resp.Message = "没有找到可上传的文件"
+		resp.Data = missingResults
+		resp.JobID = l.recordUpload(req, resp.Data)
+		resp.Message = "指定的文件在上传源中都不存在"
+		return resp, nil
+	}
+
+	// 构建上传前处理管道
+	pipeline, err := transform.Build(l.svcCtx.Config.Profiles, req.Profile, l.svcCtx.Config.Normalize)
+	if err != nil {
+		resp.Code = 400
+		resp.Message = err.Error()
 		return resp, nil
 	}
 
 	l.Infof("准备上传 %d 个文件", len(filesToUpload))
 
-	// 使用协程并发上传文件
+	// 附带文案在上传后读取，提交时作为该素材的投放文案；.tar.gz 只能顺序读取，读一遍压缩包依次上传
+	sidecars := source.Sidecars(src.Entries())
+	var results []types.UploadResult
+	if walker, ok := src.(source.Walker); ok {
+		results = l.uploadWalk(walker, filesToUpload, sidecars, pipeline, cookie)
+	} else {
+		results = l.uploadConcurrently(src, filesToUpload, sidecars, pipeline, cookie)
+	}
+	resp.Data = append(results, missingResults...)
+	l.Infof("所有文件上传完成，成功: %d, 总数: %d", countSuccessful(resp.Data), len(resp.Data))
+
+	// 记录到台账
+	resp.JobID = l.recordUpload(req, resp.Data)
+
+	return resp, nil
+}
+
+// recordUpload 将上传结果写入台账，返回任务 ID
+func (l *UploadFilesLogic) recordUpload(req *types.UploadRequest, results []types.UploadResult) string {
+	jobID := req.JobID
+	if jobID == "" {
+		job, err := l.svcCtx.Ledger.CreateJob(req.FolderPath, l.svcCtx.CookieManager.Account())
+		if err != nil {
+			l.Errorf("创建台账任务失败: %v", err)
+			return ""
+		}
+		jobID = job.ID
+	}
+
+	err := l.svcCtx.Ledger.Update(jobID, func(job *ledger.Job) {
+		// 保存处理方案和上传顺序，任务在提交前失败时重试也能沿用
+		if req.Profile != "" {
+			job.Profile = req.Profile
+		}
+		if req.Order != "" {
+			job.Order = req.Order
+		}
+		for _, r := range results {
+			rec := ledger.MaterialRecord{
+				FileName:     r.FileName,
+				FilePath:     filepath.Join(req.FolderPath, r.FileName),
+				FileSize:     r.FileSize,
+				MaterialType: r.MaterialType,
+				Width:        r.Width,
+				Height:       r.Height,
+				Duration:     r.Duration,
+				Codec:        r.Codec,
+				UploadName:   r.UploadName,
+				Steps:        toStepLogs(r.Steps),
+				URL:          r.URL,
+				LocalURL:     r.LocalURL,
+				UploadStatus: ledger.UploadStatusUploaded,
+				ReleaseCopy:  r.ReleaseCopy,
+				Thumbnail:    r.Thumbnail,
+			}
+			if !r.Success {
+				rec.UploadStatus = ledger.UploadStatusFailed
+				rec.Message = r.ErrorMsg
+			}
+			job.UpsertMaterial(rec)
+		}
+	})
+	if err != nil {
+		l.Errorf("记录上传结果到台账失败: %v", err)
+	}
+
+	return jobID
+}
+
+// selectEntries 只保留 fileNames 中的文件并按 fileNames 的顺序排列，fileNames 为空时保留全部；返回源中找不到的文件名
+func selectEntries(entries []source.Entry, fileNames []string) ([]source.Entry, []string) {
+	if len(fileNames) == 0 {
+		return entries, nil
+	}
+	byPath := make(map[string]source.Entry, len(entries))
+	for _, entry := range entries {
+		byPath[entry.Path] = entry
+	}
+
+	var selected []source.Entry
+	var missing []string
+	seen := make(map[string]bool, len(fileNames))
+	for _, name := range fileNames {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		if entry, ok := byPath[name]; ok {
+			selected = append(selected, entry)
+		} else {
+			missing = append(missing, name)
+		}
+	}
+	return selected, missing
+}
+
+func toTransformSteps(logs []transform.StepLog) []types.TransformStep {
+	var steps []types.TransformStep
+	for _, log := range logs {
+		steps = append(steps, types.TransformStep{Step: log.Step, Success: log.Success, Message: log.Message, Output: log.Output})
+	}
+	return steps
+}
+
+func toStepLogs(steps []types.TransformStep) []ledger.StepLog {
+	var logs []ledger.StepLog
+	for _, step := range steps {
+		logs = append(logs, ledger.StepLog{Step: step.Step, Success: step.Success, Message: step.Message, Output: step.Output})
+	}
+	return logs
+}
+
+// countSuccessful 统计成功上传的文件数量
+func countSuccessful(results []types.UploadResult) int {
+	count := 0
+	for _, r := range results {
+		if r.Success {
+			count++
+		}
+	}
+	return count
+}
+
+// uploadConcurrently 并发上传可随机读取的上传源中的文件，结果按上传顺序存放，不受完成先后影响
+func (l *UploadFilesLogic) uploadConcurrently(src source.Source, files []source.Entry, sidecars map[string]source.Entry, pipeline *transform.Pipeline, cookie string) []types.UploadResult {
 	var wg sync.WaitGroup
-	var mu sync.Mutex
 	maxConcurrent := 10 // 最大并发数
 	semaphore := make(chan struct{}, maxConcurrent)
+	results := make([]types.UploadResult, len(files))
 
-	for _, filePath := range filesToUpload {
+	for i, entry := range files {
 		wg.Add(1)
-		go func(fp string) {
+		go func(entry source.Entry, index int) {
 			defer wg.Done()
 
 			// 获取信号量
 			semaphore <- struct{}{}
 			defer func() { <-semaphore }()
 
-			fileName := filepath.Base(fp)
-			result := l.uploadSingleFile(fp, fileName, cookie)
-
-			// 安全地添加到结果列表
-			mu.Lock()
-			resp.Data = append(resp.Data, result)
-			mu.Unlock()
-		}(filePath)
+			results[index-1] = l.uploadSingleFile(src, entry, index, pipeline, cookie)
+		}(entry, i+1)
 	}
 
 	// 等待所有上传完成
 	wg.Wait()
-	l.Infof("所有文件上传完成，成功: %d, 总数: %d", countSuccessful(resp.Data), len(resp.Data))
 
-	return resp, nil
+	for i, entry := range files {
+		sidecar, ok := sidecars[entry.Path]
+		if !ok || !results[i].Success {
+			continue
+		}
+		text, err := source.ReadSidecar(src, sidecar)
+		if err != nil {
+			l.Errorf("%v", err)
+			continue
+		}
+		results[i].ReleaseCopy = text
+	}
+	return results
 }
 
-// countSuccessful 统计成功上传的文件数量
-func countSuccessful(results []types.UploadResult) int {
-	count := 0
-	for _, r := range results {
-		if r.Success {
-			count++
-		}
+// uploadSingleFile 上传单个文件到京橙平台，压缩包中的文件直接从压缩包读取
+// index 为文件在本次上传中的序号，供重命名模板使用
+func (l *UploadFilesLogic) uploadSingleFile(src source.Source, entry source.Entry, index int, pipeline *transform.Pipeline, cookie string) types.UploadResult {
+	if pipeline.Len() > 0 {
+		return l.uploadTransformed(entry, index, pipeline, cookie, func(dir string) (string, error) {
+			return source.Materialize(src, entry, dir)
+		})
 	}
-	return count
+
+	// 识别类型、读取元数据后重新打开文件上传，文件内容以流的方式写入请求，不整个读入内存
+	result := uploadResult(entry, entry.Name(), entry.Size)
+	typeInfo, meta, probeErr := source.Probe(src, entry)
+	if !l.checkType(&result, typeInfo, meta, probeErr) {
+		return result
+	}
+	open := func() (io.ReadCloser, error) { return src.Open(entry) }
+	if !l.uploadOpened(&result, entry.Name(), entry.Size, open, cookie) {
+		return result
+	}
+
+	localPath, _ := src.LocalPath(entry)
+	result.Thumbnail = l.thumbnail(src, entry, typeInfo, result.URL, localPath, open)
+	return result
 }
 
-// uploadSingleFile 上传单个文件到京橙平台
-func (l *UploadFilesLogic) uploadSingleFile(filePath, fileName, cookie string) types.UploadResult {
-	result := types.UploadResult{
-		FileName: fileName,
-		Success:  false,
+// uploadTransformed 执行上传前处理管道，上传最后一步的输出，原文件保持不变；materialize 将原文件写入处理目录
+// （压缩包中的文件先写入临时目录），返回其路径
+func (l *UploadFilesLogic) uploadTransformed(entry source.Entry, index int, pipeline *transform.Pipeline, cookie string, materialize func(dir string) (string, error)) types.UploadResult {
+	// 文件名使用源内的相对路径，压缩包中不同子目录下的同名文件可以区分
+	fileName := entry.Path
+	result := types.UploadResult{FileName: fileName}
+
+	workDir, err := os.MkdirTemp("", "jdpush-transform-")
+	if err != nil {
+		result.ErrorMsg = fmt.Sprintf("创建处理目录失败: %v", err)
+		l.Errorf("创建处理目录失败 %s: %v", fileName, err)
+		return result
 	}
+	defer os.RemoveAll(workDir)
 
-	// 读取文件
-	fileData, err := os.Open(filePath)
+	inputPath, err := materialize(workDir)
 	if err != nil {
-		result.ErrorMsg = fmt.Sprintf("打开文件失败: %v", err)
-		l.Errorf("打开文件失败 %s: %v", fileName, err)
+		result.ErrorMsg = fmt.Sprintf("读取文件失败: %v", err)
+		l.Errorf("读取文件失败 %s: %v", fileName, err)
 		return result
 	}
-	defer fileData.Close()
 
-	// 获取文件信息
-	fileInfo, err := fileData.Stat()
+	out, logs, err := pipeline.Run(l.ctx, transform.File{Path: inputPath, Name: entry.Name(), Index: index}, workDir)
+	result.Steps = toTransformSteps(logs)
+	if err != nil {
+		result.ErrorMsg = fmt.Sprintf("上传前处理失败: %v", err)
+		l.Errorf("上传前处理失败 %s: %v", fileName, err)
+		return result
+	}
+	if out.Path != inputPath {
+		result.OriginalSize = entry.Size
+	}
+	if out.Name != fileName {
+		result.UploadName = out.Name
+	}
+
+	info, err := os.Stat(out.Path)
 	if err != nil {
 		result.ErrorMsg = fmt.Sprintf("获取文件信息失败: %v", err)
 		l.Errorf("获取文件信息失败 %s: %v", fileName, err)
 		return result
 	}
-	result.FileSize = fileInfo.Size()
+	result.FileSize = info.Size()
+	typeInfo, meta, probeErr := media.Probe(out.Path)
+	if !l.checkType(&result, typeInfo, meta, probeErr) {
+		return result
+	}
+	open := func() (io.ReadCloser, error) { return os.Open(out.Path) }
+	if !l.uploadOpened(&result, out.Name, info.Size(), open, cookie) {
+		return result
+	}
 
-	// 创建 multipart form
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
+	result.Thumbnail = l.thumbnail(nil, entry, typeInfo, result.URL, out.Path, open)
+	return result
+}
 
-	// 添加表单字段
-	_ = writer.WriteField("systemCode", "jdOrange")
-	_ = writer.WriteField("businessCode", "伙伴计划--美数科技")
+// uploadResult 上传结果的初始值：文件名使用源内的相对路径，压缩包中不同子目录下的同名文件可以区分
+func uploadResult(entry source.Entry, uploadName string, size int64) types.UploadResult {
+	result := types.UploadResult{FileName: entry.Path, FileSize: size}
+	if uploadName != entry.Path {
+		result.UploadName = uploadName
+	}
+	return result
+}
 
-	// 添加文件
-	part, err := writer.CreateFormFile("file", fileName)
-	if err != nil {
-		result.ErrorMsg = fmt.Sprintf("创建文件表单失败: %v", err)
-		l.Errorf("创建文件表单失败 %s: %v", fileName, err)
-		return result
+// checkType 按文件内容识别的素材类型和元数据填入结果，不支持的类型不上传，返回 false
+func (l *UploadFilesLogic) checkType(result *types.UploadResult, typeInfo media.TypeInfo, meta media.Metadata, probeErr error) bool {
+	if probeErr != nil && typeInfo.Kind == "" {
+		result.ErrorMsg = fmt.Sprintf("识别文件类型失败: %v", probeErr)
+		l.Errorf("识别文件类型失败 %s: %v", result.FileName, probeErr)
+		return false
+	}
+	result.MaterialType = typeInfo.MaterialType
+	result.MimeType = typeInfo.MimeType
+
+	// 尺寸、时长等元数据解析失败不影响上传
+	if probeErr != nil {
+		l.Errorf("读取元数据失败 %s: %v", result.FileName, probeErr)
 	}
+	result.Width = meta.Width
+	result.Height = meta.Height
+	result.Duration = meta.Duration
+	result.Codec = meta.Codec
+	return true
+}
 
-	_, err = io.Copy(part, fileData)
+// uploadOpened 打开文件并上传，上传失败时返回 false
+func (l *UploadFilesLogic) uploadOpened(result *types.UploadResult, uploadName string, size int64, open func() (io.ReadCloser, error), cookie string) bool {
+	fileData, err := open()
 	if err != nil {
-		result.ErrorMsg = fmt.Sprintf("复制文件数据失败: %v", err)
-		l.Errorf("复制文件数据失败 %s: %v", fileName, err)
-		return result
+		result.ErrorMsg = fmt.Sprintf("打开文件失败: %v", err)
+		l.Errorf("打开文件失败 %s: %v", result.FileName, err)
+		return false
 	}
+	defer fileData.Close()
+	return l.post(result, uploadName, fileData, size, cookie)
+}
 
-	err = writer.Close()
+// post 将文件内容以流的方式上传到京橙平台，成功时填入 URL，失败时填入错误信息并返回 false
+func (l *UploadFilesLogic) post(result *types.UploadResult, uploadName string, r io.Reader, size int64, cookie string) bool {
+	fileName := result.FileName
+
+	body, contentType, contentLength, err := uploadForm(uploadName, r, size)
 	if err != nil {
-		result.ErrorMsg = fmt.Sprintf("关闭 writer 失败: %v", err)
-		l.Errorf("关闭 writer 失败 %s: %v", fileName, err)
-		return result
+		result.ErrorMsg = fmt.Sprintf("创建文件表单失败: %v", err)
+		l.Errorf("创建文件表单失败 %s: %v", fileName, err)
+		return false
 	}
 
 	// 创建 HTTP 请求
@@ -183,11 +443,12 @@ func (l *UploadFilesLogic) uploadSingleFile(filePath, fileName, cookie string) t
 	if err != nil {
 		result.ErrorMsg = fmt.Sprintf("创建请求失败: %v", err)
 		l.Errorf("创建请求失败 %s: %v", fileName, err)
-		return result
+		return false
 	}
+	httpReq.ContentLength = contentLength
 
 	// 设置请求头
-	httpReq.Header.Set("Content-Type", writer.FormDataContentType())
+	httpReq.Header.Set("Content-Type", contentType)
 	httpReq.Header.Set("Cookie", cookie)
 
 	// 发送请求
@@ -196,7 +457,7 @@ func (l *UploadFilesLogic) uploadSingleFile(filePath, fileName, cookie string) t
 	if err != nil {
 		result.ErrorMsg = fmt.Sprintf("发送请求失败: %v", err)
 		l.Errorf("发送请求失败 %s: %v", fileName, err)
-		return result
+		return false
 	}
 	defer httpResp.Body.Close()
 
@@ -205,7 +466,7 @@ func (l *UploadFilesLogic) uploadSingleFile(filePath, fileName, cookie string) t
 	if err != nil {
 		result.ErrorMsg = fmt.Sprintf("读取响应失败: %v", err)
 		l.Errorf("读取响应失败 %s: %v", fileName, err)
-		return result
+		return false
 	}
 
 	// 解析响应
@@ -213,14 +474,14 @@ func (l *UploadFilesLogic) uploadSingleFile(filePath, fileName, cookie string) t
 	if err := json.Unmarshal(respBody, &jcResp); err != nil {
 		result.ErrorMsg = fmt.Sprintf("解析响应失败: %v, 响应内容: %s", err, string(respBody))
 		l.Errorf("解析响应失败 %s: %v, 响应: %s", fileName, err, string(respBody))
-		return result
+		return false
 	}
 
 	// 检查响应状态
 	if jcResp.Code != 200 {
 		result.ErrorMsg = fmt.Sprintf("上传失败: %s", jcResp.Message)
 		l.Errorf("上传失败 %s: code=%d, message=%s", fileName, jcResp.Code, jcResp.Message)
-		return result
+		return false
 	}
 
 	// 上传成功
@@ -228,6 +489,75 @@ func (l *UploadFilesLogic) uploadSingleFile(filePath, fileName, cookie string) t
 	result.URL = jcResp.Result.URL
 	result.LocalURL = jcResp.Result.LocalURL
 	l.Infof("上传成功 %s", fileName)
+	return true
+}
 
-	return result
+// thumbnail 由实际上传的文件（经过处理时为处理后的副本）生成缩略图并保存，返回文件名；
+// 视频需要安装 ffmpeg，.zip 中的视频先写入临时目录
+func (l *UploadFilesLogic) thumbnail(src source.Source, entry source.Entry, typeInfo media.TypeInfo, url, localPath string, open func() (io.ReadCloser, error)) string {
+	var (
+		data []byte
+		err  error
+	)
+	switch typeInfo.MaterialType {
+	case media.MaterialTypeImage:
+		var r io.ReadCloser
+		if r, err = open(); err == nil {
+			data, err = thumbnail.Image(r)
+			r.Close()
+		}
+	case media.MaterialTypeVideo:
+		if !thumbnail.VideoSupported() {
+			return ""
+		}
+		if localPath == "" {
+			workDir, mkErr := os.MkdirTemp("", "jdpush-thumb-")
+			if mkErr != nil {
+				l.Errorf("生成缩略图失败 %s: %v", entry.Path, mkErr)
+				return ""
+			}
+			defer os.RemoveAll(workDir)
+			if localPath, err = source.Materialize(src, entry, workDir); err != nil {
+				break
+			}
+		}
+		data, err = thumbnail.Video(l.ctx, localPath)
+	default:
+		return ""
+	}
+	return l.saveThumbnail(entry, url, data, err)
+}
+
+// saveThumbnail 保存生成的缩略图，返回文件名；生成失败只记录日志，不影响上传
+func (l *UploadFilesLogic) saveThumbnail(entry source.Entry, url string, data []byte, err error) string {
+	if err != nil {
+		l.Errorf("生成缩略图失败 %s: %v", entry.Path, err)
+		return ""
+	}
+	name, err := thumbnail.Save(l.svcCtx.Config.ThumbnailDir, url, data)
+	if err != nil {
+		l.Errorf("%v", err)
+		return ""
+	}
+	return name
+}
+
+// uploadForm 构建上传表单：表单头和结尾的分隔符预先生成，文件内容从 r 流式写入请求，不整个读入内存；
+// size 为文件大小，用于给出完整的 Content-Length
+func uploadForm(uploadName string, r io.Reader, size int64) (io.Reader, string, int64, error) {
+	var form bytes.Buffer
+	writer := multipart.NewWriter(&form)
+	_ = writer.WriteField("systemCode", "jdOrange")
+	_ = writer.WriteField("businessCode", "伙伴计划--美数科技")
+	if _, err := writer.CreateFormFile("file", uploadName); err != nil {
+		return nil, "", 0, err
+	}
+	headLen := form.Len()
+	if err := writer.Close(); err != nil {
+		return nil, "", 0, err
+	}
+
+	head, tail := form.Bytes()[:headLen], form.Bytes()[headLen:]
+	body := io.MultiReader(bytes.NewReader(head), io.LimitReader(r, size), bytes.NewReader(tail))
+	return body, writer.FormDataContentType(), int64(form.Len()) + size, nil
 }
@@ -0,0 +1,205 @@
+package logic
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"jd_material_push/internal/media"
+	"jd_material_push/internal/source"
+	"jd_material_push/internal/thumbnail"
+	"jd_material_push/internal/transform"
+	"jd_material_push/internal/types"
+)
+
+// errStreamDone 文件已上传完，压缩包的解压流已交给下一个文件
+var errStreamDone = errors.New("文件已读取完毕")
+
+// uploadWalk 只能顺序读取的压缩包（.tar.gz）从头到尾读一遍，按压缩包中的顺序依次上传，不解压到磁盘；
+// 附带文案在同一遍中读取。结果仍按上传顺序存放
+func (l *UploadFilesLogic) uploadWalk(src source.Walker, files []source.Entry, sidecars map[string]source.Entry, pipeline *transform.Pipeline, cookie string) []types.UploadResult {
+	results := make([]types.UploadResult, len(files))
+	index := make(map[string]int, len(files))
+	wanted := append([]source.Entry(nil), files...)
+	copyOf := make(map[string][]string) // 附带文案 → 使用它的素材
+	for i, entry := range files {
+		index[entry.Path] = i
+		if sidecar, ok := sidecars[entry.Path]; ok {
+			if _, added := copyOf[sidecar.Path]; !added {
+				wanted = append(wanted, sidecar)
+			}
+			copyOf[sidecar.Path] = append(copyOf[sidecar.Path], entry.Path)
+		}
+	}
+
+	copies := make(map[string]string)
+	err := src.Walk(wanted, func(entry source.Entry, r io.Reader) error {
+		if materials, ok := copyOf[entry.Path]; ok {
+			text, err := source.ParseSidecar(entry, r)
+			if err != nil {
+				l.Errorf("%v", err)
+				return nil
+			}
+			for _, path := range materials {
+				copies[path] = text
+			}
+			return nil
+		}
+		i := index[entry.Path]
+		results[i] = l.uploadStreamed(entry, r, i+1, pipeline, cookie)
+		return nil
+	})
+	if err != nil {
+		l.Errorf("读取压缩包失败: %v", err)
+	}
+
+	for i, entry := range files {
+		// 压缩包读到一半出错时，之后的文件没有读到
+		if results[i].FileName == "" {
+			results[i] = types.UploadResult{FileName: entry.Path, ErrorMsg: fmt.Sprintf("读取压缩包失败: %v", err)}
+			if err == nil {
+				results[i].ErrorMsg = "压缩包中不存在该文件"
+			}
+			continue
+		}
+		if text, ok := copies[entry.Path]; ok && results[i].Success {
+			results[i].ReleaseCopy = text
+		}
+	}
+	return results
+}
+
+// uploadStreamed 上传只能读一遍的文件：按文件头识别类型后直接写入上传请求，同时在后台读取元数据、生成缩略图；
+// 只有处理管道需要文件路径时才写入临时目录
+func (l *UploadFilesLogic) uploadStreamed(entry source.Entry, r io.Reader, index int, pipeline *transform.Pipeline, cookie string) types.UploadResult {
+	if pipeline.Len() > 0 {
+		return l.uploadTransformed(entry, index, pipeline, cookie, func(dir string) (string, error) {
+			return source.MaterializeFrom(entry, r, dir)
+		})
+	}
+
+	result := uploadResult(entry, entry.Name(), entry.Size)
+	br := bufio.NewReader(r)
+	head, err := br.Peek(media.SniffSize)
+	if err != nil && err != io.EOF {
+		return l.failType(result, err)
+	}
+	typeInfo, err := media.Sniff(head)
+	if err != nil {
+		return l.failType(result, err)
+	}
+
+	var (
+		tee      streamTee
+		meta     media.Metadata
+		probed   media.TypeInfo
+		probeErr error
+		thumb    []byte
+		thumbErr error
+		video    string // 视频写入的临时文件，供 ffmpeg 截取画面
+	)
+	tee.add(func(r io.Reader) { probed, meta, probeErr = media.ProbeStream(r) })
+	switch typeInfo.MaterialType {
+	case media.MaterialTypeImage:
+		tee.add(func(r io.Reader) { thumb, thumbErr = thumbnail.Image(r) })
+	case media.MaterialTypeVideo:
+		if !thumbnail.VideoSupported() {
+			break
+		}
+		workDir, err := os.MkdirTemp("", "jdpush-thumb-")
+		if err != nil {
+			thumbErr = err
+			break
+		}
+		defer os.RemoveAll(workDir)
+		tee.add(func(r io.Reader) { video, thumbErr = source.MaterializeFrom(entry, r, workDir) })
+	}
+
+	body := &guardedReader{r: io.TeeReader(br, tee.writer())}
+	ok := l.post(&result, entry.Name(), body, entry.Size, cookie)
+	body.Close()
+	tee.close()
+
+	// 类型以读完整个文件后的识别为准（GIF 是否为动图需要统计帧数）
+	if probed.Kind != "" {
+		typeInfo = probed
+	}
+	if !l.checkType(&result, typeInfo, meta, probeErr) || !ok {
+		return result
+	}
+
+	if video != "" && thumbErr == nil {
+		thumb, thumbErr = thumbnail.Video(l.ctx, video)
+	}
+	if thumb != nil || thumbErr != nil {
+		result.Thumbnail = l.saveThumbnail(entry, result.URL, thumb, thumbErr)
+	}
+	return result
+}
+
+// failType 文件类型识别失败，不上传
+func (l *UploadFilesLogic) failType(result types.UploadResult, err error) types.UploadResult {
+	l.checkType(&result, media.TypeInfo{}, media.Metadata{}, err)
+	return result
+}
+
+// streamTee 把上传读出的内容同时交给后台的读取者，每个读取者在自己的协程中顺序读取
+type streamTee struct {
+	writers []*io.PipeWriter
+	wg      sync.WaitGroup
+}
+
+func (t *streamTee) add(fn func(r io.Reader)) {
+	pr, pw := io.Pipe()
+	t.writers = append(t.writers, pw)
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		fn(pr)
+		// 读取者提前结束时继续读完，不阻塞上传
+		io.Copy(io.Discard, pr)
+	}()
+}
+
+func (t *streamTee) writer() io.Writer {
+	writers := make([]io.Writer, len(t.writers))
+	for i, w := range t.writers {
+		writers[i] = w
+	}
+	return io.MultiWriter(writers...)
+}
+
+// close 通知读取者内容已结束，等待读取完成
+func (t *streamTee) close() {
+	for _, w := range t.writers {
+		w.Close()
+	}
+	t.wg.Wait()
+}
+
+// guardedReader 关闭后不再读取底层内容：HTTP 请求返回后传输层可能仍在读取请求体，
+// 而压缩包的解压流要继续读取下一个文件
+type guardedReader struct {
+	mu     sync.Mutex
+	r      io.Reader
+	closed bool
+}
+
+func (g *guardedReader) Read(p []byte) (int, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.closed {
+		return 0, errStreamDone
+	}
+	return g.r.Read(p)
+}
+
+func (g *guardedReader) Close() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.closed = true
+	return nil
+}
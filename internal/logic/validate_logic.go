@@ -0,0 +1,125 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"jd_material_push/internal/applyattr"
+	"jd_material_push/internal/copylib"
+	"jd_material_push/internal/preflight"
+	"jd_material_push/internal/svc"
+	"jd_material_push/internal/transform"
+	"jd_material_push/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ValidateLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewValidateLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ValidateLogic {
+	return &ValidateLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Validate 上传前预检文件夹和投放设置，不上传任何文件
+func (l *ValidateLogic) Validate(req *types.ValidateRequest) (resp *types.ValidateResponse, err error) {
+	err = applyPreset(l.svcCtx, req.Preset, presetFields{
+		mediaList:    &req.MediaList,
+		categoryList: &req.CategoryList,
+		releaseCopy:  &req.ReleaseCopy,
+		copyVariants: &req.CopyVariants,
+		copyVars:     &req.CopyVars,
+		columns:      &req.Columns,
+		profile:      &req.Profile,
+	})
+	var profile transform.Profile
+	if err == nil {
+		profile, err = transform.Lookup(l.svcCtx.Config.Profiles, req.Profile)
+	}
+	if err == nil {
+		_, err = complianceMode(l.svcCtx, req.Profile)
+	}
+	if err != nil {
+		return &types.ValidateResponse{
+			Code:    400,
+			Message: err.Error(),
+			Data:    []types.ValidateFinding{},
+		}, nil
+	}
+
+	values := applyattr.Values(req.MediaList, req.CategoryList, req.ReleaseCopy, req.Columns)
+	findings, err := preflight.Run(req.FolderPath, profile.Filter(), l.svcCtx.Config.Preflight, l.svcCtx.MediaSpecs, l.svcCtx.Catalog.Current().Schema(), values,
+		preflight.Compliance{Lexicon: l.svcCtx.Lexicon.Current(), Mode: profile.Compliance})
+	if err != nil {
+		return &types.ValidateResponse{
+			Code:    500,
+			Message: err.Error(),
+			Data:    []types.ValidateFinding{},
+		}, nil
+	}
+
+	findings = append(findings, l.checkCopyVars(req)...)
+
+	return toValidateResponse(findings), nil
+}
+
+// checkCopyVars 检查投放文案和文案变体中的模板变量是否都已填写；{stem}、{part:N} 提交时按文件名展开，不在此检查
+func (l *ValidateLogic) checkCopyVars(req *types.ValidateRequest) []preflight.Finding {
+	variants, _, err := resolveVariants(l.svcCtx, req.CopyVariants)
+	if err != nil {
+		return []preflight.Finding{{Rule: "copy", Severity: preflight.SeverityError, Message: err.Error()}}
+	}
+	texts := []string{req.ReleaseCopy}
+	for _, v := range variants {
+		texts = append(texts, v.Text)
+	}
+
+	var missing []string
+	seen := make(map[string]bool)
+	for _, text := range texts {
+		for _, name := range copylib.Variables(text) {
+			if name == "stem" || strings.HasPrefix(name, "part:") || strings.TrimSpace(req.CopyVars[name]) != "" || seen[name] {
+				continue
+			}
+			seen[name] = true
+			missing = append(missing, "{"+name+"}")
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	return []preflight.Finding{{
+		Rule:     "copy",
+		Severity: preflight.SeverityError,
+		Message:  fmt.Sprintf("文案变量未填写: %s", strings.Join(missing, "、")),
+	}}
+}
+
+func toValidateResponse(findings []preflight.Finding) *types.ValidateResponse {
+	errorCount, warningCount := preflight.Count(findings)
+	resp := &types.ValidateResponse{
+		Code:         200,
+		Message:      fmt.Sprintf("预检完成，错误: %d, 警告: %d", errorCount, warningCount),
+		Passed:       errorCount == 0,
+		ErrorCount:   errorCount,
+		WarningCount: warningCount,
+		Data:         make([]types.ValidateFinding, 0, len(findings)),
+	}
+	for _, f := range findings {
+		resp.Data = append(resp.Data, types.ValidateFinding{
+			File:     f.File,
+			Rule:     f.Rule,
+			Severity: f.Severity,
+			Message:  f.Message,
+		})
+	}
+	return resp
+}
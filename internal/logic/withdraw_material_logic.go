@@ -0,0 +1,212 @@
+package logic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"jd_material_push/internal/ledger"
+	"jd_material_push/internal/svc"
+	"jd_material_push/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type WithdrawMaterialLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewWithdrawMaterialLogic(ctx context.Context, svcCtx *svc.ServiceContext) *WithdrawMaterialLogic {
+	return &WithdrawMaterialLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// WithdrawMaterial 撤回（删除）已提交到素材中心的素材
+// Confirm 为 false 时只返回将要撤回的素材清单，不调用素材中心
+func (l *WithdrawMaterialLogic) WithdrawMaterial(req *types.WithdrawMaterialRequest) (resp *types.WithdrawMaterialResponse, err error) {
+	resp = &types.WithdrawMaterialResponse{
+		Code:    200,
+		Message: "success",
+		Data:    []types.WithdrawResult{},
+	}
+
+	// 撤回会删除素材中心的素材，方法名未经核实前不调用
+	if l.svcCtx.Config.WithdrawMethod == "" {
+		resp.Code = 400
+		resp.Message = "未配置撤回方法 WithdrawMethod：素材中心的撤回方法尚未核实，确认方法名和参数后在配置文件中设置"
+		return resp, nil
+	}
+
+	targets, err := l.resolveTargets(req)
+	if err != nil {
+		resp.Code = 400
+		resp.Message = err.Error()
+		return resp, nil
+	}
+	if len(targets) == 0 {
+		resp.Code = 400
+		resp.Message = "没有可撤回的素材"
+		return resp, nil
+	}
+
+	// 确认前只返回清单
+	if !req.Confirm {
+		for _, t := range targets {
+			resp.Data = append(resp.Data, types.WithdrawResult{
+				MaterialName: t.item.MaterialName,
+				URL:          t.item.URL,
+				Message:      "待撤回",
+			})
+		}
+		resp.Message = fmt.Sprintf("将撤回 %d 个素材，请确认", len(targets))
+		return resp, nil
+	}
+
+	resp.Confirmed = true
+	successCount := 0
+	for _, t := range targets {
+		result := l.withdrawSingle(t)
+		if result.Success {
+			successCount++
+		}
+		resp.Data = append(resp.Data, result)
+	}
+
+	resp.Message = fmt.Sprintf("撤回完成，成功: %d, 总数: %d", successCount, len(targets))
+	l.Info(resp.Message)
+	return resp, nil
+}
+
+// withdrawTarget 一个撤回目标及撤回结果要记录到的任务，jobID 为空时不记录
+type withdrawTarget struct {
+	jobID string
+	item  types.MaterialItem
+}
+
+// resolveTargets 根据任务 ID 和/或素材列表确定撤回目标；只指定素材列表时，
+// 撤回结果记录到最近一个提交了该素材的任务，同一 URL 在其他任务中的记录不变
+func (l *WithdrawMaterialLogic) resolveTargets(req *types.WithdrawMaterialRequest) ([]withdrawTarget, error) {
+	if req.JobID == "" {
+		if len(req.MaterialList) == 0 {
+			return nil, fmt.Errorf("请指定任务 ID 或素材列表")
+		}
+		jobs := l.svcCtx.Ledger.Query(ledger.Filter{})
+		targets := make([]withdrawTarget, 0, len(req.MaterialList))
+		for _, item := range req.MaterialList {
+			targets = append(targets, withdrawTarget{jobID: latestSubmitted(jobs, item.URL), item: item})
+		}
+		return targets, nil
+	}
+
+	job, ok := l.svcCtx.Ledger.Get(req.JobID)
+	if !ok {
+		return nil, fmt.Errorf("任务不存在: %s", req.JobID)
+	}
+
+	wanted := make(map[string]bool, len(req.MaterialList))
+	for _, item := range req.MaterialList {
+		wanted[item.URL] = true
+	}
+
+	var targets []withdrawTarget
+	for _, rec := range job.Materials {
+		if !withdrawable(rec) {
+			continue
+		}
+		if len(wanted) > 0 && !wanted[rec.URL] {
+			continue
+		}
+		// 按提交时的名称撤回，提交时未按模板重命名的使用原文件名
+		name := rec.FileName
+		if rec.MaterialName != "" {
+			name = rec.MaterialName
+		}
+		targets = append(targets, withdrawTarget{jobID: job.ID, item: types.MaterialItem{
+			MaterialName: name,
+			MaterialSize: rec.FileSize,
+			MaterialType: rec.MaterialType,
+			Width:        rec.Width,
+			Height:       rec.Height,
+			Duration:     rec.Duration,
+			Codec:        rec.Codec,
+			URL:          rec.URL,
+			LocalURL:     rec.LocalURL,
+		}})
+	}
+
+	return targets, nil
+}
+
+// withdrawable 只有提交成功（或上次撤回失败）的素材需要撤回
+func withdrawable(rec ledger.MaterialRecord) bool {
+	return rec.SubmitStatus == ledger.SubmitStatusSubmitted || rec.SubmitStatus == ledger.SubmitStatusWithdrawFailed
+}
+
+// latestSubmitted jobs 中（最新的在前）最近一个提交了该 URL 的任务 ID，没有时为空
+func latestSubmitted(jobs []ledger.Job, url string) string {
+	for _, job := range jobs {
+		if rec := job.Material(url); rec != nil && withdrawable(*rec) {
+			return job.ID
+		}
+	}
+	return ""
+}
+
+// withdrawSingle 撤回单个素材并记录到目标任务的台账
+// 撤回方法的参数和响应没有可核对的接口文档，只有响应明确确认删除了该素材
+// （result 为 true 且 totalNum 为 1）时才记为已撤回，其余情况记为撤回失败，可再次撤回
+func (l *WithdrawMaterialLogic) withdrawSingle(t withdrawTarget) types.WithdrawResult {
+	item := t.item
+	result := types.WithdrawResult{
+		MaterialName: item.MaterialName,
+		URL:          item.URL,
+	}
+
+	respBody, err := l.svcCtx.MaterialCenter.Call(l.ctx, l.svcCtx.Config.WithdrawMethod, map[string]interface{}{
+		"materialList": materialPayload([]types.MaterialItem{item}),
+	})
+	if err != nil {
+		result.Message = fmt.Sprintf("调用撤回接口失败: %v", err)
+	} else {
+		var withdrawResp types.SubmitMaterialResponse
+		if err := json.Unmarshal(respBody, &withdrawResp); err != nil {
+			result.Message = fmt.Sprintf("解析响应失败: %v, 响应内容: %s", err, string(respBody))
+		} else {
+			result.Success = withdrawResp.Code == 200 && withdrawResp.Result && withdrawResp.TotalNum == 1
+			result.Message = withdrawResp.Message
+			if withdrawResp.Code == 200 && withdrawResp.Result && !result.Success {
+				result.Message = fmt.Sprintf("素材中心未确认删除该素材（totalNum=%d），请在素材中心核实", withdrawResp.TotalNum)
+			}
+		}
+	}
+
+	if !result.Success {
+		l.Errorf("撤回失败 %s: %s", item.MaterialName, result.Message)
+	}
+
+	status := ledger.SubmitStatusWithdrawFailed
+	if result.Success {
+		status = ledger.SubmitStatusWithdrawn
+	}
+	if t.jobID == "" {
+		return result
+	}
+	err = l.svcCtx.Ledger.Update(t.jobID, func(job *ledger.Job) {
+		if rec := job.Material(item.URL); rec != nil {
+			rec.SubmitStatus = status
+			rec.Message = result.Message
+			rec.UpdatedAt = time.Now()
+		}
+	})
+	if err != nil {
+		l.Errorf("记录撤回结果到台账失败: %v", err)
+	}
+
+	return result
+}
@@ -0,0 +1,101 @@
+package materialcenter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"jd_material_push/internal/cookie"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+const (
+	// APIURL 素材中心网关地址
+	APIURL = "https://api.m.jd.com/?functionId=material_center_api&appid=materialCenter"
+	// SystemCode 京橙系统编码
+	SystemCode = "jdOrange"
+	// BusinessCode 业务线编码
+	BusinessCode = "伙伴计划--美数科技"
+)
+
+// Client 素材中心接口客户端
+type Client struct {
+	cookieMgr  *cookie.Manager
+	httpClient *http.Client
+}
+
+// NewClient 创建素材中心客户端
+func NewClient(cookieMgr *cookie.Manager) *Client {
+	return &Client{
+		cookieMgr:  cookieMgr,
+		httpClient: &http.Client{},
+	}
+}
+
+// Call 调用素材中心的指定方法，返回原始响应体
+// funName: 素材中心方法名（如 extAddMaterial）
+// param: 方法参数，systemCode/businessCode 未设置时自动补齐
+func (c *Client) Call(ctx context.Context, funName string, param map[string]interface{}) ([]byte, error) {
+	if _, ok := param["systemCode"]; !ok {
+		param["systemCode"] = SystemCode
+	}
+	if _, ok := param["businessCode"]; !ok {
+		param["businessCode"] = BusinessCode
+	}
+
+	body := map[string]interface{}{
+		"funName":   funName,
+		"param":     param,
+		"loginType": "3",
+	}
+	bodyJSON, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("序列化请求失败: %w", err)
+	}
+
+	// 获取 Cookie
+	cookie, err := c.cookieMgr.GetCookie()
+	if err != nil {
+		return nil, fmt.Errorf("获取 Cookie 失败: %w", err)
+	}
+
+	// 构建表单数据
+	formData := url.Values{}
+	formData.Set("appid", "materialCenter")
+	formData.Set("functionId", "material_center_api")
+	formData.Set("_", "1770018106")
+	formData.Set("loginType", "3")
+	formData.Set("body", string(bodyJSON))
+
+	// 创建请求
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, APIURL, bytes.NewBufferString(formData.Encode()))
+	if err != nil {
+		return nil, err
+	}
+
+	// 设置请求头
+	httpReq.Header.Set("Cookie", cookie)
+	httpReq.Header.Set("Origin", "https://jcheng.jd.com")
+	httpReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	// 发送请求
+	httpResp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	// 读取响应
+	respBody, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	logx.WithContext(ctx).Infof("素材中心 %s 响应: %s", funName, string(respBody))
+	return respBody, nil
+}
@@ -0,0 +1,243 @@
+package media
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// 素材中心 materialType
+const (
+	MaterialTypeImage = 1 // 图片
+	MaterialTypeVideo = 2 // 视频
+)
+
+// 识别出的具体格式
+const (
+	KindJPEG = "jpeg"
+	KindPNG  = "png"
+	KindGIF  = "gif"
+	KindWebP = "webp"
+	KindMP4  = "mp4"
+	KindMOV  = "mov"
+	KindM4V  = "m4v"
+	KindWebM = "webm"
+	KindAVI  = "avi"
+)
+
+// ErrUnsupported 文件内容不是支持的图片或视频
+var ErrUnsupported = errors.New("不支持的文件类型")
+
+// kindExts 各格式对应的常见扩展名
+var kindExts = map[string][]string{
+	KindJPEG: {".jpg", ".jpeg"},
+	KindPNG:  {".png"},
+	KindGIF:  {".gif"},
+	KindWebP: {".webp"},
+	KindMP4:  {".mp4"},
+	KindMOV:  {".mov"},
+	KindM4V:  {".m4v", ".mp4"},
+	KindWebM: {".webm"},
+	KindAVI:  {".avi"},
+}
+
+// TypeInfo 文件类型识别结果
+type TypeInfo struct {
+	Kind         string // 具体格式
+	MimeType     string // MIME 类型
+	MaterialType int    // 素材中心 materialType
+	Animated     bool   // 是否为多帧 GIF，仍按图片素材提交，但不做重新编码
+}
+
+// ExtMatches 扩展名（不区分大小写）是否与识别出的格式相符
+func (t TypeInfo) ExtMatches(fileName string) bool {
+	ext := strings.ToLower(filepath.Ext(fileName))
+	for _, e := range kindExts[t.Kind] {
+		if e == ext {
+			return true
+		}
+	}
+	return false
+}
+
+// DetectFile 按文件内容识别素材类型
+func DetectFile(path string) (TypeInfo, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return TypeInfo{}, err
+	}
+	defer f.Close()
+	return Detect(f)
+}
+
+// SniffSize Sniff 需要的文件头长度
+const SniffSize = 512
+
+// Detect 按内容识别素材类型，只认魔数和容器结构，不看扩展名
+func Detect(r io.Reader) (TypeInfo, error) {
+	br := bufio.NewReaderSize(r, 4096)
+	head, err := br.Peek(SniffSize)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return TypeInfo{}, fmt.Errorf("读取文件头失败: %w", err)
+	}
+	info, err := Sniff(head)
+	if err != nil || info.Kind != KindGIF {
+		return info, err
+	}
+
+	frames, err := countGIFFrames(br, 2)
+	if err != nil {
+		return TypeInfo{}, fmt.Errorf("解析 GIF 失败: %w", err)
+	}
+	info.Animated = frames > 1
+	return info, nil
+}
+
+// Sniff 只按文件头（前 SniffSize 字节）识别素材类型，不统计 GIF 帧数，Animated 始终为 false；
+// 用于读完整个文件之前就要确定类型的场景
+func Sniff(head []byte) (TypeInfo, error) {
+	if len(head) == 0 {
+		return TypeInfo{}, fmt.Errorf("%w: 空文件", ErrUnsupported)
+	}
+
+	// ISO BMFF（mp4/mov/m4v）需要看 ftyp 品牌，DetectContentType 无法区分
+	if len(head) >= 12 && string(head[4:8]) == "ftyp" {
+		return detectFtyp(head)
+	}
+
+	mimeType := http.DetectContentType(head)
+	switch mimeType {
+	case "image/jpeg":
+		return imageType(KindJPEG, mimeType), nil
+	case "image/png":
+		return imageType(KindPNG, mimeType), nil
+	case "image/webp":
+		return imageType(KindWebP, mimeType), nil
+	case "image/gif":
+		return imageType(KindGIF, mimeType), nil
+	case "video/webm":
+		return videoType(KindWebM, mimeType), nil
+	case "video/avi":
+		return videoType(KindAVI, "video/x-msvideo"), nil
+	}
+
+	return TypeInfo{}, fmt.Errorf("%w: %s", ErrUnsupported, mimeType)
+}
+
+// detectFtyp 根据 ftyp 主品牌和兼容品牌区分 mp4/mov/m4v，排除 HEIC 图片和纯音频
+func detectFtyp(head []byte) (TypeInfo, error) {
+	size := int(binary.BigEndian.Uint32(head[0:4]))
+	if size < 16 || size > len(head) {
+		size = len(head)
+	}
+	major := string(head[8:12])
+	brands := []string{major}
+	for i := 16; i+4 <= size; i += 4 {
+		brands = append(brands, string(head[i:i+4]))
+	}
+
+	for _, b := range brands {
+		switch b {
+		case "heic", "heix", "mif1", "msf1", "avif":
+			return TypeInfo{}, fmt.Errorf("%w: HEIF/AVIF 图片", ErrUnsupported)
+		}
+	}
+
+	switch {
+	case major == "qt  ":
+		return videoType(KindMOV, "video/quicktime"), nil
+	case strings.HasPrefix(major, "M4V"):
+		return videoType(KindM4V, "video/x-m4v"), nil
+	case major == "M4A " || major == "M4B " || major == "M4P ":
+		return TypeInfo{}, fmt.Errorf("%w: 音频文件", ErrUnsupported)
+	}
+	return videoType(KindMP4, "video/mp4"), nil
+}
+
+// countGIFFrames 统计 GIF 图像帧数，数到 limit 即停止
+func countGIFFrames(r *bufio.Reader, limit int) (int, error) {
+	header := make([]byte, 13)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return 0, err
+	}
+	// 全局颜色表
+	if header[10]&0x80 != 0 {
+		if _, err := r.Discard(3 * (1 << (int(header[10]&0x07) + 1))); err != nil {
+			return 0, err
+		}
+	}
+
+	frames := 0
+	for frames < limit {
+		introducer, err := r.ReadByte()
+		if err != nil {
+			return frames, err
+		}
+		switch introducer {
+		case 0x21: // 扩展块
+			if _, err := r.ReadByte(); err != nil {
+				return frames, err
+			}
+			if err := skipSubBlocks(r); err != nil {
+				return frames, err
+			}
+		case 0x2C: // 图像描述符
+			frames++
+			desc := make([]byte, 9)
+			if _, err := io.ReadFull(r, desc); err != nil {
+				return frames, err
+			}
+			if desc[8]&0x80 != 0 {
+				if _, err := r.Discard(3 * (1 << (int(desc[8]&0x07) + 1))); err != nil {
+					return frames, err
+				}
+			}
+			// LZW 最小码长
+			if _, err := r.ReadByte(); err != nil {
+				return frames, err
+			}
+			if err := skipSubBlocks(r); err != nil {
+				return frames, err
+			}
+		case 0x3B: // 结束符
+			return frames, nil
+		default:
+			return frames, fmt.Errorf("未知的块类型 0x%02x", introducer)
+		}
+	}
+	return frames, nil
+}
+
+func skipSubBlocks(r *bufio.Reader) error {
+	for {
+		n, err := r.ReadByte()
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return nil
+		}
+		if _, err := r.Discard(int(n)); err != nil {
+			return err
+		}
+	}
+}
+
+func imageType(kind, mimeType string) TypeInfo {
+	return TypeInfo{Kind: kind, MimeType: mimeType, MaterialType: MaterialTypeImage}
+}
+
+func videoType(kind, mimeType string) TypeInfo {
+	return TypeInfo{Kind: kind, MimeType: mimeType, MaterialType: MaterialTypeVideo}
+}
+
+// IsUnsupported 错误是否表示文件类型不受支持
+func IsUnsupported(err error) bool {
+	return errors.Is(err, ErrUnsupported)
+}
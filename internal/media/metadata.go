@@ -0,0 +1,394 @@
+package media
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"os"
+
+	_ "golang.org/x/image/webp"
+)
+
+// Metadata 素材的尺寸、时长等元数据
+type Metadata struct {
+	Width    int     // 宽（像素）
+	Height   int     // 高（像素）
+	Duration float64 // 时长（秒），图片为 0
+	Codec    string  // 视频编码（如 avc1、hvc1），图片为格式名
+}
+
+// AspectRatio 宽高比，尺寸未知时返回 0
+func (m Metadata) AspectRatio() float64 {
+	if m.Width == 0 || m.Height == 0 {
+		return 0
+	}
+	return float64(m.Width) / float64(m.Height)
+}
+
+// Probe 识别文件类型并读取元数据
+func Probe(path string) (TypeInfo, Metadata, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return TypeInfo{}, Metadata{}, err
+	}
+	defer f.Close()
+	return ProbeReader(f)
+}
+
+// ProbeReader 从可随机读取的内容识别文件类型并读取元数据；类型识别失败时 TypeInfo 为空
+func ProbeReader(r io.ReadSeeker) (TypeInfo, Metadata, error) {
+	typeInfo, err := Detect(r)
+	if err != nil {
+		return TypeInfo{}, Metadata{}, err
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return typeInfo, Metadata{}, err
+	}
+
+	meta, err := ReadMetadata(r, typeInfo)
+	return typeInfo, meta, err
+}
+
+// probeHeadSize 顺序读取时用于识别类型和读取图片尺寸的文件头长度
+const probeHeadSize = 1 << 20
+
+// maxMoovSize 顺序读取 MP4 时读入内存的 moov 盒子上限
+const maxMoovSize = 64 << 20
+
+// ProbeStream 从只能顺序读取的内容（如压缩包中的文件）识别文件类型并读取元数据，不把整个文件读入内存：
+// 图片只看前 1MB 的文件头，MP4/MOV 顺序跳过 mdat 等盒子，只读入 moov；类型识别失败时 TypeInfo 为空
+func ProbeStream(r io.Reader) (TypeInfo, Metadata, error) {
+	br := bufio.NewReaderSize(r, probeHeadSize)
+	peeked, err := br.Peek(probeHeadSize)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return TypeInfo{}, Metadata{}, fmt.Errorf("读取文件头失败: %w", err)
+	}
+	// GIF 统计帧数时会继续读取，先留一份文件头
+	head := bytes.NewReader(append([]byte(nil), peeked...))
+
+	typeInfo, err := Detect(br)
+	if err != nil {
+		return TypeInfo{}, Metadata{}, err
+	}
+	if typeInfo.Kind == KindMP4 || typeInfo.Kind == KindMOV || typeInfo.Kind == KindM4V {
+		// ftyp 只经 Peek 识别，br 仍位于文件开头
+		meta, err := readMP4Stream(br)
+		return typeInfo, meta, err
+	}
+	meta, err := ReadMetadata(head, typeInfo)
+	return typeInfo, meta, err
+}
+
+// ReadMetadata 按已识别的类型读取元数据；图片只读文件头，MP4/MOV 解析 moov 盒子
+// 暂不支持解析的容器（webm、avi）返回空元数据且不报错
+func ReadMetadata(r io.ReadSeeker, typeInfo TypeInfo) (Metadata, error) {
+	switch typeInfo.Kind {
+	case KindJPEG, KindPNG, KindGIF, KindWebP:
+		cfg, format, err := image.DecodeConfig(r)
+		if err != nil {
+			return Metadata{}, fmt.Errorf("读取图片尺寸失败: %w", err)
+		}
+		return Metadata{Width: cfg.Width, Height: cfg.Height, Codec: format}, nil
+	case KindMP4, KindMOV, KindM4V:
+		return readMP4(r)
+	}
+	return Metadata{}, nil
+}
+
+// box ISO BMFF 盒子
+type box struct {
+	typ       string
+	offset    int64 // 盒子起始位置
+	headerLen int64
+	size      int64 // 含头部的总长度
+}
+
+func (b box) bodyOffset() int64 { return b.offset + b.headerLen }
+func (b box) bodySize() int64   { return b.size - b.headerLen }
+
+// readBoxes 读取 [start, end) 范围内的同级盒子
+func readBoxes(r io.ReadSeeker, start, end int64) ([]box, error) {
+	var boxes []box
+	header := make([]byte, 16)
+	for offset := start; offset+8 <= end; {
+		if _, err := r.Seek(offset, io.SeekStart); err != nil {
+			return nil, err
+		}
+		if _, err := io.ReadFull(r, header[:8]); err != nil {
+			return nil, err
+		}
+
+		b := box{
+			typ:       string(header[4:8]),
+			offset:    offset,
+			headerLen: 8,
+			size:      int64(binary.BigEndian.Uint32(header[0:4])),
+		}
+		switch b.size {
+		case 0: // 延伸到容器末尾
+			b.size = end - offset
+		case 1: // 64 位长度
+			if _, err := io.ReadFull(r, header[8:16]); err != nil {
+				return nil, err
+			}
+			b.size = int64(binary.BigEndian.Uint64(header[8:16]))
+			b.headerLen = 16
+		}
+		if b.size < b.headerLen || offset+b.size > end {
+			return nil, fmt.Errorf("盒子 %q 长度异常", b.typ)
+		}
+
+		boxes = append(boxes, b)
+		offset += b.size
+	}
+	return boxes, nil
+}
+
+func findBox(boxes []box, typ string) (box, bool) {
+	for _, b := range boxes {
+		if b.typ == typ {
+			return b, true
+		}
+	}
+	return box{}, false
+}
+
+func readBody(r io.ReadSeeker, b box, limit int64) ([]byte, error) {
+	n := b.bodySize()
+	if n > limit {
+		n = limit
+	}
+	if _, err := r.Seek(b.bodyOffset(), io.SeekStart); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, n)
+	_, err := io.ReadFull(r, buf)
+	return buf, err
+}
+
+var errNoMoov = errors.New("未找到 moov 盒子")
+
+// readMP4 找到 moov 盒子后读取时长、分辨率与编码
+func readMP4(r io.ReadSeeker) (Metadata, error) {
+	end, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return Metadata{}, err
+	}
+
+	top, err := readBoxes(r, 0, end)
+	if err != nil {
+		return Metadata{}, fmt.Errorf("解析视频容器失败: %w", err)
+	}
+	moov, ok := findBox(top, "moov")
+	if !ok {
+		return Metadata{}, errNoMoov
+	}
+	return readMoov(r, moov)
+}
+
+// readMoov 从 moov/mvhd 读取时长，从第一个视频轨读取分辨率与编码
+func readMoov(r io.ReadSeeker, moov box) (Metadata, error) {
+	children, err := readBoxes(r, moov.bodyOffset(), moov.offset+moov.size)
+	if err != nil {
+		return Metadata{}, fmt.Errorf("解析 moov 失败: %w", err)
+	}
+
+	var meta Metadata
+	if mvhd, ok := findBox(children, "mvhd"); ok {
+		body, err := readBody(r, mvhd, 32)
+		if err != nil {
+			return Metadata{}, fmt.Errorf("读取 mvhd 失败: %w", err)
+		}
+		meta.Duration = mvhdDuration(body)
+	}
+
+	for _, trak := range children {
+		if trak.typ != "trak" {
+			continue
+		}
+		width, height, codec, isVideo, err := readVideoTrack(r, trak)
+		if err != nil {
+			return meta, err
+		}
+		if isVideo {
+			meta.Width, meta.Height, meta.Codec = width, height, codec
+			break
+		}
+	}
+
+	return meta, nil
+}
+
+// readMP4Stream 顺序遍历顶层盒子，跳过 mdat 等盒子，把 moov 读入内存后解析
+func readMP4Stream(r io.Reader) (Metadata, error) {
+	header := make([]byte, 16)
+	for {
+		if _, err := io.ReadFull(r, header[:8]); err != nil {
+			if err == io.EOF {
+				return Metadata{}, errNoMoov
+			}
+			return Metadata{}, fmt.Errorf("解析视频容器失败: %w", err)
+		}
+		b := box{
+			typ:       string(header[4:8]),
+			headerLen: 8,
+			size:      int64(binary.BigEndian.Uint32(header[0:4])),
+		}
+		switch b.size {
+		case 0: // 延伸到文件末尾，之后不会再有 moov
+			if b.typ != "moov" {
+				return Metadata{}, errNoMoov
+			}
+			b.size = b.headerLen + maxMoovSize + 1
+		case 1: // 64 位长度
+			if _, err := io.ReadFull(r, header[8:16]); err != nil {
+				return Metadata{}, fmt.Errorf("解析视频容器失败: %w", err)
+			}
+			b.size = int64(binary.BigEndian.Uint64(header[8:16]))
+			b.headerLen = 16
+		}
+		if b.size < b.headerLen {
+			return Metadata{}, fmt.Errorf("解析视频容器失败: 盒子 %q 长度异常", b.typ)
+		}
+
+		if b.typ != "moov" {
+			if _, err := io.CopyN(io.Discard, r, b.size-b.headerLen); err != nil {
+				return Metadata{}, fmt.Errorf("解析视频容器失败: %w", err)
+			}
+			continue
+		}
+
+		// moov 连同头部读入内存，按偏移 0 解析
+		data := append([]byte(nil), header[:b.headerLen]...)
+		body, err := io.ReadAll(io.LimitReader(r, min(b.size-b.headerLen, maxMoovSize+1)))
+		if err != nil {
+			return Metadata{}, fmt.Errorf("读取 moov 失败: %w", err)
+		}
+		if int64(len(body)) > maxMoovSize {
+			return Metadata{}, fmt.Errorf("moov 盒子超过 %d MB", maxMoovSize>>20)
+		}
+		data = append(data, body...)
+		b.size = int64(len(data))
+		return readMoov(bytes.NewReader(data), b)
+	}
+}
+
+// mvhdDuration 解析 mvhd 中的 timescale 与 duration（兼容 version 0/1）
+func mvhdDuration(body []byte) float64 {
+	if len(body) < 20 {
+		return 0
+	}
+	var timescale uint32
+	var duration uint64
+	if body[0] == 1 {
+		if len(body) < 32 {
+			return 0
+		}
+		timescale = binary.BigEndian.Uint32(body[20:24])
+		duration = binary.BigEndian.Uint64(body[24:32])
+	} else {
+		timescale = binary.BigEndian.Uint32(body[12:16])
+		duration = uint64(binary.BigEndian.Uint32(body[16:20]))
+	}
+	if timescale == 0 {
+		return 0
+	}
+	return float64(duration) / float64(timescale)
+}
+
+// readVideoTrack 读取一个 trak，非视频轨返回 isVideo=false
+func readVideoTrack(r io.ReadSeeker, trak box) (width, height int, codec string, isVideo bool, err error) {
+	children, err := readBoxes(r, trak.bodyOffset(), trak.offset+trak.size)
+	if err != nil {
+		return 0, 0, "", false, fmt.Errorf("解析 trak 失败: %w", err)
+	}
+
+	mdia, ok := findBox(children, "mdia")
+	if !ok {
+		return 0, 0, "", false, nil
+	}
+	mdiaChildren, err := readBoxes(r, mdia.bodyOffset(), mdia.offset+mdia.size)
+	if err != nil {
+		return 0, 0, "", false, fmt.Errorf("解析 mdia 失败: %w", err)
+	}
+
+	hdlr, ok := findBox(mdiaChildren, "hdlr")
+	if !ok {
+		return 0, 0, "", false, nil
+	}
+	body, err := readBody(r, hdlr, 12)
+	if err != nil || len(body) < 12 || string(body[8:12]) != "vide" {
+		return 0, 0, "", false, nil
+	}
+
+	if tkhd, ok := findBox(children, "tkhd"); ok {
+		body, err := readBody(r, tkhd, tkhd.bodySize())
+		if err == nil {
+			width, height = tkhdSize(body)
+		}
+	}
+
+	// mdia/minf/stbl/stsd 的第一个采样描述即编码格式
+	if minf, ok := findBox(mdiaChildren, "minf"); ok {
+		codec = readCodec(r, minf)
+	}
+
+	return width, height, codec, true, nil
+}
+
+// tkhdSize 解析 tkhd 的显示宽高：末尾 8 字节为 16.16 定点数的宽高，其前 36 字节为变换矩阵，
+// 旋转 90° 或 270°（手机竖拍常见）时交换宽高
+func tkhdSize(body []byte) (width, height int) {
+	n := len(body)
+	if n < 8 {
+		return 0, 0
+	}
+	width = int(binary.BigEndian.Uint32(body[n-8:n-4]) >> 16)
+	height = int(binary.BigEndian.Uint32(body[n-4:n]) >> 16)
+
+	if n < 44 {
+		return width, height
+	}
+	// 矩阵 {a, b, u, c, d, v, x, y, w}，a、b、c、d 为 16.16 定点数
+	matrix := body[n-44 : n-8]
+	a := int32(binary.BigEndian.Uint32(matrix[0:4]))
+	b := int32(binary.BigEndian.Uint32(matrix[4:8]))
+	c := int32(binary.BigEndian.Uint32(matrix[12:16]))
+	d := int32(binary.BigEndian.Uint32(matrix[16:20]))
+	if a == 0 && d == 0 && b != 0 && c != 0 {
+		width, height = height, width
+	}
+	return width, height
+}
+
+func readCodec(r io.ReadSeeker, minf box) string {
+	minfChildren, err := readBoxes(r, minf.bodyOffset(), minf.offset+minf.size)
+	if err != nil {
+		return ""
+	}
+	stbl, ok := findBox(minfChildren, "stbl")
+	if !ok {
+		return ""
+	}
+	stblChildren, err := readBoxes(r, stbl.bodyOffset(), stbl.offset+stbl.size)
+	if err != nil {
+		return ""
+	}
+	stsd, ok := findBox(stblChildren, "stsd")
+	if !ok {
+		return ""
+	}
+	// version/flags(4) + entry_count(4) + 第一个条目 size(4) + format(4)
+	body, err := readBody(r, stsd, 16)
+	if err != nil || len(body) < 16 {
+		return ""
+	}
+	return string(body[12:16])
+}
@@ -0,0 +1,153 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/gif"
+	"testing"
+)
+
+// mp4Box 拼出一个 ISO BMFF 盒子
+func mp4Box(typ string, parts ...[]byte) []byte {
+	body := bytes.Join(parts, nil)
+	b := make([]byte, 8, 8+len(body))
+	binary.BigEndian.PutUint32(b[0:4], uint32(8+len(body)))
+	copy(b[4:8], typ)
+	return append(b, body...)
+}
+
+func u32(vs ...uint32) []byte {
+	b := make([]byte, 4*len(vs))
+	for i, v := range vs {
+		binary.BigEndian.PutUint32(b[4*i:], v)
+	}
+	return b
+}
+
+// 16.16 定点数
+const (
+	fixedOne      = 0x00010000
+	fixedMinusOne = 0xFFFF0000
+)
+
+// tkhdV0 version 0 的 tkhd：头部 24 字节、保留/层/音量 16 字节、矩阵 36 字节、宽高 8 字节
+func tkhdV0(matrix [9]uint32, width, height uint32) []byte {
+	return mp4Box("tkhd", make([]byte, 24), make([]byte, 16), u32(matrix[:]...), u32(width<<16, height<<16))
+}
+
+// testMP4 一个视频轨和一个音频轨的最小 MP4，时长 12.5 秒
+func testMP4(matrix [9]uint32, width, height uint32) []byte {
+	videoTrak := mp4Box("trak",
+		tkhdV0(matrix, width, height),
+		mp4Box("mdia",
+			mp4Box("hdlr", make([]byte, 8), []byte("vide"), make([]byte, 12)),
+			mp4Box("minf", mp4Box("stbl", mp4Box("stsd", u32(0, 1, 16), []byte("avc1"))))))
+	audioTrak := mp4Box("trak",
+		tkhdV0(identity, 0, 0),
+		mp4Box("mdia", mp4Box("hdlr", make([]byte, 8), []byte("soun"), make([]byte, 12))))
+	mvhd := mp4Box("mvhd", u32(0, 0, 0, 1000, 12500), make([]byte, 80))
+	return bytes.Join([][]byte{
+		mp4Box("ftyp", []byte("isom"), u32(0x200), []byte("isommp41")),
+		mp4Box("free"),
+		mp4Box("moov", mvhd, audioTrak, videoTrak),
+	}, nil)
+}
+
+var identity = [9]uint32{fixedOne, 0, 0, 0, fixedOne, 0, 0, 0, 0x40000000}
+
+func TestReadMP4(t *testing.T) {
+	tests := []struct {
+		name          string
+		matrix        [9]uint32
+		width, height int
+	}{
+		{"不旋转", identity, 1920, 1080},
+		{"旋转 90°", [9]uint32{0, fixedOne, 0, fixedMinusOne, 0, 0, 0, 0, 0x40000000}, 1080, 1920},
+		{"旋转 270°", [9]uint32{0, fixedMinusOne, 0, fixedOne, 0, 0, 0, 0, 0x40000000}, 1080, 1920},
+		{"旋转 180°", [9]uint32{fixedMinusOne, 0, 0, 0, fixedMinusOne, 0, 0, 0, 0x40000000}, 1920, 1080},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			typeInfo, meta, err := ProbeReader(bytes.NewReader(testMP4(tt.matrix, 1920, 1080)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if typeInfo.Kind != KindMP4 || typeInfo.MaterialType != MaterialTypeVideo {
+				t.Errorf("类型 = %+v，应为 mp4 视频", typeInfo)
+			}
+			if meta.Width != tt.width || meta.Height != tt.height {
+				t.Errorf("尺寸 = %d×%d，应为 %d×%d", meta.Width, meta.Height, tt.width, tt.height)
+			}
+			if meta.Duration != 12.5 {
+				t.Errorf("时长 = %v，应为 12.5", meta.Duration)
+			}
+			if meta.Codec != "avc1" {
+				t.Errorf("编码 = %q，应为 avc1", meta.Codec)
+			}
+		})
+	}
+}
+
+func TestReadMP4Malformed(t *testing.T) {
+	ftyp := mp4Box("ftyp", []byte("isom"), u32(0x200), []byte("isom"))
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"缺少 moov", bytes.Join([][]byte{ftyp, mp4Box("mdat", make([]byte, 32))}, nil)},
+		{"盒子长度超出文件", bytes.Join([][]byte{ftyp, u32(1000), []byte("moov")}, nil)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := ProbeReader(bytes.NewReader(tt.data)); err == nil {
+				t.Error("应返回错误")
+			}
+		})
+	}
+}
+
+func testGIF(t *testing.T, frames int) []byte {
+	t.Helper()
+	palette := color.Palette{color.Black, color.White}
+	anim := &gif.GIF{}
+	for i := 0; i < frames; i++ {
+		anim.Image = append(anim.Image, image.NewPaletted(image.Rect(0, 0, 40, 30), palette))
+		anim.Delay = append(anim.Delay, 10)
+	}
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, anim); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestDetectGIF(t *testing.T) {
+	tests := []struct {
+		name     string
+		frames   int
+		animated bool
+	}{
+		{"单帧", 1, false},
+		{"两帧", 2, true},
+		{"多帧", 5, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			typeInfo, meta, err := ProbeReader(bytes.NewReader(testGIF(t, tt.frames)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if typeInfo.Kind != KindGIF || typeInfo.MaterialType != MaterialTypeImage {
+				t.Errorf("类型 = %+v，应为 gif 图片", typeInfo)
+			}
+			if typeInfo.Animated != tt.animated {
+				t.Errorf("Animated = %v，应为 %v", typeInfo.Animated, tt.animated)
+			}
+			if meta.Width != 40 || meta.Height != 30 {
+				t.Errorf("尺寸 = %d×%d，应为 40×30", meta.Width, meta.Height)
+			}
+		})
+	}
+}
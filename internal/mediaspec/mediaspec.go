@@ -0,0 +1,216 @@
+package mediaspec
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"jd_material_push/internal/media"
+
+	"github.com/zeromicro/go-zero/core/conf"
+)
+
+// 不符合规格时的处理方式
+const (
+	SeverityError   = "error"   // 阻止推送
+	SeverityWarning = "warning" // 提示但允许推送
+)
+
+// Spec 一条素材规格，未配置（为 0 或空）的项不检查
+type Spec struct {
+	MaterialType   int      `json:",optional"`                            // 适用的素材类型：1 图片，2 视频，0 全部
+	AspectRatios   []string `json:",optional"`                            // 允许的宽高比，如 9:16
+	RatioTolerance float64  `json:",default=0.02"`                        // 宽高比允许的相对误差
+	MinShortEdge   int      `json:",optional"`                            // 短边最小像素
+	MaxLongEdge    int      `json:",optional"`                            // 长边最大像素
+	MinDuration    float64  `json:",optional"`                            // 最短时长（秒）
+	MaxDuration    float64  `json:",optional"`                            // 最长时长（秒）
+	MaxSizeMB      float64  `json:",optional"`                            // 文件大小上限
+	Codecs         []string `json:",optional"`                            // 允许的视频编码，如 avc1
+	Severity       string   `json:",default=error,options=error|warning"` // 不符合时阻止推送还是仅提示
+}
+
+// MediaSpecs 一个投放媒体的全部规格
+type MediaSpecs struct {
+	Media string // 投放媒体取值，与目录中的 value 一致
+	Label string `json:",optional"` // 显示名称
+	Specs []Spec
+}
+
+// File 待检查的素材
+type File struct {
+	Name         string
+	Size         int64
+	MaterialType int
+	Meta         media.Metadata
+}
+
+// Violation 一条不符合规格的结果
+type Violation struct {
+	Media    string // 投放媒体显示名称
+	Rule     string // 规则标识
+	Severity string
+	Message  string
+}
+
+// Set 按投放媒体索引的规格集合
+type Set struct {
+	byMedia map[string]MediaSpecs
+}
+
+// Load 从 YAML 文件加载规格，文件不存在时返回空集合（不做检查）
+func Load(path string) (*Set, error) {
+	var c struct {
+		Media []MediaSpecs `json:",optional"`
+	}
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return NewSet(nil)
+	}
+	if err := conf.Load(path, &c); err != nil {
+		return nil, fmt.Errorf("加载媒体规格文件失败: %w", err)
+	}
+	return NewSet(c.Media)
+}
+
+// NewSet 创建规格集合，并校验宽高比格式
+func NewSet(list []MediaSpecs) (*Set, error) {
+	s := &Set{byMedia: make(map[string]MediaSpecs, len(list))}
+	for _, m := range list {
+		for _, spec := range m.Specs {
+			for _, ratio := range spec.AspectRatios {
+				if _, err := parseRatio(ratio); err != nil {
+					return nil, fmt.Errorf("投放媒体 %s: %w", m.Media, err)
+				}
+			}
+		}
+		s.byMedia[m.Media] = m
+	}
+	return s, nil
+}
+
+// Check 按所选的每个投放媒体检查素材，返回全部不符合项
+func (s *Set) Check(mediaList []string, f File) []Violation {
+	if s == nil {
+		return nil
+	}
+
+	var violations []Violation
+	for _, value := range mediaList {
+		m, ok := s.byMedia[value]
+		if !ok {
+			continue
+		}
+		label := m.Label
+		if label == "" {
+			label = m.Media
+		}
+		for _, spec := range m.Specs {
+			if spec.MaterialType != 0 && spec.MaterialType != f.MaterialType {
+				continue
+			}
+			for _, v := range checkSpec(spec, f) {
+				v.Media = label
+				v.Severity = spec.Severity
+				violations = append(violations, v)
+			}
+		}
+	}
+	return violations
+}
+
+// checkSpec 检查单条规格；元数据未知（如无法解析的容器）的项跳过
+func checkSpec(spec Spec, f File) []Violation {
+	var violations []Violation
+	meta := f.Meta
+
+	if len(spec.AspectRatios) > 0 && meta.AspectRatio() > 0 && !ratioAllowed(spec, meta.AspectRatio()) {
+		violations = append(violations, Violation{
+			Rule:    "aspect-ratio",
+			Message: fmt.Sprintf("宽高比 %d:%d 不在允许范围 %s 内", meta.Width, meta.Height, strings.Join(spec.AspectRatios, "、")),
+		})
+	}
+
+	short, long := meta.Width, meta.Height
+	if short > long {
+		short, long = long, short
+	}
+	if spec.MinShortEdge > 0 && short > 0 && short < spec.MinShortEdge {
+		violations = append(violations, Violation{
+			Rule:    "resolution",
+			Message: fmt.Sprintf("分辨率 %d×%d 过低，短边至少 %d 像素", meta.Width, meta.Height, spec.MinShortEdge),
+		})
+	}
+	if spec.MaxLongEdge > 0 && long > spec.MaxLongEdge {
+		violations = append(violations, Violation{
+			Rule:    "resolution",
+			Message: fmt.Sprintf("分辨率 %d×%d 过高，长边最多 %d 像素", meta.Width, meta.Height, spec.MaxLongEdge),
+		})
+	}
+
+	if meta.Duration > 0 {
+		if spec.MinDuration > 0 && meta.Duration < spec.MinDuration {
+			violations = append(violations, Violation{
+				Rule:    "duration",
+				Message: fmt.Sprintf("时长 %.1f 秒短于 %g 秒", meta.Duration, spec.MinDuration),
+			})
+		}
+		if spec.MaxDuration > 0 && meta.Duration > spec.MaxDuration {
+			violations = append(violations, Violation{
+				Rule:    "duration",
+				Message: fmt.Sprintf("时长 %.1f 秒超过 %g 秒", meta.Duration, spec.MaxDuration),
+			})
+		}
+	}
+
+	if spec.MaxSizeMB > 0 && float64(f.Size) > spec.MaxSizeMB*1024*1024 {
+		violations = append(violations, Violation{
+			Rule:    "size",
+			Message: fmt.Sprintf("文件大小 %.2f MB 超过 %g MB", float64(f.Size)/1024/1024, spec.MaxSizeMB),
+		})
+	}
+
+	if len(spec.Codecs) > 0 && f.MaterialType == media.MaterialTypeVideo && meta.Codec != "" && !contains(spec.Codecs, meta.Codec) {
+		violations = append(violations, Violation{
+			Rule:    "codec",
+			Message: fmt.Sprintf("视频编码 %s 不在允许范围 %s 内", meta.Codec, strings.Join(spec.Codecs, "、")),
+		})
+	}
+
+	return violations
+}
+
+func ratioAllowed(spec Spec, actual float64) bool {
+	for _, r := range spec.AspectRatios {
+		want, _ := parseRatio(r)
+		if math.Abs(actual-want)/want <= spec.RatioTolerance {
+			return true
+		}
+	}
+	return false
+}
+
+// parseRatio 解析 "9:16" 形式的宽高比
+func parseRatio(s string) (float64, error) {
+	w, h, ok := strings.Cut(strings.TrimSpace(s), ":")
+	if !ok {
+		return 0, fmt.Errorf("宽高比格式错误: %q", s)
+	}
+	width, err1 := strconv.ParseFloat(strings.TrimSpace(w), 64)
+	height, err2 := strconv.ParseFloat(strings.TrimSpace(h), 64)
+	if err1 != nil || err2 != nil || width <= 0 || height <= 0 {
+		return 0, fmt.Errorf("宽高比格式错误: %q", s)
+	}
+	return width / height, nil
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
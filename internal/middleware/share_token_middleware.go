@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net"
+	"net/http"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+// ShareTokenHeader 局域网内其他电脑调用接口时携带共享口令的请求头
+const ShareTokenHeader = "X-Share-Token"
+
+// ShareTokenMiddleware 开启局域网共享后校验共享口令：接口使用本机登录的京东 Cookie，
+// 本机（GUI、jdpush）的请求直接放行，其他电脑的请求须携带与配置一致的口令
+type ShareTokenMiddleware struct {
+	token string
+}
+
+func NewShareTokenMiddleware(token string) *ShareTokenMiddleware {
+	return &ShareTokenMiddleware{token: token}
+}
+
+func (m *ShareTokenMiddleware) Handle(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !loopback(r.RemoteAddr) && subtle.ConstantTimeCompare([]byte(r.Header.Get(ShareTokenHeader)), []byte(m.token)) != 1 {
+			httpx.WriteJson(w, http.StatusUnauthorized, map[string]interface{}{
+				"code":    http.StatusUnauthorized,
+				"message": "共享口令错误，请在请求头 " + ShareTokenHeader + " 中携带运行 GUI 的同事提供的口令",
+			})
+			return
+		}
+		next(w, r)
+	}
+}
+
+// loopback 请求是否来自本机
+func loopback(remoteAddr string) bool {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
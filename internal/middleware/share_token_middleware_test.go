@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestShareTokenMiddleware(t *testing.T) {
+	handler := NewShareTokenMiddleware("secret").Handle(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		token      string
+		want       int
+	}{
+		{name: "本机请求无需口令", remoteAddr: "127.0.0.1:50000", want: http.StatusOK},
+		{name: "本机 IPv6 请求无需口令", remoteAddr: "[::1]:50000", want: http.StatusOK},
+		{name: "其他电脑携带正确口令", remoteAddr: "192.168.1.20:50000", token: "secret", want: http.StatusOK},
+		{name: "其他电脑未携带口令", remoteAddr: "192.168.1.20:50000", want: http.StatusUnauthorized},
+		{name: "其他电脑口令错误", remoteAddr: "192.168.1.20:50000", token: "secre", want: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/catalog", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.token != "" {
+				req.Header.Set(ShareTokenHeader, tt.token)
+			}
+			rec := httptest.NewRecorder()
+			handler(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("状态码 = %d，应为 %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+package naming
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DefaultMaxLength 素材名称的默认最大长度（字符数，含扩展名）
+const DefaultMaxLength = 50
+
+// 默认短哈希长度
+const defaultHashLength = 6
+
+// maxSeqWidth {seq:N} 最多补零到的位数
+const maxSeqWidth = 9
+
+var placeholderRe = regexp.MustCompile(`\{([a-z]+)(?::(\d+))?\}`)
+
+// copySuffixRe 系统复制文件时追加的后缀，如 (1)、（2）、 - 副本、 - Copy
+var copySuffixRe = regexp.MustCompile(`(\s*[(（]\d+[)）]|\s*-\s*(副本|Copy|copy))+$`)
+
+// Template 素材名称模板
+// 变量: {date} 日期 20060102，{campaign} 活动名，{category} 品类名称，{media} 投放媒体名称，
+// {seq} 序号（{seq:3} 补零到 3 位），{stem} 不含扩展名的原文件名，{hash} 由原文件名和大小计算的短哈希（{hash:8} 指定长度），{ext} 扩展名
+type Template struct {
+	text      string
+	maxLength int
+}
+
+// Vars 生成一个素材名称所需的值
+type Vars struct {
+	Name     string    // 原素材名称，可含子目录
+	Size     int64     // 文件大小
+	Seq      int       // 序号，从 1 开始
+	Campaign string    // 活动名
+	Media    []string  // 投放媒体名称
+	Category []string  // 品类名称
+	Now      time.Time // 生成时间
+}
+
+// Result 生成的素材名称
+type Result struct {
+	Name      string // 生成的名称
+	Truncated bool   // 是否因超长截断了原文件名
+}
+
+// Parse 解析模板，模板中没有 {ext} 时自动保留原扩展名；maxLength 不大于 0 时使用 DefaultMaxLength
+func Parse(text string, maxLength int) (*Template, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, fmt.Errorf("素材名称模板不能为空")
+	}
+	if strings.Count(text, "{") != len(placeholderRe.FindAllString(text, -1)) {
+		return nil, fmt.Errorf("素材名称模板有误: %s", text)
+	}
+	for _, m := range placeholderRe.FindAllStringSubmatch(text, -1) {
+		switch m[1] {
+		case "date", "campaign", "category", "media", "stem", "ext":
+			if m[2] != "" {
+				return nil, fmt.Errorf("素材名称模板变量 {%s} 不支持指定长度", m[1])
+			}
+		case "seq":
+			if width, _ := strconv.Atoi(m[2]); width > maxSeqWidth {
+				return nil, fmt.Errorf("素材名称模板变量 {seq} 最多补零到 %d 位: %s", maxSeqWidth, m[0])
+			}
+		case "hash":
+		default:
+			return nil, fmt.Errorf("素材名称模板中有未知变量 {%s}（可选 date、campaign、category、media、seq、stem、hash、ext）", m[1])
+		}
+	}
+	if !strings.Contains(text, "{ext}") {
+		text += "{ext}"
+	}
+	if maxLength <= 0 {
+		maxLength = DefaultMaxLength
+	}
+	return &Template{text: text, maxLength: maxLength}, nil
+}
+
+// Render 生成素材名称：清理非法字符，超长时先截断原文件名部分，仍超长时截断整个名称并保留扩展名
+func (t *Template) Render(v Vars) Result {
+	base := path.Base(v.Name)
+	ext := path.Ext(base)
+	stem := Clean(copySuffixRe.ReplaceAllString(strings.TrimSuffix(base, ext), ""))
+	if stem == "" {
+		stem = Clean(strings.TrimSuffix(base, ext))
+	}
+
+	render := func(stem string) string {
+		name := placeholderRe.ReplaceAllStringFunc(t.text, func(s string) string {
+			m := placeholderRe.FindStringSubmatch(s)
+			width, _ := strconv.Atoi(m[2])
+			switch m[1] {
+			case "date":
+				return v.Now.Format("20060102")
+			case "campaign":
+				return Clean(v.Campaign)
+			case "category":
+				return Clean(strings.Join(v.Category, "+"))
+			case "media":
+				return Clean(strings.Join(v.Media, "+"))
+			case "seq":
+				return fmt.Sprintf("%0*d", width, v.Seq)
+			case "stem":
+				return stem
+			case "hash":
+				return shortHash(v.Name, v.Size, width)
+			case "ext":
+				return strings.ToLower(ext)
+			}
+			return s
+		})
+		return tidy(name, ext)
+	}
+
+	name := render(stem)
+	over := utf8.RuneCountInString(name) - t.maxLength
+	if over <= 0 {
+		return Result{Name: name}
+	}
+
+	runes := []rune(stem)
+	if over < len(runes) {
+		return Result{Name: render(strings.TrimRight(string(runes[:len(runes)-over]), "_-. ")), Truncated: true}
+	}
+	name = render("")
+	runes = []rune(strings.TrimSuffix(name, strings.ToLower(ext)))
+	keep := t.maxLength - utf8.RuneCountInString(ext)
+	if keep < len(runes) && keep > 0 {
+		name = strings.TrimRight(string(runes[:keep]), "_-. ") + strings.ToLower(ext)
+	}
+	return Result{Name: name, Truncated: true}
+}
+
+// Clean 将文件名中不允许的字符和空白替换为 _，并合并连续的 _
+func Clean(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case strings.ContainsRune(`\/:*?"<>|`, r), unicode.IsControl(r), unicode.IsSpace(r):
+			b.WriteRune('_')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return strings.Trim(collapse(b.String()), "_")
+}
+
+// tidy 合并变量为空时留下的连续分隔符，去掉首尾的分隔符
+func tidy(name, ext string) string {
+	base := collapse(strings.TrimSuffix(name, strings.ToLower(ext)))
+	return strings.Trim(base, "_-. ") + strings.ToLower(ext)
+}
+
+func collapse(s string) string {
+	for _, sep := range []string{"__", "--"} {
+		for strings.Contains(s, sep) {
+			s = strings.ReplaceAll(s, sep, sep[:1])
+		}
+	}
+	return s
+}
+
+// shortHash 原文件名和大小的 sha256 前若干位，同一文件预览和提交时一致
+func shortHash(name string, size int64, length int) string {
+	if length <= 0 {
+		length = defaultHashLength
+	}
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%d", name, size)))
+	h := hex.EncodeToString(sum[:])
+	if length > len(h) {
+		length = len(h)
+	}
+	return h[:length]
+}
+
+// Dedupe 生成的名称重复时在扩展名前追加 _2、_3，按出现顺序保留第一个
+func Dedupe(names []string) []string {
+	return dedupe(nil, names, 0)
+}
+
+// DedupeAgainst 同 Dedupe，且不使用 taken 中已被占用的名称（如同一任务中之前提交的素材）；
+// 追加序号后超过模板的最大长度时先截短原名称
+func (t *Template) DedupeAgainst(taken, names []string) []string {
+	return dedupe(taken, names, t.maxLength)
+}
+
+// dedupe maxLength 不大于 0 时不限制长度
+func dedupe(taken, names []string, maxLength int) []string {
+	seen := make(map[string]bool, len(taken)+len(names))
+	for _, name := range taken {
+		seen[strings.ToLower(name)] = true
+	}
+	out := make([]string, len(names))
+	for i, name := range names {
+		candidate := name
+		ext := path.Ext(name)
+		for n := 2; seen[strings.ToLower(candidate)]; n++ {
+			stem := []rune(strings.TrimSuffix(name, ext))
+			suffix := fmt.Sprintf("_%d", n)
+			if keep := maxLength - len(suffix) - utf8.RuneCountInString(ext); maxLength > 0 && keep > 0 && keep < len(stem) {
+				stem = []rune(strings.TrimRight(string(stem[:keep]), "_-. "))
+			}
+			candidate = string(stem) + suffix + ext
+		}
+		seen[strings.ToLower(candidate)] = true
+		out[i] = candidate
+	}
+	return out
+}
@@ -0,0 +1,112 @@
+package naming
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRender(t *testing.T) {
+	now := time.Date(2026, 10, 19, 9, 30, 0, 0, time.Local)
+	tests := []struct {
+		name      string
+		template  string
+		maxLength int
+		vars      Vars
+		want      string
+		truncated bool
+	}{
+		{
+			name:     "全部变量",
+			template: "{date}_{campaign}_{category}_{seq:3}_{stem}",
+			vars:     Vars{Name: "最终版-v3(1).mp4", Seq: 1, Campaign: "双11", Category: []string{"女装"}},
+			want:     "20261019_双11_女装_001_最终版-v3.mp4",
+		},
+		{
+			name:     "变量为空时合并分隔符",
+			template: "{campaign}_{seq}_{stem}",
+			vars:     Vars{Name: "a b.jpg", Seq: 2},
+			want:     "2_a_b.jpg",
+		},
+		{
+			name:     "清理非法字符并统一小写扩展名",
+			template: "{campaign}_{stem}",
+			vars:     Vars{Name: "x.JPG", Campaign: "a/b:c"},
+			want:     "a_b_c_x.jpg",
+		},
+		{
+			name:      "超长时先截断原文件名",
+			template:  "{seq:2}_{stem}",
+			maxLength: 10,
+			vars:      Vars{Name: "abcdefghijkl.jpg", Seq: 1},
+			want:      "01_abc.jpg",
+			truncated: true,
+		},
+		{
+			name:      "按字符数截断中文",
+			template:  "{stem}",
+			maxLength: 8,
+			vars:      Vars{Name: "一二三四五六七八九十.png"},
+			want:      "一二三四.png",
+			truncated: true,
+		},
+		{
+			name:      "去掉原文件名仍超长时截断整个名称",
+			template:  "{campaign}_{stem}",
+			maxLength: 10,
+			vars:      Vars{Name: "x.jpg", Campaign: "abcdefghijklmn"},
+			want:      "abcdef.jpg",
+			truncated: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := Parse(tt.template, tt.maxLength)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.vars.Now = now
+			got := tmpl.Render(tt.vars)
+			if got.Name != tt.want || got.Truncated != tt.truncated {
+				t.Errorf("Render = %q（截断 %v），应为 %q（截断 %v）", got.Name, got.Truncated, tt.want, tt.truncated)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, text := range []string{"", "  ", "{foo}_{stem}", "{date:3}", "{seq_{stem}", "{seq:10}_{stem}"} {
+		if _, err := Parse(text, 0); err == nil {
+			t.Errorf("Parse(%q) 应返回错误", text)
+		}
+	}
+}
+
+func TestDedupeAgainst(t *testing.T) {
+	tests := []struct {
+		name      string
+		maxLength int
+		taken     []string
+		names     []string
+		want      []string
+	}{
+		{"不重复", 0, nil, []string{"a.jpg", "b.jpg"}, []string{"a.jpg", "b.jpg"}},
+		{"重复追加序号", 0, nil, []string{"a.jpg", "a.jpg", "a.jpg"}, []string{"a.jpg", "a_2.jpg", "a_3.jpg"}},
+		{"不区分大小写", 0, nil, []string{"A.jpg", "a.JPG"}, []string{"A.jpg", "a_2.JPG"}},
+		{"避开追加后已存在的名称", 0, nil, []string{"a.jpg", "a_2.jpg", "a.jpg"}, []string{"a.jpg", "a_2.jpg", "a_3.jpg"}},
+		{"避开任务中已使用的名称", 0, []string{"a.jpg", "a_2.jpg"}, []string{"a.jpg", "b.jpg"}, []string{"a_3.jpg", "b.jpg"}},
+		{"追加序号后超长时截短原名称", 10, nil, []string{"abcdef.jpg", "abcdef.jpg"}, []string{"abcdef.jpg", "abcd_2.jpg"}},
+		{"按字符数截短中文", 8, []string{"一二三四.png"}, []string{"一二三四.png"}, []string{"一二_2.png"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := Parse("{stem}", tt.maxLength)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := tmpl.DedupeAgainst(tt.taken, tt.names); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DedupeAgainst = %q，应为 %q", got, tt.want)
+			}
+		})
+	}
+}
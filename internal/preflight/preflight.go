@@ -0,0 +1,403 @@
+package preflight
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"jd_material_push/internal/applyattr"
+	"jd_material_push/internal/catalog"
+	"jd_material_push/internal/lexicon"
+	"jd_material_push/internal/media"
+	"jd_material_push/internal/mediaspec"
+	"jd_material_push/internal/source"
+)
+
+// 检查结果的严重程度
+const (
+	SeverityError   = "error"   // 阻止推送
+	SeverityWarning = "warning" // 提示但允许推送
+)
+
+// Rules 预检规则
+type Rules struct {
+	MaxImageSizeMB int64 `json:",default=10"`  // 图片大小上限
+	MaxVideoSizeMB int64 `json:",default=500"` // 视频大小上限
+	MaxNameLength  int   `json:",default=100"` // 文件名最大字符数
+}
+
+// Finding 一条检查结果
+type Finding struct {
+	File     string `json:"file"`     // 文件名，非文件相关的检查为空
+	Rule     string `json:"rule"`     // 规则标识
+	Severity string `json:"severity"` // error / warning
+	Message  string `json:"message"`  // 问题描述
+}
+
+// Compliance 违禁词检查设置
+type Compliance struct {
+	Lexicon *lexicon.Lexicon
+	Mode    string // 处理方案的检查方式：空（按词库中的级别）、block、warn、off
+}
+
+// check 检查一段文本中的违禁词，field 说明文本的来源，如 投放文案
+func (c Compliance) check(file, field, text string) []Finding {
+	if c.Mode == lexicon.ModeOff {
+		return nil
+	}
+	hits := c.Lexicon.Check(text)
+	var findings []Finding
+	for _, h := range hits {
+		findings = append(findings, Finding{
+			File:     file,
+			Rule:     "compliance",
+			Severity: lexicon.Severity(h, c.Mode),
+			Message:  fmt.Sprintf("%s含违禁词%s: %s", field, lexicon.Describe(h), lexicon.Highlight(text, hits)),
+		})
+	}
+	return findings
+}
+
+// HasErrors 是否存在阻止推送的问题
+func HasErrors(findings []Finding) bool {
+	for _, f := range findings {
+		if f.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Run 执行完整预检：文件夹内的文件检查（含所选投放媒体的素材规格）加上已填写的 applyAttr 列值检查，
+// 以及投放文案、文件名和附带文案中的违禁词
+func Run(folderPath string, filter source.Filter, rules Rules, specs *mediaspec.Set, columns []catalog.Column, values map[string]interface{}, compliance Compliance) ([]Finding, error) {
+	mediaList, _ := applyattr.Strings(values[catalog.ColumnKeyMedia])
+	findings, err := CheckFolder(folderPath, filter, rules, specs, mediaList, compliance)
+	if err != nil {
+		return nil, err
+	}
+	findings = append(findings, CheckColumns(columns, values)...)
+	if releaseCopy, ok := values[catalog.ColumnKeyRelease].(string); ok {
+		findings = append(findings, compliance.check("", "投放文案", releaseCopy)...)
+	}
+	if compliance.Mode != lexicon.ModeOff && compliance.Lexicon.Missing() {
+		findings = append(findings, Finding{Rule: "compliance", Severity: SeverityWarning, Message: "违禁词词库不存在，未检查违禁词"})
+	}
+	return findings, nil
+}
+
+// Count 统计 error 与 warning 数量
+func Count(findings []Finding) (errors, warnings int) {
+	for _, f := range findings {
+		switch f.Severity {
+		case SeverityError:
+			errors++
+		case SeverityWarning:
+			warnings++
+		}
+	}
+	return errors, warnings
+}
+
+// CheckFolder 扫描上传源（文件夹或压缩包）中待上传的文件并返回全部问题，规则与上传时的文件筛选一致（跳过子目录、隐藏文件，
+// 以及方案和 .pushignore 中忽略的文件）；specs 为空或未选择投放媒体时不检查素材规格
+func CheckFolder(folderPath string, filter source.Filter, rules Rules, specs *mediaspec.Set, mediaList []string, compliance Compliance) ([]Finding, error) {
+	src, err := source.Open(folderPath)
+	if err != nil {
+		return nil, fmt.Errorf("读取上传源失败: %w", err)
+	}
+	defer src.Close()
+
+	entries, _, err := source.Select(src, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []Finding
+	hashes := make(map[string][]string)
+	var hashOrder []string
+
+	sidecars := source.Sidecars(src.Entries())
+	read := readContents(src, entries, sidecars)
+	for _, entry := range entries {
+		findings = append(findings, checkName(entry.Path, entry.Name(), rules)...)
+		findings = append(findings, compliance.check(entry.Path, "文件名", entry.Name())...)
+		if sidecar, ok := sidecars[entry.Path]; ok && compliance.Mode != lexicon.ModeOff {
+			text, err := read.sidecar(sidecar)
+			findings = append(findings, checkSidecar(entry.Path, sidecar, text, err, compliance)...)
+		}
+
+		if entry.Size == 0 {
+			findings = append(findings, Finding{File: entry.Path, Rule: "zero-byte", Severity: SeverityError, Message: "文件大小为 0"})
+			continue
+		}
+		c := read.file(entry)
+		findings = append(findings, checkFile(entry.Path, entry.Name(), c.typeInfo, c.meta, c.probeErr, entry.Size, rules, specs, mediaList)...)
+
+		if c.readErr != nil {
+			findings = append(findings, Finding{File: entry.Path, Rule: "read", Severity: SeverityError, Message: fmt.Sprintf("读取文件失败: %v", c.readErr)})
+			continue
+		}
+		if _, ok := hashes[c.sum]; !ok {
+			hashOrder = append(hashOrder, c.sum)
+		}
+		hashes[c.sum] = append(hashes[c.sum], entry.Path)
+	}
+
+	if len(entries) == 0 {
+		findings = append(findings, Finding{Rule: "empty-folder", Severity: SeverityError, Message: "文件夹中没有可上传的文件"})
+	}
+
+	for _, sum := range hashOrder {
+		names := hashes[sum]
+		if len(names) < 2 {
+			continue
+		}
+		for _, name := range names[1:] {
+			findings = append(findings, Finding{
+				File:     name,
+				Rule:     "duplicate",
+				Severity: SeverityWarning,
+				Message:  fmt.Sprintf("与 %s 内容完全相同", names[0]),
+			})
+		}
+	}
+
+	return findings, nil
+}
+
+// CheckColumns 按列定义检查已填写的 applyAttr 列值（如投放文案长度），未填写的列不报必填
+func CheckColumns(columns []catalog.Column, values map[string]interface{}) []Finding {
+	provided := make(map[string]interface{}, len(values))
+	var filled []catalog.Column
+	for _, col := range columns {
+		if v, ok := values[col.Key]; ok && !isEmpty(v) {
+			provided[col.Key] = v
+			filled = append(filled, col)
+		}
+	}
+
+	var findings []Finding
+	for _, problem := range applyattr.Validate(filled, provided) {
+		findings = append(findings, Finding{
+			Rule:     "apply-attr",
+			Severity: SeverityError,
+			Message:  problem,
+		})
+	}
+	return findings
+}
+
+func isEmpty(v interface{}) bool {
+	switch val := v.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(val) == ""
+	case []string:
+		return len(val) == 0
+	case []interface{}:
+		return len(val) == 0
+	}
+	return false
+}
+
+// checkFile 按识别结果检查文件内容类型、元数据、按素材类型的大小上限和投放媒体规格
+// file 为结果中的文件标识（压缩包内为相对路径），name 为文件名，err 为识别或读取元数据的错误
+func checkFile(file, name string, typeInfo media.TypeInfo, meta media.Metadata, err error, size int64, rules Rules, specs *mediaspec.Set, mediaList []string) []Finding {
+	if err != nil && typeInfo.Kind == "" {
+		return []Finding{{File: file, Rule: "type", Severity: SeverityError, Message: err.Error()}}
+	}
+
+	var findings []Finding
+	if err != nil {
+		findings = append(findings, Finding{
+			File:     file,
+			Rule:     "metadata",
+			Severity: SeverityWarning,
+			Message:  fmt.Sprintf("无法解析尺寸或时长: %v", err),
+		})
+	}
+	if !typeInfo.ExtMatches(name) {
+		findings = append(findings, Finding{
+			File:     file,
+			Rule:     "extension",
+			Severity: SeverityWarning,
+			Message:  fmt.Sprintf("扩展名 %q 与实际内容 %s 不符", filepath.Ext(name), typeInfo.Kind),
+		})
+	}
+
+	var limitMB int64
+	switch typeInfo.MaterialType {
+	case media.MaterialTypeImage:
+		limitMB = rules.MaxImageSizeMB
+	case media.MaterialTypeVideo:
+		limitMB = rules.MaxVideoSizeMB
+	}
+	if limitMB > 0 && size > limitMB*1024*1024 {
+		findings = append(findings, Finding{
+			File:     file,
+			Rule:     "size",
+			Severity: SeverityError,
+			Message:  fmt.Sprintf("文件大小 %.2f MB 超过上限 %d MB", float64(size)/1024/1024, limitMB),
+		})
+	}
+
+	specFile := mediaspec.File{Name: name, Size: size, MaterialType: typeInfo.MaterialType, Meta: meta}
+	for _, v := range specs.Check(mediaList, specFile) {
+		findings = append(findings, Finding{
+			File:     file,
+			Rule:     "spec-" + v.Rule,
+			Severity: v.Severity,
+			Message:  fmt.Sprintf("不符合%s规格: %s", v.Media, v.Message),
+		})
+	}
+
+	return findings
+}
+
+// checkName 检查文件名长度和非法字符
+func checkName(file, name string, rules Rules) []Finding {
+	var findings []Finding
+
+	if rules.MaxNameLength > 0 && utf8.RuneCountInString(name) > rules.MaxNameLength {
+		findings = append(findings, Finding{
+			File:     file,
+			Rule:     "name-length",
+			Severity: SeverityError,
+			Message:  fmt.Sprintf("文件名超过 %d 个字符", rules.MaxNameLength),
+		})
+	}
+
+	if strings.ContainsAny(name, `\/:*?"<>|`) || strings.IndexFunc(name, unicode.IsControl) >= 0 {
+		findings = append(findings, Finding{
+			File:     file,
+			Rule:     "name-chars",
+			Severity: SeverityError,
+			Message:  "文件名包含非法字符",
+		})
+	}
+
+	stem := strings.TrimSuffix(name, filepath.Ext(name))
+	if stem != strings.TrimSpace(stem) {
+		findings = append(findings, Finding{
+			File:     file,
+			Rule:     "name-space",
+			Severity: SeverityWarning,
+			Message:  "文件名首尾包含空格",
+		})
+	}
+
+	return findings
+}
+
+// checkSidecar 检查素材附带文案中的违禁词
+func checkSidecar(file string, sidecar source.Entry, text string, err error, compliance Compliance) []Finding {
+	if err != nil {
+		return []Finding{{File: file, Rule: "read", Severity: SeverityWarning, Message: err.Error()}}
+	}
+	return compliance.check(file, "附带文案（"+sidecar.Name()+"）", text)
+}
+
+// content 读取文件内容得到的类型、元数据和 sha256
+type content struct {
+	typeInfo media.TypeInfo
+	meta     media.Metadata
+	probeErr error
+	sum      string
+	readErr  error
+}
+
+// contents 按需读取文件内容和附带文案
+type contents struct {
+	file    func(entry source.Entry) content
+	sidecar func(sidecar source.Entry) (string, error)
+}
+
+// readContents 可随机读取的上传源在检查到每个文件时读取；.tar.gz 逐个打开每次都要从头解压，
+// 先读一遍压缩包，按压缩包中的顺序读出全部文件和附带文案
+func readContents(src source.Source, entries []source.Entry, sidecars map[string]source.Entry) contents {
+	walker, ok := src.(source.Walker)
+	if !ok {
+		return contents{
+			file: func(entry source.Entry) content {
+				var c content
+				c.typeInfo, c.meta, c.probeErr = source.Probe(src, entry)
+				c.sum, c.readErr = hashEntry(src, entry)
+				return c
+			},
+			sidecar: func(sidecar source.Entry) (string, error) {
+				return source.ReadSidecar(src, sidecar)
+			},
+		}
+	}
+
+	files := make(map[string]content, len(entries))
+	texts := make(map[string]string)
+	textErrs := make(map[string]error)
+	isSidecar := make(map[string]bool)
+	wanted := append([]source.Entry(nil), entries...)
+	for _, sidecar := range sidecars {
+		if !isSidecar[sidecar.Path] {
+			isSidecar[sidecar.Path] = true
+			wanted = append(wanted, sidecar)
+		}
+	}
+	walkErr := walker.Walk(wanted, func(entry source.Entry, r io.Reader) error {
+		if isSidecar[entry.Path] {
+			texts[entry.Path], textErrs[entry.Path] = source.ParseSidecar(entry, r)
+			return nil
+		}
+		// 识别类型时读过的内容经 TeeReader 计入哈希，再读完剩余部分
+		var c content
+		h := sha256.New()
+		c.typeInfo, c.meta, c.probeErr = media.ProbeStream(io.TeeReader(r, h))
+		if _, err := io.Copy(h, r); err != nil {
+			c.readErr = err
+		} else {
+			c.sum = hex.EncodeToString(h.Sum(nil))
+		}
+		files[entry.Path] = c
+		return nil
+	})
+
+	missing := func(path string) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		return fmt.Errorf("压缩包中不存在: %s", path)
+	}
+	return contents{
+		file: func(entry source.Entry) content {
+			if c, ok := files[entry.Path]; ok {
+				return c
+			}
+			return content{probeErr: missing(entry.Path), readErr: missing(entry.Path)}
+		},
+		sidecar: func(sidecar source.Entry) (string, error) {
+			if text, ok := texts[sidecar.Path]; ok {
+				return text, textErrs[sidecar.Path]
+			}
+			return "", fmt.Errorf("读取附带文案 %s 失败: %w", sidecar.Path, missing(sidecar.Path))
+		},
+	}
+}
+
+// hashEntry 顺序读取文件内容计算 sha256
+func hashEntry(src source.Source, entry source.Entry) (string, error) {
+	r, err := src.Open(entry)
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,324 @@
+package preset
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Preset 一组命名的推送设置，保存在团队共享的 YAML 文件中；未填写的字段使用请求或界面中的值
+type Preset struct {
+	Name               string                 `yaml:"Name"`
+	Description        string                 `yaml:"Description,omitempty"`        // 说明
+	Account            string                 `yaml:"Account,omitempty"`            // 要求的京东账号（Cookie 中的 pin），与当前登录账号不一致时拒绝提交
+	MediaList          []string               `yaml:"MediaList,omitempty"`          // 投放媒体
+	CategoryList       []string               `yaml:"CategoryList,omitempty"`       // 素材品类
+	ReleaseCopy        string                 `yaml:"ReleaseCopy,omitempty"`        // 投放文案
+	CopyVariants       []CopyVariant          `yaml:"CopyVariants,omitempty"`       // 投放文案变体
+	CopyVars           map[string]string      `yaml:"CopyVars,omitempty"`           // 文案模板变量
+	Columns            map[string]interface{} `yaml:"Columns,omitempty"`            // 其他 diyColumns 列值
+	NameTemplate       string                 `yaml:"NameTemplate,omitempty"`       // 素材名称模板
+	Campaign           string                 `yaml:"Campaign,omitempty"`           // 活动名
+	Profile            string                 `yaml:"Profile,omitempty"`            // 上传前处理方案
+	Order              string                 `yaml:"Order,omitempty"`              // 上传顺序
+	SubmitPolicy       string                 `yaml:"SubmitPolicy,omitempty"`       // 提交策略
+	MinUploadedPercent int                    `yaml:"MinUploadedPercent,omitempty"` // threshold 策略要求的上传成功比例
+	IsolateFailures    bool                   `yaml:"IsolateFailures,omitempty"`    // 批次被拒绝时拆分重新提交
+}
+
+// CopyVariant 预设中的文案变体
+type CopyVariant struct {
+	CopyID string `yaml:"CopyID,omitempty"` // 文案库中的文案 ID，与 Text 二选一
+	Text   string `yaml:"Text,omitempty"`
+	Match  string `yaml:"Match,omitempty"`
+}
+
+// file 预设文件的结构，导出的文件与之相同，可直接作为共享文件使用
+type file struct {
+	Presets []Preset `yaml:"Presets"`
+}
+
+// Validate 检查预设中可在本地判断的取值；投放媒体、品类和处理方案在使用时按当前目录和配置校验
+func (p Preset) Validate() error {
+	if strings.TrimSpace(p.Name) == "" {
+		return errors.New("预设名称不能为空")
+	}
+	switch p.SubmitPolicy {
+	case "", "partial", "all", "threshold":
+	default:
+		return fmt.Errorf("预设 %s 的提交策略有误: %s（可选 partial、all、threshold）", p.Name, p.SubmitPolicy)
+	}
+	switch p.Order {
+	case "", "name", "natural", "mtime", "size":
+	default:
+		return fmt.Errorf("预设 %s 的上传顺序有误: %s（可选 name、natural、mtime、size）", p.Name, p.Order)
+	}
+	if p.SubmitPolicy == "threshold" && (p.MinUploadedPercent < 1 || p.MinUploadedPercent > 100) {
+		return fmt.Errorf("预设 %s 使用 threshold 策略时最低上传成功比例需为 1-100", p.Name)
+	}
+	if p.MinUploadedPercent < 0 || p.MinUploadedPercent > 100 {
+		return fmt.Errorf("预设 %s 的最低上传成功比例需为 1-100（只在 threshold 策略下使用）", p.Name)
+	}
+	return nil
+}
+
+// Parse 解析预设文件内容，检查每个预设并拒绝重名
+func Parse(data []byte) ([]Preset, error) {
+	var f file
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("解析预设失败: %w", err)
+	}
+	seen := make(map[string]bool, len(f.Presets))
+	for i := range f.Presets {
+		p := &f.Presets[i]
+		p.Name = strings.TrimSpace(p.Name)
+		if err := p.Validate(); err != nil {
+			return nil, err
+		}
+		if seen[p.Name] {
+			return nil, fmt.Errorf("预设重名: %s", p.Name)
+		}
+		seen[p.Name] = true
+	}
+	return f.Presets, nil
+}
+
+// Marshal 将预设编码为预设文件内容
+func Marshal(presets []Preset) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(file{Presets: presets}); err != nil {
+		return nil, fmt.Errorf("编码预设失败: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("编码预设失败: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// ImportResult 导入预设的结果
+type ImportResult struct {
+	Added    []string // 新增的预设
+	Replaced []string // 覆盖的同名预设
+	Skipped  []string // 已存在且未选择覆盖的预设
+}
+
+// Store 预设文件，可放在共享文件夹中供团队共用：每次读取时检查文件修改时间，他人修改后自动重新加载；
+// 修改前先重新读取文件，只改动涉及的预设
+type Store struct {
+	path    string
+	mu      sync.Mutex
+	presets []Preset
+	modTime time.Time
+}
+
+// NewStore 加载预设文件，文件不存在时为空
+func NewStore(path string) (*Store, error) {
+	s := &Store{path: path}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// List 返回全部预设，按文件中的顺序
+func (s *Store) List() ([]Preset, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.refresh(); err != nil {
+		return nil, err
+	}
+	return append([]Preset(nil), s.presets...), nil
+}
+
+// Get 按名称获取预设
+func (s *Store) Get(name string) (Preset, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.refresh(); err != nil {
+		return Preset{}, false, err
+	}
+	if i := s.index(name); i >= 0 {
+		return s.presets[i], true, nil
+	}
+	return Preset{}, false, nil
+}
+
+// Save 新增预设或覆盖同名预设并落盘
+func (s *Store) Save(p Preset) error {
+	p.Name = strings.TrimSpace(p.Name)
+	if err := p.Validate(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.load(); err != nil {
+		return err
+	}
+	if i := s.index(p.Name); i >= 0 {
+		s.presets[i] = p
+	} else {
+		s.presets = append(s.presets, p)
+	}
+	return s.save()
+}
+
+// Delete 删除预设，不存在时返回 false
+func (s *Store) Delete(name string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.load(); err != nil {
+		return false, err
+	}
+	i := s.index(name)
+	if i < 0 {
+		return false, nil
+	}
+	s.presets = append(s.presets[:i], s.presets[i+1:]...)
+	return true, s.save()
+}
+
+// Export 导出指定名称的预设，names 为空时导出全部
+func (s *Store) Export(names []string) ([]byte, error) {
+	presets, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		return Marshal(presets)
+	}
+
+	var out []Preset
+	for _, name := range names {
+		found := false
+		for _, p := range presets {
+			if p.Name == name {
+				out = append(out, p)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("预设不存在: %s", name)
+		}
+	}
+	return Marshal(out)
+}
+
+// Import 导入预设文件内容；同名预设在 overwrite 为 true 时覆盖，否则跳过
+func (s *Store) Import(data []byte, overwrite bool) (ImportResult, error) {
+	var result ImportResult
+	incoming, err := Parse(data)
+	if err != nil {
+		return result, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.load(); err != nil {
+		return result, err
+	}
+	for _, p := range incoming {
+		i := s.index(p.Name)
+		switch {
+		case i < 0:
+			s.presets = append(s.presets, p)
+			result.Added = append(result.Added, p.Name)
+		case overwrite:
+			s.presets[i] = p
+			result.Replaced = append(result.Replaced, p.Name)
+		default:
+			result.Skipped = append(result.Skipped, p.Name)
+		}
+	}
+	if len(result.Added) == 0 && len(result.Replaced) == 0 {
+		return result, nil
+	}
+	return result, s.save()
+}
+
+func (s *Store) index(name string) int {
+	name = strings.TrimSpace(name)
+	for i, p := range s.presets {
+		if p.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// refresh 文件修改时间变化时重新加载
+func (s *Store) refresh() error {
+	info, err := os.Stat(s.path)
+	if err != nil || info.ModTime().Equal(s.modTime) {
+		return nil
+	}
+	return s.load()
+}
+
+func (s *Store) load() error {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		s.presets, s.modTime = nil, time.Time{}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("读取预设文件失败: %w", err)
+	}
+	presets, err := Parse(data)
+	if err != nil {
+		return fmt.Errorf("预设文件 %s 有误: %w", s.path, err)
+	}
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return fmt.Errorf("读取预设文件失败: %w", err)
+	}
+	s.presets, s.modTime = presets, info.ModTime()
+	return nil
+}
+
+// save 先写临时文件再替换，避免写一半时共享文件损坏
+func (s *Store) save() error {
+	if dir := filepath.Dir(s.path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("创建预设目录失败: %w", err)
+		}
+	}
+
+	data, err := Marshal(s.presets)
+	if err != nil {
+		return err
+	}
+	data = append([]byte(header), data...)
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("写入预设文件失败: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("写入预设文件失败: %w", err)
+	}
+	if info, err := os.Stat(s.path); err == nil {
+		s.modTime = info.ModTime()
+	}
+	return nil
+}
+
+// header 写回预设文件时保留的说明
+const header = `# 推送预设，可放在共享文件夹中供团队共用（配置 PresetsPath 指向该文件）
+# 通过 GUI、接口或 jdpush preset 修改时会重写本文件
+`
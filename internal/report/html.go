@@ -0,0 +1,120 @@
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+
+	"jd_material_push/internal/media"
+)
+
+// writeHTML 写出可离线打开的单个 HTML 页面：上传时生成的缩略图内嵌在页面中，没有缩略图的图片引用素材 URL
+func writeHTML(w io.Writer, r Report) error {
+	for i := range r.Items {
+		r.Items[i].thumbnail = thumbnailURI(r.Items[i].thumbnailPath)
+	}
+	return galleryTemplate.Execute(w, r)
+}
+
+var galleryTemplate = template.Must(template.New("gallery").Funcs(template.FuncMap{
+	"join":   strings.Join,
+	"status": StatusText,
+	"time":   func(t time.Time) string { return t.Format("2006-01-02 15:04") },
+	"size":   sizeText,
+	"thumb":  func(item Item) template.URL { return template.URL(item.thumbnail) },
+	"image":  func(item Item) bool { return item.Type == typeName(media.MaterialTypeImage) },
+	"meta":   metaText,
+}).Parse(galleryHTML))
+
+func sizeText(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
+
+func metaText(item Item) string {
+	var parts []string
+	if item.Width > 0 && item.Height > 0 {
+		parts = append(parts, intText(item.Width)+"×"+intText(item.Height))
+	}
+	if item.Duration > 0 {
+		parts = append(parts, durationText(item.Duration)+" 秒")
+	}
+	return strings.Join(parts, " · ")
+}
+
+const galleryHTML = `<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>素材交付报告 {{.JobID}}</title>
+<style>
+body { margin: 0; padding: 24px; font-family: -apple-system, "PingFang SC", "Microsoft YaHei", sans-serif; background: #f5f6f8; color: #222; }
+h1 { font-size: 22px; margin: 0 0 12px; }
+.summary { background: #fff; border-radius: 8px; padding: 16px 20px; margin-bottom: 20px; line-height: 1.8; }
+.summary span { color: #666; }
+.grid { display: grid; grid-template-columns: repeat(auto-fill, minmax(260px, 1fr)); gap: 16px; }
+.card { background: #fff; border-radius: 8px; overflow: hidden; display: flex; flex-direction: column; }
+.thumb { height: 200px; background: #eceef1; display: flex; align-items: center; justify-content: center; color: #888; }
+.thumb img { max-width: 100%; max-height: 100%; }
+.info { padding: 12px; font-size: 13px; line-height: 1.7; word-break: break-all; }
+.name { font-weight: 600; font-size: 14px; }
+.badge { display: inline-block; padding: 0 6px; border-radius: 4px; background: #e8f0fe; color: #1a56db; margin-right: 4px; }
+.badge.failed, .badge.rejected, .badge.held { background: #fde8e8; color: #c81e1e; }
+.badge.approved { background: #def7ec; color: #03543f; }
+.url { display: flex; gap: 4px; margin-top: 4px; }
+.url input { flex: 1; min-width: 0; font-size: 12px; padding: 2px 4px; border: 1px solid #ccc; border-radius: 4px; }
+.url button { font-size: 12px; cursor: pointer; }
+</style>
+</head>
+<body>
+<h1>素材交付报告</h1>
+<div class="summary">
+<div><span>任务：</span>{{.JobID}}</div>
+{{if .Account}}<div><span>账号：</span>{{.Account}}</div>{{end}}
+{{if .MediaList}}<div><span>投放媒体：</span>{{join .MediaList "、"}}</div>{{end}}
+{{if .CategoryList}}<div><span>素材品类：</span>{{join .CategoryList "、"}}</div>{{end}}
+{{if .ReleaseCopy}}<div><span>投放文案：</span>{{.ReleaseCopy}}</div>{{end}}
+{{if .Campaign}}<div><span>活动：</span>{{.Campaign}}</div>{{end}}
+<div><span>素材：</span>共 {{.Total}} 个，已提交 {{.Submitted}} 个，审核通过 {{.Approved}} 个</div>
+<div><span>推送时间：</span>{{time .CreatedAt}}　<span>报告生成：</span>{{time .GeneratedAt}}</div>
+</div>
+<div class="grid">
+{{range .Items}}<div class="card">
+<div class="thumb">{{if thumb .}}<img src="{{thumb .}}" alt="{{.MaterialName}}">{{else if and (image .) .URL}}<img src="{{.URL}}" alt="{{.MaterialName}}">{{else}}{{.Type}}{{end}}</div>
+<div class="info">
+<div class="name">{{.MaterialName}}</div>
+{{if ne .FileName .MaterialName}}<div>文件：{{.FileName}}</div>{{end}}
+<div>{{.Type}} · {{size .FileSize}}{{with meta .}} · {{.}}{{end}}</div>
+<div><span class="badge {{.SubmitStatus}}">{{status .SubmitStatus}}</span>{{if .ApprovalStatus}}<span class="badge {{.ApprovalStatus}}">{{status .ApprovalStatus}}</span>{{end}}</div>
+{{if .BatchUUID}}<div>批次：{{.BatchUUID}}</div>{{end}}
+{{if .SubmitMessage}}<div>提交信息：{{.SubmitMessage}}</div>{{end}}
+{{if .ApprovalNote}}<div>审核意见：{{.ApprovalNote}}</div>{{end}}
+{{if .URL}}<div class="url"><input readonly value="{{.URL}}"><button type="button" onclick="copyURL(this)">复制</button></div>{{end}}
+{{if .LocalURL}}<div class="url"><input readonly value="{{.LocalURL}}"><button type="button" onclick="copyURL(this)">复制</button></div>{{end}}
+</div>
+</div>
+{{end}}</div>
+<script>
+function copyURL(btn) {
+  var input = btn.previousElementSibling;
+  input.select();
+  var done = function () { btn.textContent = "已复制"; setTimeout(function () { btn.textContent = "复制"; }, 1500); };
+  if (navigator.clipboard && window.isSecureContext) {
+    navigator.clipboard.writeText(input.value).then(done);
+  } else {
+    document.execCommand("copy");
+    done();
+  }
+}
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,225 @@
+package report
+
+import (
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"jd_material_push/internal/ledger"
+	"jd_material_push/internal/media"
+)
+
+// 报告格式
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+	FormatHTML = "html"
+)
+
+// Report 一个任务的交付报告，可作为已交付素材的凭证发给客户
+type Report struct {
+	JobID        string    `json:"jobId"`
+	Account      string    `json:"account"`
+	MediaList    []string  `json:"mediaList"`    // 投放媒体名称
+	CategoryList []string  `json:"categoryList"` // 素材品类名称
+	ReleaseCopy  string    `json:"releaseCopy"`
+	Campaign     string    `json:"campaign,omitempty"`
+	Total        int       `json:"total"`     // 素材数
+	Submitted    int       `json:"submitted"` // 提交成功数
+	Approved     int       `json:"approved"`  // 审核通过数
+	CreatedAt    time.Time `json:"createdAt"`
+	GeneratedAt  time.Time `json:"generatedAt"`
+	Items        []Item    `json:"items"`
+}
+
+// Item 报告中的一个素材
+type Item struct {
+	FileName       string  `json:"fileName"`
+	MaterialName   string  `json:"materialName"`
+	FileSize       int64   `json:"fileSize"`
+	Type           string  `json:"type"` // 图片、视频
+	Width          int     `json:"width,omitempty"`
+	Height         int     `json:"height,omitempty"`
+	Duration       float64 `json:"duration,omitempty"`
+	URL            string  `json:"url"`
+	LocalURL       string  `json:"localUrl"`
+	BatchUUID      string  `json:"batchUuid"`
+	SubmitStatus   string  `json:"submitStatus"`
+	SubmitMessage  string  `json:"submitMessage"`
+	ApprovalStatus string  `json:"approvalStatus"`
+	ApprovalNote   string  `json:"approvalNote,omitempty"`
+
+	thumbnailPath string
+	thumbnail     string // 内嵌的缩略图（data URI），仅用于 HTML
+}
+
+// New 由台账任务生成报告，mediaList、categoryList 为投放媒体和品类的显示名称，thumbnailDir 为上传时保存缩略图的目录
+func New(job ledger.Job, mediaList, categoryList []string, thumbnailDir string) Report {
+	r := Report{
+		JobID:        job.ID,
+		Account:      job.Account,
+		MediaList:    mediaList,
+		CategoryList: categoryList,
+		ReleaseCopy:  job.ReleaseCopy,
+		Campaign:     job.Campaign,
+		Total:        len(job.Materials),
+		CreatedAt:    job.CreatedAt,
+		GeneratedAt:  time.Now(),
+		Items:        []Item{},
+	}
+	for _, rec := range job.Materials {
+		item := Item{
+			FileName:       rec.FileName,
+			MaterialName:   rec.MaterialName,
+			FileSize:       rec.FileSize,
+			Type:           typeName(rec.MaterialType),
+			Width:          rec.Width,
+			Height:         rec.Height,
+			Duration:       rec.Duration,
+			URL:            rec.URL,
+			LocalURL:       rec.LocalURL,
+			BatchUUID:      rec.BatchUUID,
+			SubmitStatus:   rec.SubmitStatus,
+			SubmitMessage:  rec.Message,
+			ApprovalStatus: rec.ApprovalStatus,
+			ApprovalNote:   rec.ApprovalNote,
+		}
+		if item.MaterialName == "" {
+			item.MaterialName = rec.FileName
+		}
+		if rec.Thumbnail != "" {
+			item.thumbnailPath = filepath.Join(thumbnailDir, rec.Thumbnail)
+		}
+		switch rec.SubmitStatus {
+		case ledger.SubmitStatusSubmitted, ledger.SubmitStatusWithdrawFailed:
+			r.Submitted++
+		}
+		if rec.ApprovalStatus == ledger.ApprovalStatusApproved {
+			r.Approved++
+		}
+		r.Items = append(r.Items, item)
+	}
+	return r
+}
+
+// Write 按格式写出报告
+func Write(w io.Writer, r Report, format string) error {
+	switch format {
+	case FormatCSV:
+		return writeCSV(w, r)
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r)
+	case FormatHTML:
+		return writeHTML(w, r)
+	}
+	return fmt.Errorf("报告格式有误: %s（可选 csv、json、html）", format)
+}
+
+// ContentType 报告格式对应的 Content-Type
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatJSON:
+		return "application/json; charset=utf-8"
+	}
+	return "text/html; charset=utf-8"
+}
+
+// writeCSV 写出 CSV，带 UTF-8 BOM 以便 Excel 正确显示中文
+func writeCSV(w io.Writer, r Report) error {
+	if _, err := io.WriteString(w, "\ufeff"); err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"文件名", "素材名称", "大小（字节）", "类型", "宽", "高", "时长（秒）", "URL", "LocalURL", "批次", "提交状态", "提交信息", "审核状态", "审核意见"})
+	for _, item := range r.Items {
+		_ = cw.Write([]string{
+			item.FileName,
+			item.MaterialName,
+			strconv.FormatInt(item.FileSize, 10),
+			item.Type,
+			intText(item.Width),
+			intText(item.Height),
+			durationText(item.Duration),
+			item.URL,
+			item.LocalURL,
+			item.BatchUUID,
+			StatusText(item.SubmitStatus),
+			item.SubmitMessage,
+			StatusText(item.ApprovalStatus),
+			item.ApprovalNote,
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// StatusText 提交和审核状态的显示名称
+func StatusText(status string) string {
+	switch status {
+	case ledger.SubmitStatusNone:
+		return "未提交"
+	case ledger.SubmitStatusSubmitted:
+		return "已提交"
+	case ledger.SubmitStatusFailed:
+		return "提交失败"
+	case ledger.SubmitStatusHeld:
+		return "暂缓提交"
+	case ledger.SubmitStatusWithdrawn:
+		return "已撤回"
+	case ledger.SubmitStatusWithdrawFailed:
+		return "撤回失败"
+	case ledger.ApprovalStatusPending:
+		return "待审核"
+	case ledger.ApprovalStatusApproved:
+		return "审核通过"
+	case ledger.ApprovalStatusRejected:
+		return "审核驳回"
+	}
+	return status
+}
+
+func typeName(materialType int) string {
+	switch materialType {
+	case media.MaterialTypeImage:
+		return "图片"
+	case media.MaterialTypeVideo:
+		return "视频"
+	}
+	return strconv.Itoa(materialType)
+}
+
+func intText(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
+
+func durationText(d float64) string {
+	if d == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(d, 'f', 1, 64)
+}
+
+// thumbnailURI 读取上传时生成的缩略图，转为内嵌的 data URI；没有缩略图或文件已删除时返回空
+func thumbnailURI(path string) string {
+	if path == "" {
+		return ""
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return "data:image/jpeg;base64," + base64.StdEncoding.EncodeToString(data)
+}
@@ -0,0 +1,266 @@
+package source
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// zipSource .zip 压缩包，条目可随机访问
+type zipSource struct {
+	location string
+	reader   *zip.ReadCloser
+	files    map[string]*zip.File
+	entries  []Entry
+	ignore   []byte
+}
+
+func openZip(location string) (*zipSource, error) {
+	reader, err := zip.OpenReader(location)
+	if err != nil {
+		return nil, fmt.Errorf("打开压缩包失败: %w", err)
+	}
+
+	s := &zipSource{location: location, reader: reader, files: make(map[string]*zip.File)}
+	for _, f := range reader.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		name := cleanPath(zipName(f))
+		if name == IgnoreFile {
+			if err := s.readIgnore(f); err != nil {
+				reader.Close()
+				return nil, err
+			}
+			continue
+		}
+		if _, dup := s.files[name]; dup || name == "" || skip(name) {
+			continue
+		}
+		s.files[name] = f
+		s.entries = append(s.entries, Entry{Path: name, Size: int64(f.UncompressedSize64), ModTime: f.Modified})
+	}
+	sortEntries(s.entries)
+	return s, nil
+}
+
+// zipName 解码未标记 UTF-8 的文件名：Windows 中文系统压缩的文件名为 GBK，macOS 压缩的 UTF-8 文件名也不带标记；
+// GBK 字节有时恰好也是合法 UTF-8（解出的是变音符、希伯来文等），因此只在 UTF-8 解读不像常见文字时才按 GBK 解码
+func zipName(f *zip.File) string {
+	if !f.NonUTF8 || (utf8.ValidString(f.Name) && plausibleText(f.Name)) {
+		return f.Name
+	}
+	decoded, err := simplifiedchinese.GBK.NewDecoder().String(f.Name)
+	if err != nil || !plausibleText(decoded) {
+		return f.Name
+	}
+	return decoded
+}
+
+// plausibleText 非 ASCII 字符是否都是汉字、拉丁字母、假名、谚文或标点
+func plausibleText(s string) bool {
+	for _, r := range s {
+		if r < utf8.RuneSelf {
+			continue
+		}
+		if r == utf8.RuneError || !unicode.In(r, unicode.Han, unicode.Latin, unicode.Hiragana, unicode.Katakana, unicode.Hangul, unicode.P, unicode.Zs) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *zipSource) Location() string   { return s.location }
+func (s *zipSource) Entries() []Entry   { return s.entries }
+func (s *zipSource) Close() error       { return s.reader.Close() }
+func (s *zipSource) IgnoreFile() []byte { return s.ignore }
+
+func (s *zipSource) readIgnore(f *zip.File) error {
+	rc, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("读取 %s 失败: %w", IgnoreFile, err)
+	}
+	defer rc.Close()
+	s.ignore, err = io.ReadAll(io.LimitReader(rc, ignoreFileLimit))
+	if err != nil {
+		return fmt.Errorf("读取 %s 失败: %w", IgnoreFile, err)
+	}
+	return nil
+}
+
+func (s *zipSource) Open(entry Entry) (io.ReadCloser, error) {
+	f, ok := s.files[entry.Path]
+	if !ok {
+		return nil, fmt.Errorf("压缩包中不存在: %s", entry.Path)
+	}
+	return f.Open()
+}
+
+func (s *zipSource) LocalPath(Entry) (string, bool) { return "", false }
+
+// tarGzSource .tar.gz 压缩包；tar 只能顺序读取，打开时只扫描一遍列出条目。条目都以流的方式读取，不解压到磁盘：
+// Open 每次从头解压到该文件，读取多个文件时用 Walk 按压缩包中的顺序一次读完
+type tarGzSource struct {
+	location string
+	entries  []Entry
+	ignore   []byte
+}
+
+func openTarGz(location string) (*tarGzSource, error) {
+	s := &tarGzSource{location: location}
+	seen := make(map[string]bool)
+	err := s.scan(func(name string, hdr *tar.Header, r io.Reader) error {
+		if name == IgnoreFile {
+			data, err := io.ReadAll(io.LimitReader(r, ignoreFileLimit))
+			if err != nil {
+				return fmt.Errorf("读取 %s 失败: %w", IgnoreFile, err)
+			}
+			s.ignore = data
+			return nil
+		}
+		// 同名条目只保留第一个，与 .zip 一致
+		if seen[name] {
+			return nil
+		}
+		seen[name] = true
+		s.entries = append(s.entries, Entry{Path: name, Size: hdr.Size, ModTime: hdr.ModTime})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sortEntries(s.entries)
+	return s, nil
+}
+
+// tarStream 从头打开的压缩包
+type tarStream struct {
+	*tar.Reader
+	file *os.File
+	gz   *gzip.Reader
+}
+
+func (s *tarGzSource) open() (*tarStream, error) {
+	f, err := os.Open(s.location)
+	if err != nil {
+		return nil, err
+	}
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("打开压缩包失败: %w", err)
+	}
+	return &tarStream{Reader: tar.NewReader(gz), file: f, gz: gz}, nil
+}
+
+// next 跳到下一个普通文件，已到末尾时返回 io.EOF
+func (t *tarStream) next() (string, *tar.Header, error) {
+	for {
+		hdr, err := t.Next()
+		if err == io.EOF {
+			return "", nil, err
+		}
+		if err != nil {
+			return "", nil, fmt.Errorf("读取压缩包失败: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		name := cleanPath(hdr.Name)
+		if name == "" || (skip(name) && name != IgnoreFile) {
+			continue
+		}
+		return name, hdr, nil
+	}
+}
+
+func (t *tarStream) Close() error {
+	t.gz.Close()
+	return t.file.Close()
+}
+
+// scan 顺序遍历普通文件
+func (s *tarGzSource) scan(fn func(name string, hdr *tar.Header, r io.Reader) error) error {
+	t, err := s.open()
+	if err != nil {
+		return err
+	}
+	defer t.Close()
+
+	for {
+		name, hdr, err := t.next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(name, hdr, t); err != nil {
+			return err
+		}
+	}
+}
+
+// Walk 从头到尾读一遍压缩包，按压缩包中的顺序对 entries 中的每个文件调用 fn
+func (s *tarGzSource) Walk(entries []Entry, fn func(entry Entry, r io.Reader) error) error {
+	wanted := make(map[string]Entry, len(entries))
+	for _, entry := range entries {
+		wanted[entry.Path] = entry
+	}
+	return s.scan(func(name string, _ *tar.Header, r io.Reader) error {
+		entry, ok := wanted[name]
+		if !ok {
+			return nil
+		}
+		// 同名条目只读第一个
+		delete(wanted, name)
+		return fn(entry, r)
+	})
+}
+
+func (s *tarGzSource) Location() string   { return s.location }
+func (s *tarGzSource) Entries() []Entry   { return s.entries }
+func (s *tarGzSource) IgnoreFile() []byte { return s.ignore }
+func (s *tarGzSource) Close() error       { return nil }
+
+// Open 从头解压到该文件后返回，读取的是压缩包的解压流
+func (s *tarGzSource) Open(entry Entry) (io.ReadCloser, error) {
+	t, err := s.open()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		name, _, err := t.next()
+		if err != nil {
+			t.Close()
+			if err == io.EOF {
+				return nil, fmt.Errorf("压缩包中不存在: %s", entry.Path)
+			}
+			return nil, err
+		}
+		if name == entry.Path {
+			return t, nil
+		}
+	}
+}
+
+func (s *tarGzSource) LocalPath(Entry) (string, bool) { return "", false }
+
+// cleanPath 规范化压缩包内路径，拒绝绝对路径和 .. 越界
+func cleanPath(name string) string {
+	name = strings.ReplaceAll(name, `\`, "/")
+	cleaned := path.Clean("/" + name)[1:]
+	if cleaned == "" || cleaned == "." {
+		return ""
+	}
+	return cleaned
+}
@@ -0,0 +1,86 @@
+package source
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeTarGz 按顺序写入压缩包，允许同名条目
+func writeTarGz(t *testing.T, files [][2]string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "x.tar.gz")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for _, file := range files {
+		tw.WriteHeader(&tar.Header{Name: file[0], Mode: 0644, Size: int64(len(file[1])), Typeflag: tar.TypeReg})
+		tw.Write([]byte(file[1]))
+	}
+	tw.Close()
+	gz.Close()
+	return path
+}
+
+func TestTarGzWalk(t *testing.T) {
+	path := writeTarGz(t, [][2]string{
+		{"b.jpg", "b1"},
+		{"女装/a.jpg", "a"},
+		{".hidden.jpg", "h"},
+		{"b.jpg", "b2"},
+		{"c.jpg", "c"},
+	})
+	src, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer src.Close()
+
+	var paths []string
+	for _, entry := range src.Entries() {
+		paths = append(paths, entry.Path)
+	}
+	if want := []string{"b.jpg", "c.jpg", "女装/a.jpg"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("条目 = %v，应为 %v", paths, want)
+	}
+
+	walker, ok := src.(Walker)
+	if !ok {
+		t.Fatal(".tar.gz 应支持 Walk")
+	}
+	entries := src.Entries()
+	var got [][2]string
+	err = walker.Walk([]Entry{entries[2], entries[0]}, func(entry Entry, r io.Reader) error {
+		data, err := io.ReadAll(r)
+		got = append(got, [2]string{entry.Path, string(data)})
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 按压缩包中的顺序读取，同名条目只读第一个
+	if want := [][2]string{{"b.jpg", "b1"}, {"女装/a.jpg", "a"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Walk 读到 %v，应为 %v", got, want)
+	}
+
+	rc, err := src.Open(entries[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(rc)
+	rc.Close()
+	if string(data) != "c" {
+		t.Errorf("Open 读到 %q，应为 %q", data, "c")
+	}
+	if _, err := src.Open(Entry{Path: "nosuch.jpg"}); err == nil {
+		t.Error("打开不存在的文件应返回错误")
+	}
+}
@@ -0,0 +1,195 @@
+package source
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// IgnoreFile 上传源根目录下的忽略规则文件，语法同 .gitignore
+const IgnoreFile = ".pushignore"
+
+// ignoreFileLimit .pushignore 的最大字节数
+const ignoreFileLimit = 1 << 20
+
+// builtinIgnore 始终跳过的系统文件，可在 .pushignore 中用 ! 重新包含
+var builtinIgnore = []string{"Thumbs.db", "desktop.ini"}
+
+// Filter 方案中的文件筛选设置，先于 .pushignore 生效
+type Filter struct {
+	Profile    string   // 方案名，用于说明跳过原因
+	Ignore     []string // 忽略规则，语法同 .gitignore
+	Extensions []string // 允许上传的扩展名（如 .jpg），为空时不限制
+}
+
+// Skipped 被规则跳过的文件
+type Skipped struct {
+	Path string // 源内的相对路径
+	Rule string // 命中的规则，如 .pushignore:3 *.psd
+}
+
+// Select 按内置规则、方案设置和源中的 .pushignore 筛选待上传的文件，返回保留的文件和被跳过的文件；规则有误时返回错误。
+// 素材的附带文案（同名 .txt）在提交时作为投放文案使用，本身不是素材，总是跳过并列出
+func Select(src Source, f Filter) ([]Entry, []Skipped, error) {
+	rules, err := buildRules(src, f)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	allowed := make(map[string]bool, len(f.Extensions))
+	for _, ext := range f.Extensions {
+		ext = strings.ToLower(strings.TrimSpace(ext))
+		if ext != "" && !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		allowed[ext] = true
+	}
+
+	sidecarOf := make(map[string]string)
+	for material, text := range Sidecars(src.Entries()) {
+		sidecarOf[text.Path] = material
+	}
+
+	var kept []Entry
+	var skipped []Skipped
+	for _, entry := range src.Entries() {
+		if material, ok := sidecarOf[entry.Path]; ok {
+			skipped = append(skipped, Skipped{Path: entry.Path, Rule: "附带文案 " + material})
+			continue
+		}
+		if r := rules.match(entry.Path); r != nil {
+			skipped = append(skipped, Skipped{Path: entry.Path, Rule: r.String()})
+			continue
+		}
+		if len(allowed) > 0 && !allowed[strings.ToLower(path.Ext(entry.Path))] {
+			skipped = append(skipped, Skipped{Path: entry.Path, Rule: fmt.Sprintf("方案 %s 允许的扩展名: %s", f.Profile, strings.Join(f.Extensions, " "))})
+			continue
+		}
+		kept = append(kept, entry)
+	}
+	return kept, skipped, nil
+}
+
+func buildRules(src Source, f Filter) (ruleSet, error) {
+	var rules ruleSet
+	for _, p := range builtinIgnore {
+		rules.add(p, "内置")
+	}
+	for _, p := range f.Ignore {
+		rules.add(p, "方案 "+f.Profile)
+	}
+
+	data := src.IgnoreFile()
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	line := 0
+	for scanner.Scan() {
+		line++
+		rules.add(scanner.Text(), fmt.Sprintf("%s:%d", IgnoreFile, line))
+	}
+
+	for _, r := range rules {
+		for _, seg := range r.segments {
+			if _, err := path.Match(seg, ""); err != nil {
+				return nil, fmt.Errorf("忽略规则有误（%s）: %s", r.origin, r.text)
+			}
+		}
+	}
+	return rules, nil
+}
+
+// ignoreRule 一条 gitignore 风格的规则
+type ignoreRule struct {
+	text     string   // 原始规则
+	origin   string   // 来源，如 .pushignore:3
+	negate   bool     // 以 ! 开头，重新包含
+	dirOnly  bool     // 以 / 结尾，只匹配目录
+	segments []string // 按 / 切分的模式；不含 / 的规则前面补 **，匹配任意层级
+}
+
+func (r ignoreRule) String() string {
+	return r.origin + " " + r.text
+}
+
+type ruleSet []ignoreRule
+
+// add 解析一行规则，忽略空行和 # 注释
+func (rs *ruleSet) add(line, origin string) {
+	text := strings.TrimSpace(line)
+	if text == "" || strings.HasPrefix(text, "#") {
+		return
+	}
+
+	r := ignoreRule{text: text, origin: origin}
+	pattern := text
+	if strings.HasPrefix(pattern, "!") {
+		r.negate = true
+		pattern = pattern[1:]
+	}
+	if strings.HasSuffix(pattern, "/") {
+		r.dirOnly = true
+		pattern = strings.TrimRight(pattern, "/")
+	}
+	anchored := strings.Contains(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+	if pattern == "" {
+		return
+	}
+	if !anchored {
+		pattern = "**/" + pattern
+	}
+	r.segments = strings.Split(pattern, "/")
+	*rs = append(*rs, r)
+}
+
+// match 返回使文件被跳过的规则，未被跳过时返回 nil；任一层目录被跳过时其中的文件都跳过，同一路径以最后命中的规则为准
+func (rs ruleSet) match(p string) *ignoreRule {
+	parts := strings.Split(p, "/")
+	for i := 1; i < len(parts); i++ {
+		if r := rs.last(parts[:i], true); r != nil && !r.negate {
+			return r
+		}
+	}
+	if r := rs.last(parts, false); r != nil && !r.negate {
+		return r
+	}
+	return nil
+}
+
+// last 最后一条匹配该路径的规则
+func (rs ruleSet) last(parts []string, isDir bool) *ignoreRule {
+	var matched *ignoreRule
+	for i := range rs {
+		r := &rs[i]
+		if r.dirOnly && !isDir {
+			continue
+		}
+		if matchSegments(r.segments, parts) {
+			matched = r
+		}
+	}
+	return matched
+}
+
+// matchSegments 逐段匹配路径，** 匹配任意层（含零层）
+func matchSegments(pattern, parts []string) bool {
+	if len(pattern) == 0 {
+		return len(parts) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(parts); i++ {
+			if matchSegments(pattern[1:], parts[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(parts) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(pattern[0], parts[0]); !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], parts[1:])
+}
@@ -0,0 +1,138 @@
+package source
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// memSource 只有文件列表和 .pushignore 的上传源
+type memSource struct {
+	paths  []string
+	ignore string
+}
+
+func (s memSource) Location() string { return "mem" }
+
+func (s memSource) Entries() []Entry {
+	entries := make([]Entry, 0, len(s.paths))
+	for _, p := range s.paths {
+		entries = append(entries, Entry{Path: p})
+	}
+	return entries
+}
+
+func (s memSource) Open(entry Entry) (io.ReadCloser, error) {
+	return io.NopCloser(strings.NewReader("")), nil
+}
+
+func (s memSource) LocalPath(entry Entry) (string, bool) { return "", false }
+
+func (s memSource) IgnoreFile() []byte {
+	if s.ignore == "" {
+		return nil
+	}
+	return []byte(s.ignore)
+}
+
+func (s memSource) Close() error { return nil }
+
+func TestSelect(t *testing.T) {
+	tests := []struct {
+		name    string
+		paths   []string
+		ignore  string
+		filter  Filter
+		skipped map[string]string // 被跳过的文件及命中的规则
+	}{
+		{
+			name:    "不含 / 的规则匹配任意层级",
+			paths:   []string{"a.jpg", "a.psd", "sub/b.psd"},
+			ignore:  "# 源文件\n*.psd\n",
+			skipped: map[string]string{"a.psd": ".pushignore:2 *.psd", "sub/b.psd": ".pushignore:2 *.psd"},
+		},
+		{
+			name:    "! 重新包含",
+			paths:   []string{"keep.jpg", "x.jpg"},
+			ignore:  "*.jpg\n!keep.jpg",
+			skipped: map[string]string{"x.jpg": ".pushignore:1 *.jpg"},
+		},
+		{
+			name:    "以 / 结尾只匹配目录",
+			paths:   []string{"draft", "draft/a.jpg", "sub/draft/b.jpg"},
+			ignore:  "draft/",
+			skipped: map[string]string{"draft/a.jpg": ".pushignore:1 draft/", "sub/draft/b.jpg": ".pushignore:1 draft/"},
+		},
+		{
+			name:    "目录被跳过时不能重新包含其中的文件",
+			paths:   []string{"draft/keep.jpg"},
+			ignore:  "draft/\n!draft/keep.jpg",
+			skipped: map[string]string{"draft/keep.jpg": ".pushignore:1 draft/"},
+		},
+		{
+			name:    "以 / 开头只匹配根目录",
+			paths:   []string{"top.jpg", "sub/top.jpg"},
+			ignore:  "/top.jpg",
+			skipped: map[string]string{"top.jpg": ".pushignore:1 /top.jpg"},
+		},
+		{
+			name:   "** 匹配任意层（含零层）",
+			paths:  []string{"assets/raw/a.jpg", "assets/x/y/raw/b.jpg", "assets/raw.jpg", "other/raw/c.jpg"},
+			ignore: "assets/**/raw/*.jpg",
+			skipped: map[string]string{
+				"assets/raw/a.jpg":     ".pushignore:1 assets/**/raw/*.jpg",
+				"assets/x/y/raw/b.jpg": ".pushignore:1 assets/**/raw/*.jpg",
+			},
+		},
+		{
+			name:    "内置规则可重新包含",
+			paths:   []string{"Thumbs.db", "sub/desktop.ini"},
+			ignore:  "!Thumbs.db",
+			skipped: map[string]string{"sub/desktop.ini": "内置 desktop.ini"},
+		},
+		{
+			name:    "方案规则先于 .pushignore",
+			paths:   []string{"a.psd", "b.psd"},
+			ignore:  "!b.psd",
+			filter:  Filter{Profile: "default", Ignore: []string{"*.psd"}},
+			skipped: map[string]string{"a.psd": "方案 default *.psd"},
+		},
+		{
+			name:    "方案限定扩展名",
+			paths:   []string{"a.JPG", "b.png", "c.mp4"},
+			filter:  Filter{Profile: "img", Extensions: []string{"jpg", ".png"}},
+			skipped: map[string]string{"c.mp4": "方案 img 允许的扩展名: jpg .png"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kept, skipped, err := Select(memSource{paths: tt.paths, ignore: tt.ignore}, tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := make(map[string]string, len(skipped))
+			for _, s := range skipped {
+				got[s.Path] = s.Rule
+			}
+			if len(got) == 0 {
+				got = nil
+			}
+			if len(tt.skipped) == 0 {
+				tt.skipped = nil
+			}
+			if !reflect.DeepEqual(got, tt.skipped) {
+				t.Errorf("跳过 %v，应为 %v", got, tt.skipped)
+			}
+			if len(kept)+len(skipped) != len(tt.paths) {
+				t.Errorf("保留 %d 个、跳过 %d 个，共应为 %d 个", len(kept), len(skipped), len(tt.paths))
+			}
+		})
+	}
+}
+
+func TestSelectInvalidRule(t *testing.T) {
+	if _, _, err := Select(memSource{paths: []string{"a.jpg"}, ignore: "[abc"}, Filter{}); err == nil {
+		t.Error("规则有误时应返回错误")
+	}
+}
@@ -0,0 +1,88 @@
+package source
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// 上传顺序：决定文件的上传、提交分批和在平台上的先后顺序
+const (
+	OrderName    = "name"    // 按路径排序，与扫描顺序一致
+	OrderNatural = "natural" // 按路径排序，其中的数字按数值比较，如 2.jpg 在 10.jpg 之前
+	OrderMtime   = "mtime"   // 按修改时间从早到晚
+	OrderSize    = "size"    // 按大小从小到大
+)
+
+// Less 返回按指定顺序比较两个文件的函数；修改时间或大小相同时按自然顺序比较路径，保证结果确定
+func Less(order string) (func(a, b Entry) bool, error) {
+	switch order {
+	case "", OrderName:
+		return func(a, b Entry) bool { return a.Path < b.Path }, nil
+	case OrderNatural:
+		return func(a, b Entry) bool { return NaturalLess(a.Path, b.Path) }, nil
+	case OrderMtime:
+		return func(a, b Entry) bool {
+			if !a.ModTime.Equal(b.ModTime) {
+				return a.ModTime.Before(b.ModTime)
+			}
+			return NaturalLess(a.Path, b.Path)
+		}, nil
+	case OrderSize:
+		return func(a, b Entry) bool {
+			if a.Size != b.Size {
+				return a.Size < b.Size
+			}
+			return NaturalLess(a.Path, b.Path)
+		}, nil
+	}
+	return nil, fmt.Errorf("不支持的上传顺序: %s（可选 name、natural、mtime、size）", order)
+}
+
+// Sorted 返回按指定顺序排序后的副本，不修改原切片
+func Sorted(entries []Entry, order string) ([]Entry, error) {
+	less, err := Less(order)
+	if err != nil {
+		return nil, err
+	}
+	sorted := append([]Entry(nil), entries...)
+	sort.SliceStable(sorted, func(i, j int) bool { return less(sorted[i], sorted[j]) })
+	return sorted, nil
+}
+
+// NaturalLess 自然顺序比较：连续的数字按数值比较，其余部分按字节比较；数值相同时前导零少的在前
+func NaturalLess(a, b string) bool {
+	for a != "" && b != "" {
+		ca, cb := leadingChunk(a), leadingChunk(b)
+		a, b = a[len(ca):], b[len(cb):]
+		if ca == cb {
+			continue
+		}
+		if !isDigit(ca[0]) || !isDigit(cb[0]) {
+			return ca < cb
+		}
+		na, nb := strings.TrimLeft(ca, "0"), strings.TrimLeft(cb, "0")
+		if len(na) != len(nb) {
+			return len(na) < len(nb)
+		}
+		if na != nb {
+			return na < nb
+		}
+		return len(ca) < len(cb)
+	}
+	return a == "" && b != ""
+}
+
+// leadingChunk 开头连续的数字或连续的非数字
+func leadingChunk(s string) string {
+	digit := isDigit(s[0])
+	i := 1
+	for i < len(s) && isDigit(s[i]) == digit {
+		i++
+	}
+	return s[:i]
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
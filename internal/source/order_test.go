@@ -0,0 +1,67 @@
+package source
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNaturalLess(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"2.jpg", "10.jpg", true},
+		{"10.jpg", "2.jpg", false},
+		{"img2.jpg", "img10.jpg", true},
+		{"a10b2.jpg", "a10b10.jpg", true},
+		{"02.jpg", "2.jpg", false},
+		{"2.jpg", "02.jpg", true},
+		{"a.jpg", "b.jpg", true},
+		{"a", "a1", true},
+		{"a1", "a1", false},
+		{"99999999999999999999.jpg", "100000000000000000000.jpg", true},
+	}
+	for _, tt := range tests {
+		if got := NaturalLess(tt.a, tt.b); got != tt.want {
+			t.Errorf("NaturalLess(%q, %q) = %v，应为 %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestSorted(t *testing.T) {
+	base := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	entries := []Entry{
+		{Path: "10.jpg", Size: 300, ModTime: base},
+		{Path: "2.jpg", Size: 100, ModTime: base.Add(time.Hour)},
+		{Path: "1.jpg", Size: 300, ModTime: base},
+	}
+	tests := []struct {
+		order string
+		want  []string
+	}{
+		{OrderName, []string{"1.jpg", "10.jpg", "2.jpg"}},
+		{OrderNatural, []string{"1.jpg", "2.jpg", "10.jpg"}},
+		{OrderMtime, []string{"1.jpg", "10.jpg", "2.jpg"}},
+		{OrderSize, []string{"2.jpg", "1.jpg", "10.jpg"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.order, func(t *testing.T) {
+			sorted, err := Sorted(entries, tt.order)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, e := range sorted {
+				got = append(got, e.Path)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Sorted(%s) = %q，应为 %q", tt.order, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := Sorted(entries, "random"); err == nil {
+		t.Error("不支持的顺序应返回错误")
+	}
+}
@@ -0,0 +1,75 @@
+package source
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// SidecarExt 附带文案文件的扩展名：与素材同目录、同名（不含扩展名）的 .txt 为该素材的附带文案，
+// 提交时作为该素材的投放文案，本身不作为素材上传
+const SidecarExt = ".txt"
+
+// sidecarLimit 附带文案读取的最大字节数
+const sidecarLimit = 64 << 10
+
+// Sidecars 返回素材路径 → 附带文案文件
+func Sidecars(entries []Entry) map[string]Entry {
+	texts := make(map[string]Entry)
+	for _, e := range entries {
+		if strings.EqualFold(path.Ext(e.Path), SidecarExt) {
+			texts[strings.ToLower(stemPath(e.Path))] = e
+		}
+	}
+
+	sidecars := make(map[string]Entry)
+	if len(texts) == 0 {
+		return sidecars
+	}
+	for _, e := range entries {
+		if strings.EqualFold(path.Ext(e.Path), SidecarExt) {
+			continue
+		}
+		if text, ok := texts[strings.ToLower(stemPath(e.Path))]; ok {
+			sidecars[e.Path] = text
+		}
+	}
+	return sidecars
+}
+
+// stemPath 去掉扩展名的路径
+func stemPath(p string) string {
+	return strings.TrimSuffix(p, path.Ext(p))
+}
+
+// ReadSidecar 读取附带文案，去掉 BOM 和首尾空白；Windows 记事本保存的 GBK 文本自动转为 UTF-8
+func ReadSidecar(src Source, sidecar Entry) (string, error) {
+	r, err := src.Open(sidecar)
+	if err != nil {
+		return "", fmt.Errorf("读取附带文案 %s 失败: %w", sidecar.Path, err)
+	}
+	defer r.Close()
+	return ParseSidecar(sidecar, r)
+}
+
+// ParseSidecar 同 ReadSidecar，从已打开的附带文案读取
+func ParseSidecar(sidecar Entry, r io.Reader) (string, error) {
+	data, err := io.ReadAll(io.LimitReader(r, sidecarLimit))
+	if err != nil {
+		return "", fmt.Errorf("读取附带文案 %s 失败: %w", sidecar.Path, err)
+	}
+
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	text := string(data)
+	if !utf8.Valid(data) {
+		if decoded, err := simplifiedchinese.GBK.NewDecoder().String(text); err == nil {
+			text = decoded
+		}
+	}
+	return strings.TrimSpace(text), nil
+}
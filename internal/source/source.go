@@ -0,0 +1,201 @@
+package source
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"jd_material_push/internal/media"
+)
+
+// Entry 上传源中的一个文件
+type Entry struct {
+	Path    string // 源内的相对路径，以 / 分隔，如 女装/a.jpg；文件夹源只有文件名
+	Size    int64
+	ModTime time.Time
+}
+
+// Name 文件名（不含目录）
+func (e Entry) Name() string {
+	return path.Base(e.Path)
+}
+
+// Dir 所在的子目录，顶层文件为空
+func (e Entry) Dir() string {
+	if dir := path.Dir(e.Path); dir != "." {
+		return dir
+	}
+	return ""
+}
+
+// Source 待上传文件的来源：文件夹或压缩包
+type Source interface {
+	// Location 源路径
+	Location() string
+	// Entries 全部待上传的文件，已跳过目录和隐藏文件，按路径排序
+	Entries() []Entry
+	// Open 打开一个文件；压缩包中的文件以流的方式读取，不解压到磁盘，.tar.gz 每次打开都从头解压到该文件
+	Open(entry Entry) (io.ReadCloser, error)
+	// LocalPath 文件在磁盘上的路径，压缩包中的文件返回 false
+	LocalPath(entry Entry) (string, bool)
+	// IgnoreFile 源根目录下 .pushignore 的内容，不存在时为 nil
+	IgnoreFile() []byte
+	Close() error
+}
+
+// Walker 只能顺序读取的上传源（.tar.gz）：逐个 Open 每次都要从头解压，读取多个文件时用 Walk 一次读完
+type Walker interface {
+	Source
+	// Walk 从头到尾读一遍，按源中的顺序对 entries 中的每个文件调用 fn；r 只在 fn 返回前有效，fn 返回错误时停止
+	Walk(entries []Entry, fn func(entry Entry, r io.Reader) error) error
+}
+
+// Open 按路径打开上传源：文件夹、.zip、.tar.gz/.tgz
+func Open(location string) (Source, error) {
+	info, err := os.Stat(location)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return openDir(location)
+	}
+
+	switch {
+	case IsZip(location):
+		return openZip(location)
+	case IsTarGz(location):
+		return openTarGz(location)
+	}
+	return nil, fmt.Errorf("不支持的上传源: %s（仅支持文件夹、.zip、.tar.gz）", filepath.Base(location))
+}
+
+// IsArchive 路径是否为支持的压缩包
+func IsArchive(location string) bool {
+	return IsZip(location) || IsTarGz(location)
+}
+
+// IsZip 是否为 .zip
+func IsZip(location string) bool {
+	return strings.HasSuffix(strings.ToLower(location), ".zip")
+}
+
+// IsTarGz 是否为 .tar.gz 或 .tgz
+func IsTarGz(location string) bool {
+	lower := strings.ToLower(location)
+	return strings.HasSuffix(lower, ".tar.gz") || strings.HasSuffix(lower, ".tgz")
+}
+
+// Probe 识别文件类型并读取元数据；磁盘上的文件随机读取，压缩包中的文件顺序读取，不整个读入内存。
+// 类型识别失败时 TypeInfo 为空，只有元数据读取失败时 TypeInfo 有效
+func Probe(src Source, entry Entry) (media.TypeInfo, media.Metadata, error) {
+	if p, ok := src.LocalPath(entry); ok {
+		return media.Probe(p)
+	}
+	rc, err := src.Open(entry)
+	if err != nil {
+		return media.TypeInfo{}, media.Metadata{}, err
+	}
+	defer rc.Close()
+	return media.ProbeStream(rc)
+}
+
+// Materialize 返回文件在磁盘上的路径；压缩包中的文件写入 dir 后返回副本路径（供需要文件路径的处理步骤使用）
+func Materialize(src Source, entry Entry, dir string) (string, error) {
+	if p, ok := src.LocalPath(entry); ok {
+		return p, nil
+	}
+
+	rc, err := src.Open(entry)
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+	return MaterializeFrom(entry, rc, dir)
+}
+
+// MaterializeFrom 同 Materialize，将已打开的文件内容写入 dir
+func MaterializeFrom(entry Entry, r io.Reader, dir string) (string, error) {
+	target := filepath.Join(dir, entry.Name())
+	f, err := os.Create(target)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return "", fmt.Errorf("读取 %s 失败: %w", entry.Path, err)
+	}
+	return target, f.Close()
+}
+
+// limitIgnore 只保留 .pushignore 的前 1MB
+func limitIgnore(data []byte) []byte {
+	if len(data) > ignoreFileLimit {
+		return data[:ignoreFileLimit]
+	}
+	return data
+}
+
+// skip 是否跳过该路径：任一层目录或文件名以 . 开头（如 .DS_Store），或是 macOS 压缩时生成的 __MACOSX
+func skip(p string) bool {
+	for _, part := range strings.Split(p, "/") {
+		if part == "__MACOSX" || (len(part) > 0 && part[0] == '.') {
+			return true
+		}
+	}
+	return false
+}
+
+func sortEntries(entries []Entry) {
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Path < entries[j].Path })
+}
+
+// dirSource 文件夹，只读取第一层文件，与原有行为一致
+type dirSource struct {
+	location string
+	entries  []Entry
+	ignore   []byte
+}
+
+func openDir(location string) (*dirSource, error) {
+	items, err := os.ReadDir(location)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &dirSource{location: location}
+	if data, err := os.ReadFile(filepath.Join(location, IgnoreFile)); err == nil {
+		s.ignore = limitIgnore(data)
+	}
+	for _, item := range items {
+		if item.IsDir() || skip(item.Name()) {
+			continue
+		}
+		info, err := item.Info()
+		if err != nil {
+			// 保留条目，打开时再报告具体错误
+			s.entries = append(s.entries, Entry{Path: item.Name()})
+			continue
+		}
+		s.entries = append(s.entries, Entry{Path: item.Name(), Size: info.Size(), ModTime: info.ModTime()})
+	}
+	sortEntries(s.entries)
+	return s, nil
+}
+
+func (s *dirSource) Location() string   { return s.location }
+func (s *dirSource) Entries() []Entry   { return s.entries }
+func (s *dirSource) Close() error       { return nil }
+func (s *dirSource) IgnoreFile() []byte { return s.ignore }
+
+func (s *dirSource) Open(entry Entry) (io.ReadCloser, error) {
+	return os.Open(filepath.Join(s.location, entry.Path))
+}
+
+func (s *dirSource) LocalPath(entry Entry) (string, bool) {
+	return filepath.Join(s.location, entry.Path), true
+}
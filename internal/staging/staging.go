@@ -0,0 +1,172 @@
+package staging
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// workspacePrefix 暂存目录下每次上传的子目录前缀，启动清理只删除带该前缀的目录
+const workspacePrefix = "upload-"
+
+// ErrQuotaExceeded 超出单次上传或暂存空间总量的上限
+var ErrQuotaExceeded = errors.New("超出暂存空间配额")
+
+// Options 浏览器上传的暂存空间配置
+type Options struct {
+	Dir            string `json:",default=data/staging"` // 暂存目录
+	MaxUploadMB    int64  `json:",default=2048"`         // 单次上传的大小上限
+	MaxTotalMB     int64  `json:",default=10240"`        // 同时进行的上传合计占用上限
+	MaxFiles       int    `json:",default=500"`          // 单次上传的文件数上限
+	TimeoutMinutes int    `json:",default=60"`           // 单次上传（含上传到京橙和提交素材）的超时时间
+}
+
+// Store 暂存空间，统计所有进行中的上传占用
+type Store struct {
+	opts Options
+	mu   sync.Mutex
+	used int64
+}
+
+// NewStore 创建暂存目录，并清理上次异常退出时遗留的上传目录
+func NewStore(opts Options) (*Store, error) {
+	if err := os.MkdirAll(opts.Dir, 0755); err != nil {
+		return nil, fmt.Errorf("创建暂存目录失败: %w", err)
+	}
+
+	items, err := os.ReadDir(opts.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("读取暂存目录失败: %w", err)
+	}
+	for _, item := range items {
+		if item.IsDir() && strings.HasPrefix(item.Name(), workspacePrefix) {
+			if err := os.RemoveAll(filepath.Join(opts.Dir, item.Name())); err != nil {
+				return nil, fmt.Errorf("清理暂存目录失败: %w", err)
+			}
+		}
+	}
+
+	return &Store{opts: opts}, nil
+}
+
+// Create 为一次上传创建独立的工作目录
+func (s *Store) Create() (*Workspace, error) {
+	dir, err := os.MkdirTemp(s.opts.Dir, workspacePrefix)
+	if err != nil {
+		return nil, fmt.Errorf("创建上传目录失败: %w", err)
+	}
+	return &Workspace{store: s, dir: dir, names: make(map[string]bool)}, nil
+}
+
+// reserve 占用暂存空间，超出总量时返回 ErrQuotaExceeded
+func (s *Store) reserve(n int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.used+n > s.opts.MaxTotalMB<<20 {
+		return ErrQuotaExceeded
+	}
+	s.used += n
+	return nil
+}
+
+func (s *Store) release(n int64) {
+	s.mu.Lock()
+	s.used -= n
+	s.mu.Unlock()
+}
+
+// Workspace 一次上传的工作目录，文件平铺存放
+type Workspace struct {
+	store *Store
+	dir   string
+	size  int64
+	names map[string]bool
+	order []string // 文件按保存的先后顺序
+}
+
+// Dir 工作目录路径，可直接作为上传源
+func (w *Workspace) Dir() string {
+	return w.dir
+}
+
+// ID 工作目录名，用于台账记录
+func (w *Workspace) ID() string {
+	return filepath.Base(w.dir)
+}
+
+// Len 已保存的文件数
+func (w *Workspace) Len() int {
+	return len(w.names)
+}
+
+// Names 已保存的文件名，按上传请求中的先后顺序
+func (w *Workspace) Names() []string {
+	return append([]string(nil), w.order...)
+}
+
+// Save 保存一个上传的文件；文件名只保留最后一段，隐藏文件、重名和超出配额的文件返回错误
+func (w *Workspace) Save(name string, r io.Reader) error {
+	name = path.Base(strings.ReplaceAll(name, `\`, "/"))
+	if name == "." || name == "/" || strings.HasPrefix(name, ".") {
+		return fmt.Errorf("文件名无效: %q", name)
+	}
+	if w.names[name] {
+		return fmt.Errorf("文件名重复: %s", name)
+	}
+	if len(w.names) >= w.store.opts.MaxFiles {
+		return fmt.Errorf("单次最多上传 %d 个文件", w.store.opts.MaxFiles)
+	}
+
+	target := filepath.Join(w.dir, name)
+	f, err := os.Create(target)
+	if err != nil {
+		return fmt.Errorf("保存 %s 失败: %w", name, err)
+	}
+
+	_, err = io.Copy(&quotaWriter{w: f, ws: w}, r)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(target)
+		if errors.Is(err, ErrQuotaExceeded) {
+			return fmt.Errorf("保存 %s 失败: %w", name, err)
+		}
+		return fmt.Errorf("保存 %s 失败: %v", name, err)
+	}
+
+	w.names[name] = true
+	w.order = append(w.order, name)
+	return nil
+}
+
+// Remove 删除工作目录并释放占用的配额
+func (w *Workspace) Remove() error {
+	w.store.release(w.size)
+	w.size = 0
+	return os.RemoveAll(w.dir)
+}
+
+// quotaWriter 写入前检查单次上传和暂存空间总量的配额
+type quotaWriter struct {
+	w  io.Writer
+	ws *Workspace
+}
+
+func (q *quotaWriter) Write(p []byte) (int, error) {
+	n := int64(len(p))
+	if q.ws.size+n > q.ws.store.opts.MaxUploadMB<<20 {
+		return 0, ErrQuotaExceeded
+	}
+	if err := q.ws.store.reserve(n); err != nil {
+		return 0, err
+	}
+	q.ws.size += n
+	return q.w.Write(p)
+}
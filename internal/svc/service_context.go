@@ -4,21 +4,103 @@
 package svc
 
 import (
+	"context"
+
+	"jd_material_push/internal/catalog"
 	"jd_material_push/internal/config"
 	"jd_material_push/internal/cookie"
+	"jd_material_push/internal/copylib"
+	"jd_material_push/internal/ledger"
+	"jd_material_push/internal/lexicon"
+	"jd_material_push/internal/materialcenter"
+	"jd_material_push/internal/mediaspec"
+	"jd_material_push/internal/preset"
+	"jd_material_push/internal/staging"
+
+	"github.com/zeromicro/go-zero/core/logx"
 )
 
 type ServiceContext struct {
-	Config        config.Config
-	CookieManager *cookie.Manager
+	Config         config.Config
+	CookieManager  *cookie.Manager
+	MaterialCenter *materialcenter.Client
+	Ledger         *ledger.Store
+	Catalog        *catalog.Store
+	MediaSpecs     *mediaspec.Set
+	Staging        *staging.Store
+	CopyLibrary    *copylib.Store
+	Lexicon        *lexicon.Store
+	Presets        *preset.Store
 }
 
 func NewServiceContext(c config.Config) *ServiceContext {
 	// 初始化 Cookie 管理器
 	cookieMgr := cookie.NewManager()
 
+	// 打开本地推送台账
+	ledgerStore, err := ledger.NewStore(c.LedgerPath)
+	logx.Must(err)
+
+	// 加载投放媒体与素材品类目录（文件不可用时使用内置目录），并叠加本地同步缓存
+	baseCatalog, err := catalog.LoadOrDefault(c.CatalogPath)
+	if err != nil {
+		logx.Errorf("%v，使用内置目录", err)
+	}
+	catalogStore, err := catalog.NewStore(baseCatalog, c.CatalogCachePath)
+	logx.Must(err)
+
+	// 加载各投放媒体的素材规格
+	mediaSpecs, err := mediaspec.Load(c.MediaSpecsPath)
+	logx.Must(err)
+
+	// 打开投放文案库
+	copyLibrary, err := copylib.NewStore(c.CopyLibraryPath)
+	logx.Must(err)
+
+	// 加载违禁词词库，文件修改后自动重新加载
+	lexiconStore, err := lexicon.NewStore(c.LexiconPath)
+	logx.Must(err)
+	if lexiconStore.Current().Missing() {
+		logx.Errorf("违禁词词库 %s 不存在，提交前不会检查违禁词", c.LexiconPath)
+	}
+
+	// 加载推送预设，文件被他人修改后自动重新加载
+	presetStore, err := preset.NewStore(c.PresetsPath)
+	logx.Must(err)
+
+	// 浏览器上传的暂存空间，清理上次遗留的文件
+	stagingStore, err := staging.NewStore(c.Staging)
+	logx.Must(err)
+
+	materialCenter := materialcenter.NewClient(cookieMgr)
+	if c.CatalogSyncOnStart {
+		go syncCatalog(materialCenter, catalogStore, c.CatalogSyncMethod)
+	}
+
 	return &ServiceContext{
-		Config:        c,
-		CookieManager: cookieMgr,
+		Config:         c,
+		CookieManager:  cookieMgr,
+		MaterialCenter: materialCenter,
+		Ledger:         ledgerStore,
+		Catalog:        catalogStore,
+		MediaSpecs:     mediaSpecs,
+		Staging:        stagingStore,
+		CopyLibrary:    copyLibrary,
+		Lexicon:        lexiconStore,
+		Presets:        presetStore,
+	}
+}
+
+// syncCatalog 启动时从素材中心同步目录，失败时继续使用本地目录
+func syncCatalog(client *materialcenter.Client, store *catalog.Store, method string) {
+	snap, err := catalog.Fetch(context.Background(), client, method)
+	if err != nil {
+		logx.Errorf("启动同步目录失败: %v，继续使用本地目录", err)
+		return
+	}
+	if _, err := store.Apply(snap); err != nil {
+		logx.Errorf("保存目录缓存失败: %v", err)
+		return
 	}
+	logx.Infof("目录同步完成，版本: %s", snap.Version)
 }
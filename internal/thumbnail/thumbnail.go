@@ -0,0 +1,104 @@
+package thumbnail
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	_ "image/gif"
+	_ "image/png"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// Edge 缩略图长边像素
+const Edge = 320
+
+// videoTimeout 用 ffmpeg 截取视频画面的超时时间
+const videoTimeout = 30 * time.Second
+
+// ErrNoFFmpeg 未安装 ffmpeg，无法生成视频缩略图
+var ErrNoFFmpeg = errors.New("未找到 ffmpeg，不生成视频缩略图")
+
+// Image 由图片生成 JPEG 缩略图：缩小到长边不超过 Edge，透明区域铺白底；动图取第一帧
+func Image(r io.Reader) ([]byte, error) {
+	img, _, err := image.Decode(r)
+	if err != nil {
+		return nil, fmt.Errorf("解码图片失败: %w", err)
+	}
+
+	b := img.Bounds()
+	scale := min(1, float64(Edge)/float64(max(b.Dx(), b.Dy())))
+	dst := image.NewRGBA(image.Rect(0, 0, max(1, int(float64(b.Dx())*scale)), max(1, int(float64(b.Dy())*scale))))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.ApproxBiLinear.Scale(dst, dst.Bounds(), img, b, draw.Over, nil)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 80}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// VideoSupported 是否能生成视频缩略图，即是否已安装 ffmpeg
+func VideoSupported() bool {
+	_, err := exec.LookPath("ffmpeg")
+	return err == nil
+}
+
+// Video 用 ffmpeg 截取视频第 1 秒（不足 1 秒时取第一帧）的画面生成缩略图，未安装 ffmpeg 时返回 ErrNoFFmpeg
+func Video(ctx context.Context, path string) ([]byte, error) {
+	ffmpeg, err := exec.LookPath("ffmpeg")
+	if err != nil {
+		return nil, ErrNoFFmpeg
+	}
+	ctx, cancel := context.WithTimeout(ctx, videoTimeout)
+	defer cancel()
+
+	frame := func(seek string) ([]byte, error) {
+		var stderr bytes.Buffer
+		cmd := exec.CommandContext(ctx, ffmpeg, "-v", "error", "-ss", seek, "-i", path, "-frames:v", "1", "-f", "image2pipe", "-vcodec", "mjpeg", "-")
+		cmd.Stderr = &stderr
+		out, err := cmd.Output()
+		if err != nil {
+			return nil, fmt.Errorf("ffmpeg 截取画面失败: %v %s", err, bytes.TrimSpace(stderr.Bytes()))
+		}
+		return out, nil
+	}
+	out, err := frame("1")
+	if err == nil && len(out) == 0 {
+		out, err = frame("0")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("ffmpeg 未截取到画面")
+	}
+	return Image(bytes.NewReader(out))
+}
+
+// Save 将缩略图保存到 dir，文件名由 key（如素材 URL）计算，返回文件名
+func Save(dir, key string, data []byte) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("创建缩略图目录失败: %w", err)
+	}
+	sum := sha256.Sum256([]byte(key))
+	name := hex.EncodeToString(sum[:8]) + ".jpg"
+	if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+		return "", fmt.Errorf("保存缩略图失败: %w", err)
+	}
+	return name, nil
+}
@@ -0,0 +1,264 @@
+package transform
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"jd_material_push/internal/imagenorm"
+	"jd_material_push/internal/media"
+
+	"golang.org/x/image/draw"
+)
+
+// execOutputLimit 外部命令输出写入日志的最大字节数
+const execOutputLimit = 500
+
+// Normalizer 图片规整步骤
+type Normalizer struct {
+	Options imagenorm.Options
+}
+
+func (n *Normalizer) Name() string { return StepNormalize }
+
+func (n *Normalizer) Transform(ctx context.Context, in File, workDir string) (File, string, error) {
+	result, err := imagenorm.Normalize(in.Path, in.Name, workDir, n.Options)
+	if err != nil {
+		return in, "", err
+	}
+	if result == nil {
+		return in, "无需规整", nil
+	}
+	return File{Path: result.Path, Name: result.Name, Index: in.Index}, strings.Join(result.Actions, "，"), nil
+}
+
+// Renamer 按模板重命名上传文件名，不改动文件内容
+// 模板变量: {name} 不含扩展名的文件名，{ext} 扩展名（含点），{index} 序号，{date} 日期 20060102，{time} 时间 150405
+type Renamer struct {
+	template string
+}
+
+// NewRenamer 创建重命名步骤；模板中没有 {ext} 时自动保留原扩展名
+func NewRenamer(template string) (*Renamer, error) {
+	if strings.TrimSpace(template) == "" {
+		return nil, errors.New("重命名模板不能为空")
+	}
+	if !strings.Contains(template, "{ext}") {
+		template += "{ext}"
+	}
+	return &Renamer{template: template}, nil
+}
+
+func (r *Renamer) Name() string { return StepRename }
+
+func (r *Renamer) Transform(ctx context.Context, in File, workDir string) (File, string, error) {
+	ext := filepath.Ext(in.Name)
+	now := time.Now()
+	name := strings.NewReplacer(
+		"{name}", strings.TrimSuffix(in.Name, ext),
+		"{ext}", ext,
+		"{index}", strconv.Itoa(in.Index),
+		"{date}", now.Format("20060102"),
+		"{time}", now.Format("150405"),
+	).Replace(r.template)
+
+	if strings.ContainsAny(name, `/\`) || strings.TrimSpace(strings.TrimSuffix(name, ext)) == "" {
+		return in, "", fmt.Errorf("重命名结果无效: %q", name)
+	}
+
+	out := in
+	out.Name = name
+	return out, fmt.Sprintf("%s → %s", in.Name, name), nil
+}
+
+// Watermarker 在图片上叠加水印，视频和动图跳过
+type Watermarker struct {
+	mark     image.Image
+	position string
+	opacity  float64
+	scale    float64
+	margin   int
+}
+
+// NewWatermarker 创建水印步骤，水印图片在创建时加载
+func NewWatermarker(path, position string, opacity, scale float64, margin int) (*Watermarker, error) {
+	if path == "" {
+		return nil, errors.New("未配置水印图片")
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("打开水印图片失败: %w", err)
+	}
+	defer f.Close()
+
+	mark, _, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("解码水印图片失败: %w", err)
+	}
+	return &Watermarker{mark: mark, position: position, opacity: opacity, scale: scale, margin: margin}, nil
+}
+
+func (w *Watermarker) Name() string { return StepWatermark }
+
+func (w *Watermarker) Transform(ctx context.Context, in File, workDir string) (File, string, error) {
+	typeInfo, err := media.DetectFile(in.Path)
+	if err != nil {
+		return in, "", err
+	}
+	if typeInfo.MaterialType != media.MaterialTypeImage {
+		return in, "跳过：非图片素材", nil
+	}
+	if typeInfo.Animated {
+		return in, "跳过：动图", nil
+	}
+
+	data, err := os.ReadFile(in.Path)
+	if err != nil {
+		return in, "", fmt.Errorf("读取图片失败: %w", err)
+	}
+	base, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return in, "", fmt.Errorf("解码图片失败: %w", err)
+	}
+
+	b := base.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), base, b.Min, draw.Src)
+
+	// 按比例缩放水印
+	mb := w.mark.Bounds()
+	mw := max(1, int(float64(b.Dx())*w.scale))
+	mh := max(1, mw*mb.Dy()/mb.Dx())
+	mark := image.NewRGBA(image.Rect(0, 0, mw, mh))
+	draw.CatmullRom.Scale(mark, mark.Bounds(), w.mark, mb, draw.Src, nil)
+
+	at := w.anchor(b.Dx(), b.Dy(), mw, mh)
+	mask := image.NewUniform(color.Alpha{A: uint8(w.opacity * 255)})
+	draw.DrawMask(dst, image.Rect(at.X, at.Y, at.X+mw, at.Y+mh), mark, image.Point{}, mask, image.Point{}, draw.Over)
+
+	// PNG 保留 PNG，其余（JPEG、WebP、静态 GIF）输出 JPEG
+	var buf bytes.Buffer
+	ext := ".jpg"
+	if typeInfo.Kind == media.KindPNG {
+		ext = ".png"
+		err = png.Encode(&buf, dst)
+	} else {
+		err = jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 92})
+	}
+	if err != nil {
+		return in, "", fmt.Errorf("编码图片失败: %w", err)
+	}
+
+	name := strings.TrimSuffix(in.Name, filepath.Ext(in.Name)) + ext
+	path := filepath.Join(workDir, name)
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return in, "", fmt.Errorf("写入水印图片失败: %w", err)
+	}
+	return File{Path: path, Name: name, Index: in.Index}, fmt.Sprintf("已添加水印（%s）", w.position), nil
+}
+
+// anchor 计算水印左上角位置
+func (w *Watermarker) anchor(width, height, mw, mh int) image.Point {
+	left, top := w.margin, w.margin
+	right, bottom := width-mw-w.margin, height-mh-w.margin
+	switch w.position {
+	case "top-left":
+		return image.Pt(left, top)
+	case "top-right":
+		return image.Pt(right, top)
+	case "bottom-left":
+		return image.Pt(left, bottom)
+	case "center":
+		return image.Pt((width-mw)/2, (height-mh)/2)
+	}
+	return image.Pt(right, bottom)
+}
+
+// Executor 调用外部命令处理文件，命令需把结果写到 {output}
+type Executor struct {
+	command   []string
+	outputExt string
+	timeout   time.Duration
+}
+
+// NewExecutor 创建外部命令步骤，参数中必须包含 {input} 和 {output}
+func NewExecutor(command []string, outputExt string, timeoutSeconds int) (*Executor, error) {
+	if len(command) == 0 {
+		return nil, errors.New("未配置外部命令")
+	}
+	joined := strings.Join(command, " ")
+	if !strings.Contains(joined, "{input}") || !strings.Contains(joined, "{output}") {
+		return nil, errors.New("外部命令参数中需要包含 {input} 和 {output}")
+	}
+	if outputExt != "" && !strings.HasPrefix(outputExt, ".") {
+		outputExt = "." + outputExt
+	}
+	return &Executor{command: command, outputExt: outputExt, timeout: time.Duration(timeoutSeconds) * time.Second}, nil
+}
+
+func (e *Executor) Name() string { return StepExec }
+
+func (e *Executor) Transform(ctx context.Context, in File, workDir string) (File, string, error) {
+	ext := e.outputExt
+	if ext == "" {
+		ext = filepath.Ext(in.Name)
+	}
+	name := strings.TrimSuffix(in.Name, filepath.Ext(in.Name)) + ext
+	output := filepath.Join(workDir, name)
+
+	replacer := strings.NewReplacer("{input}", in.Path, "{output}", output)
+	args := make([]string, len(e.command))
+	for i, arg := range e.command {
+		args[i] = replacer.Replace(arg)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+	combined, err := exec.CommandContext(ctx, args[0], args[1:]...).CombinedOutput()
+	text := truncate(strings.TrimSpace(string(combined)), execOutputLimit)
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return in, "", fmt.Errorf("%s 执行超时（%s）", filepath.Base(args[0]), e.timeout)
+		}
+		if text != "" {
+			return in, "", fmt.Errorf("%s 执行失败: %v: %s", filepath.Base(args[0]), err, text)
+		}
+		return in, "", fmt.Errorf("%s 执行失败: %v", filepath.Base(args[0]), err)
+	}
+
+	info, err := os.Stat(output)
+	if err != nil || info.Size() == 0 {
+		return in, "", fmt.Errorf("%s 未生成输出文件", filepath.Base(args[0]))
+	}
+
+	msg := fmt.Sprintf("%s 执行完成", filepath.Base(args[0]))
+	if text != "" {
+		msg += ": " + text
+	}
+	return File{Path: output, Name: name, Index: in.Index}, msg, nil
+}
+
+// truncate 保留末尾 limit 字节（外部命令的关键信息通常在最后），按 UTF-8 边界截断
+func truncate(s string, limit int) string {
+	if len(s) <= limit {
+		return s
+	}
+	s = s[len(s)-limit:]
+	for i := 0; i < len(s) && i < 4; i++ {
+		if s[i]&0xC0 != 0x80 {
+			return "…" + s[i:]
+		}
+	}
+	return "…" + s
+}
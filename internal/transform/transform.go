@@ -0,0 +1,182 @@
+package transform
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"jd_material_push/internal/imagenorm"
+	"jd_material_push/internal/source"
+)
+
+// 内置步骤类型
+const (
+	StepNormalize = "normalize" // 图片规整，使用全局 Normalize 配置
+	StepRename    = "rename"    // 按模板重命名上传文件名
+	StepWatermark = "watermark" // 叠加水印图片
+	StepExec      = "exec"      // 调用外部命令
+)
+
+// DefaultProfile 上传请求未指定方案时使用的方案名
+const DefaultProfile = "default"
+
+// File 流经管道的文件
+type File struct {
+	Path  string // 当前文件路径，原文件或上一步生成的副本
+	Name  string // 上传时使用的文件名
+	Index int    // 在本次上传中的序号，从 1 开始
+}
+
+// Transformer 上传前的一个处理步骤
+type Transformer interface {
+	// Name 步骤名称，记录在日志中
+	Name() string
+	// Transform 处理文件，生成的新文件写入 workDir；无需处理时原样返回输入
+	Transform(ctx context.Context, in File, workDir string) (out File, log string, err error)
+}
+
+// StepLog 一个步骤的执行记录
+type StepLog struct {
+	Step    string // 步骤名称
+	Success bool   // 是否成功
+	Message string // 处理说明或错误信息
+	Output  string // 处理后的上传文件名
+}
+
+// StepConfig 方案中的一个步骤，按 Type 使用对应的字段
+type StepConfig struct {
+	Type            string `json:",options=normalize|rename|watermark|exec"`
+	ContinueOnError bool   `json:",optional"` // 失败时跳过该步骤继续，默认中止该文件的上传
+
+	// rename
+	Template string `json:",optional"` // 如 {date}_{name}_{index}{ext}
+
+	// watermark
+	Image    string  `json:",optional"`                                                                        // 水印 PNG 路径
+	Position string  `json:",default=bottom-right,options=top-left|top-right|bottom-left|bottom-right|center"` // 水印位置
+	Opacity  float64 `json:",default=0.8,range=(0:1]"`                                                         // 不透明度
+	Scale    float64 `json:",default=0.2,range=(0:1]"`                                                         // 水印宽度占图片宽度的比例
+	Margin   int     `json:",default=20"`                                                                      // 距边缘像素
+
+	// exec
+	Command   []string `json:",optional"`    // 命令及参数，{input}、{output} 会替换为输入、输出文件路径
+	OutputExt string   `json:",optional"`    // 输出文件扩展名，默认与输入一致
+	Timeout   int      `json:",default=300"` // 超时时间（秒）
+}
+
+// Profile 一个命名的处理方案，步骤按顺序执行
+type Profile struct {
+	Name       string
+	Steps      []StepConfig `json:",optional"`
+	Ignore     []string     `json:",optional"` // 跳过的文件，语法同 .gitignore，上传源中的 .pushignore 可覆盖
+	Extensions []string     `json:",optional"` // 只上传这些扩展名的文件，为空时不限制
+	Compliance string       `json:",optional"` // 违禁词检查方式：为空时按词库中的级别，block 命中即阻止，warn 只提示，off 不检查
+}
+
+// Filter 方案的文件筛选设置
+func (p Profile) Filter() source.Filter {
+	return source.Filter{Profile: p.Name, Ignore: p.Ignore, Extensions: p.Extensions}
+}
+
+// Lookup 按方案名查找方案；方案名为空时使用 default 方案，default 未配置时返回空方案
+func Lookup(profiles []Profile, name string) (Profile, error) {
+	lookup := name
+	if lookup == "" {
+		lookup = DefaultProfile
+	}
+	for _, p := range profiles {
+		if p.Name == lookup {
+			return p, nil
+		}
+	}
+	if name != "" {
+		return Profile{}, fmt.Errorf("处理方案不存在: %s", name)
+	}
+	return Profile{Name: lookup}, nil
+}
+
+type step struct {
+	Transformer
+	continueOnError bool
+}
+
+// Pipeline 按顺序执行的处理步骤
+type Pipeline struct {
+	steps []step
+}
+
+// Build 按方案名构建管道；启用了全局图片规整且方案中没有 normalize 步骤时，规整作为第一步（失败时上传原文件）
+// 方案名为空时使用 default 方案，default 未配置时只执行全局规整
+func Build(profiles []Profile, name string, normalize imagenorm.Options) (*Pipeline, error) {
+	profile, err := Lookup(profiles, name)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Pipeline{}
+	hasNormalize := false
+	for _, cfg := range profile.Steps {
+		if cfg.Type == StepNormalize {
+			hasNormalize = true
+		}
+	}
+	if normalize.Enabled && !hasNormalize {
+		p.steps = append(p.steps, step{Transformer: &Normalizer{Options: normalize}, continueOnError: true})
+	}
+
+	for i, cfg := range profile.Steps {
+		t, err := NewStep(cfg, normalize)
+		if err != nil {
+			return nil, fmt.Errorf("处理方案 %s 第 %d 步: %w", profile.Name, i+1, err)
+		}
+		p.steps = append(p.steps, step{Transformer: t, continueOnError: cfg.ContinueOnError})
+	}
+	return p, nil
+}
+
+// NewStep 按配置创建内置步骤
+func NewStep(cfg StepConfig, normalize imagenorm.Options) (Transformer, error) {
+	switch cfg.Type {
+	case StepNormalize:
+		return &Normalizer{Options: normalize}, nil
+	case StepRename:
+		return NewRenamer(cfg.Template)
+	case StepWatermark:
+		return NewWatermarker(cfg.Image, cfg.Position, cfg.Opacity, cfg.Scale, cfg.Margin)
+	case StepExec:
+		return NewExecutor(cfg.Command, cfg.OutputExt, cfg.Timeout)
+	}
+	return nil, fmt.Errorf("未知的步骤类型: %s", cfg.Type)
+}
+
+// Len 步骤数量
+func (p *Pipeline) Len() int {
+	return len(p.steps)
+}
+
+// Run 依次执行各步骤，每步使用 workDir 下独立的子目录，返回最终要上传的文件和每步的日志
+func (p *Pipeline) Run(ctx context.Context, in File, workDir string) (File, []StepLog, error) {
+	var logs []StepLog
+	current := in
+	for i, s := range p.steps {
+		stepDir := filepath.Join(workDir, strconv.Itoa(i+1))
+		if err := os.MkdirAll(stepDir, 0755); err != nil {
+			return current, logs, fmt.Errorf("创建步骤目录失败: %w", err)
+		}
+
+		out, msg, err := s.Transform(ctx, current, stepDir)
+		if err != nil {
+			logs = append(logs, StepLog{Step: s.Name(), Message: err.Error(), Output: current.Name})
+			if s.continueOnError {
+				continue
+			}
+			return current, logs, fmt.Errorf("%s: %w", s.Name(), err)
+		}
+
+		logs = append(logs, StepLog{Step: s.Name(), Success: true, Message: msg, Output: out.Name})
+		current = out
+	}
+	return current, logs, nil
+}
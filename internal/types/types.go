@@ -1,4 +1,4 @@
-// Code generated by goctl. DO NOT EDIT.
+// Code scaffolded by goctl. Safe to edit.
 // goctl 1.9.2
 
 package types
@@ -23,24 +23,56 @@ type GetFilesResponse struct {
 
 // UploadRequest 上传请求
 type UploadRequest struct {
-	FolderPath string `json:"folderPath"` // 文件夹路径
+	FolderPath string   `json:"folderPath"`         // 文件夹路径
+	JobID      string   `json:"jobId,optional"`     // 追加到已有任务，为空时新建任务
+	Profile    string   `json:"profile,optional"`   // 上传前处理方案，为空时使用 default 方案
+	FileNames  []string `json:"fileNames,optional"` // 只按顺序上传这些文件（源内的相对路径），为空时上传全部
+	Order      string   `json:"order,optional"`     // 上传顺序：name、natural、mtime、size，为空时使用配置的 UploadOrder
+	Preset     string   `json:"preset,optional"`    // 推送预设，补全未填写的 profile 和 order
 }
 
 // UploadResult 单个文件上传结果
 type UploadResult struct {
-	FileName string `json:"fileName"` // 文件名
-	Success  bool   `json:"success"`  // 是否成功
-	URL      string `json:"url"`      // 上传后的 URL
-	LocalURL string `json:"localUrl"` // 本地 URL
-	ErrorMsg string `json:"errorMsg"` // 错误信息
-	FileSize int64  `json:"fileSize"` // 文件大小
+	FileName     string          `json:"fileName"`               // 文件名
+	Success      bool            `json:"success"`                // 是否成功
+	URL          string          `json:"url"`                    // 上传后的 URL
+	LocalURL     string          `json:"localUrl"`               // 本地 URL
+	ErrorMsg     string          `json:"errorMsg"`               // 错误信息
+	FileSize     int64           `json:"fileSize"`               // 文件大小
+	MaterialType int             `json:"materialType"`           // 按内容识别的素材类型（1 图片，2 视频）
+	MimeType     string          `json:"mimeType"`               // 按内容识别的 MIME 类型
+	Width        int             `json:"width"`                  // 宽（像素）
+	Height       int             `json:"height"`                 // 高（像素）
+	Duration     float64         `json:"duration"`               // 视频时长（秒）
+	Codec        string          `json:"codec"`                  // 视频编码或图片格式
+	OriginalSize int64           `json:"originalSize,omitempty"` // 处理前的原文件大小，未生成副本时为空
+	UploadName   string          `json:"uploadName,omitempty"`   // 处理后实际上传的文件名，与原文件名相同时为空
+	Steps        []TransformStep `json:"steps,omitempty"`        // 上传前各处理步骤的记录
+	ReleaseCopy  string          `json:"releaseCopy,omitempty"`  // 附带文案（与素材同名的 .txt），提交到同一任务时作为该素材的投放文案
+	Thumbnail    string          `json:"-"`                      // 由实际上传的文件生成的缩略图（ThumbnailDir 中的文件名），写入台账
+}
+
+// TransformStep 上传前处理步骤的执行记录
+type TransformStep struct {
+	Step    string `json:"step"`    // 步骤名称：normalize / rename / watermark / exec
+	Success bool   `json:"success"` // 是否成功
+	Message string `json:"message"` // 处理说明或错误信息
+	Output  string `json:"output"`  // 处理后的上传文件名
 }
 
 // UploadResponse 上传响应
 type UploadResponse struct {
 	Code    int            `json:"code"`
 	Message string         `json:"message"`
+	JobID   string         `json:"jobId"` // 台账任务 ID
 	Data    []UploadResult `json:"data"`
+	Skipped []SkippedFile  `json:"skipped,omitempty"` // 被忽略规则跳过的文件
+}
+
+// SkippedFile 被忽略规则跳过的文件
+type SkippedFile struct {
+	FileName string `json:"fileName"` // 源内的相对路径
+	Rule     string `json:"rule"`     // 命中的规则，如 .pushignore:3 *.psd
 }
 
 // JingchengUploadResponse 京橙平台上传接口响应
@@ -77,22 +109,566 @@ type MaterialItem struct {
 	MaterialType int    `json:"materialType"` // 素材类型
 	URL          string `json:"url"`          // URL
 	LocalURL     string `json:"localUrl"`     // 本地URL
+	// 以下为本地解析的元数据，仅用于校验和报告，不提交给素材中心
+	Width    int     `json:"width,optional,omitempty"`    // 宽（像素）
+	Height   int     `json:"height,optional,omitempty"`   // 高（像素）
+	Duration float64 `json:"duration,optional,omitempty"` // 视频时长（秒）
+	Codec    string  `json:"codec,optional,omitempty"`    // 视频编码或图片格式
+	// 以下为单个素材的投放设置，为空时使用请求中的设置；生成的 applyAttr 不同的素材分到不同批次
+	MediaList    []string `json:"mediaList,optional,omitempty"`    // 投放媒体列表
+	CategoryList []string `json:"categoryList,optional,omitempty"` // 素材所属品类列表
+	ReleaseCopy  string   `json:"releaseCopy,optional,omitempty"`  // 投放文案
+	Folder       string   `json:"folder,optional,omitempty"`       // 素材在上传源中所在的子目录，如 女装，按子目录匹配品类时使用
 }
 
-// SubmitMaterialResponse 提交素材响应
+// SubmitMaterialResponse 提交素材响应；分多批提交时 Result 表示是否全部成功，UUID 为空
 type SubmitMaterialResponse struct {
-	Code     int    `json:"code"`
-	Message  string `json:"message"`
-	Result   bool   `json:"result"`
-	HasNext  bool   `json:"hasNext"`
-	TotalNum int    `json:"totalNum"`
-	UUID     string `json:"uuid"`
+	Code       int                 `json:"code"`
+	Message    string              `json:"message"`
+	Result     bool                `json:"result"`
+	HasNext    bool                `json:"hasNext"`
+	TotalNum   int                 `json:"totalNum"`
+	UUID       string              `json:"uuid"`
+	Batches    []SubmitBatchResult `json:"batches,omitempty"`    // 每批的提交结果
+	Materials  []MaterialOutcome   `json:"materials,omitempty"`  // 每个素材的提交结果
+	Held       *HeldReport         `json:"held,omitempty"`       // 未满足提交策略时暂缓提交的情况
+	Compliance []ComplianceHit     `json:"compliance,omitempty"` // 投放文案和素材名称中的违禁词，error 级别时已阻止提交
+	Specs      []SpecViolation     `json:"specs,omitempty"`      // 不符合投放媒体规格之处，error 级别的素材未提交
+	JobID      string              `json:"jobId,omitempty"`      // 记录本次提交的台账任务
+}
+
+// SpecViolation 素材不符合投放媒体规格之处
+type SpecViolation struct {
+	MaterialName string `json:"materialName"` // 素材名称
+	URL          string `json:"url"`          // URL
+	Media        string `json:"media"`        // 投放媒体
+	Severity     string `json:"severity"`     // error 时该素材未提交，warning 仅提示
+	Message      string `json:"message"`
+}
+
+// ComplianceHit 一处违禁词
+type ComplianceHit struct {
+	Field     string   `json:"field"`             // 来源：releaseCopy、materialName
+	Target    string   `json:"target"`            // 所在的素材名称，投放文案为空
+	Term      string   `json:"term"`              // 命中的词
+	Category  string   `json:"category"`          // 类别
+	Severity  string   `json:"severity"`          // error 阻止提交，warning 仅提示
+	Suggest   []string `json:"suggest,omitempty"` // 替换建议，为空时建议删除
+	Highlight string   `json:"highlight"`         // 用【】标出违禁词的原文
+}
+
+// HeldReport 未满足提交策略、暂缓提交的情况
+type HeldReport struct {
+	Policy        string   `json:"policy"`        // 提交策略
+	Total         int      `json:"total"`         // 任务中的文件数
+	Uploaded      int      `json:"uploaded"`      // 上传成功的文件数
+	FailedFiles   []string `json:"failedFiles"`   // 上传失败的文件
+	HeldMaterials []string `json:"heldMaterials"` // 暂缓提交的素材
+}
+
+// MaterialOutcome 单个素材的提交结果
+type MaterialOutcome struct {
+	MaterialName string `json:"materialName"` // 素材名称
+	URL          string `json:"url"`          // URL
+	Batch        int    `json:"batch"`        // 所在批次序号，0 表示不符合投放媒体规格而未提交
+	Success      bool   `json:"success"`      // 是否提交成功
+	Message      string `json:"message"`      // 素材中心返回的信息或错误
+	UUID         string `json:"uuid"`         // 素材中心批次号
 }
 
 // SubmitMaterialBatchRequest 批量提交素材请求
 type SubmitMaterialBatchRequest struct {
-	MaterialList []MaterialItem `json:"materialList"` // 素材列表（最多20个）
-	MediaList    []string       `json:"mediaList"`    // 投放媒体列表
-	CategoryList []string       `json:"categoryList"` // 素材所属品类列表
-	ReleaseCopy  string         `json:"releaseCopy"`  // 投放文案
+	MaterialList       []MaterialItem         `json:"materialList"`                // 素材列表，超过 20 个时自动分批提交
+	MediaList          []string               `json:"mediaList,optional"`          // 投放媒体列表
+	CategoryList       []string               `json:"categoryList,optional"`       // 素材所属品类列表
+	ReleaseCopy        string                 `json:"releaseCopy,optional"`        // 投放文案
+	JobID              string                 `json:"jobId,optional"`              // 台账任务 ID，用于记录提交结果，为空时新建任务
+	Columns            map[string]interface{} `json:"columns,optional"`            // 其他 diyColumns 列值，key 为列定义中的 key
+	GroupByType        bool                   `json:"groupByType,optional"`        // 图片和视频分批提交
+	IsolateFailures    bool                   `json:"isolateFailures,optional"`    // 批次被拒绝时拆分重新提交，定位被拒绝的素材
+	SubmitPolicy       string                 `json:"submitPolicy,optional"`       // 提交策略：partial（默认）、all、threshold，需要 jobId
+	MinUploadedPercent int                    `json:"minUploadedPercent,optional"` // threshold 策略要求的上传成功比例（1-100）
+	NameTemplate       string                 `json:"nameTemplate,optional"`       // 素材名称模板，为空时使用配置的 NameTemplate
+	Campaign           string                 `json:"campaign,optional"`           // 活动名，用于名称模板中的 {campaign}
+	CopyVariants       []CopyVariant          `json:"copyVariants,optional"`       // 投放文案变体，按规则或轮流分配给素材，未分配到的素材使用 releaseCopy
+	CopyVars           map[string]string      `json:"copyVars,optional"`           // 文案模板变量，如 product、price
+	Profile            string                 `json:"profile,optional"`            // 处理方案，决定违禁词检查方式
+	Preset             string                 `json:"preset,optional"`             // 推送预设，补全请求中未填写的设置
+	CategoryByFolder   bool                   `json:"categoryByFolder,optional"`   // 按素材所在子目录的名称匹配素材品类，未匹配的素材使用 categoryList
+}
+
+// CopyVariant 投放文案变体
+type CopyVariant struct {
+	CopyID string `json:"copyId,optional"` // 文案库中的文案 ID，与 text 二选一
+	Text   string `json:"text,optional"`   // 文案内容，可含模板变量
+	Match  string `json:"match,optional"`  // 只分配给文件名匹配该通配符的素材，如 *_promo*；为空时参与轮流分配
+}
+
+// WithdrawMaterialRequest 撤回素材请求
+type WithdrawMaterialRequest struct {
+	JobID        string         `json:"jobId,optional"`        // 撤回该任务下已提交的素材
+	MaterialList []MaterialItem `json:"materialList,optional"` // 指定素材（与 jobId 同时传入时只撤回交集）
+	Confirm      bool           `json:"confirm,optional"`      // false 时仅返回待撤回清单
+}
+
+// WithdrawResult 单个素材撤回结果
+type WithdrawResult struct {
+	MaterialName string `json:"materialName"` // 素材名称
+	URL          string `json:"url"`          // URL
+	Success      bool   `json:"success"`      // 是否成功
+	Message      string `json:"message"`      // 结果信息
+}
+
+// WithdrawMaterialResponse 撤回素材响应
+type WithdrawMaterialResponse struct {
+	Code      int              `json:"code"`
+	Message   string           `json:"message"`
+	Confirmed bool             `json:"confirmed"` // 是否已实际执行撤回
+	Data      []WithdrawResult `json:"data"`
+}
+
+// CatalogOption 目录选项
+type CatalogOption struct {
+	Label string `json:"label"` // 显示名称
+	Value string `json:"value"` // 提交值
+}
+
+// Catalog 投放媒体与素材品类目录
+type Catalog struct {
+	Media      []CatalogOption `json:"media"`      // 投放媒体
+	Categories []CatalogOption `json:"categories"` // 素材所属品类
+	Version    string          `json:"version"`    // 同步版本号，未同步时为空
+	SyncedAt   string          `json:"syncedAt"`   // 同步时间
+}
+
+// CatalogResponse 目录响应
+type CatalogResponse struct {
+	Code    int     `json:"code"`
+	Message string  `json:"message"`
+	Data    Catalog `json:"data"`
+}
+
+// SyncCatalogResponse 同步目录响应
+type SyncCatalogResponse struct {
+	Code     int    `json:"code"`
+	Message  string `json:"message"`
+	Version  string `json:"version"`  // 同步后的版本号
+	SyncedAt string `json:"syncedAt"` // 同步时间
+	Changed  bool   `json:"changed"`  // 与本地缓存相比是否有变化
+}
+
+// ValidateRequest 上传前预检请求
+type ValidateRequest struct {
+	FolderPath   string                 `json:"folderPath"`            // 文件夹路径
+	MediaList    []string               `json:"mediaList,optional"`    // 投放媒体列表
+	CategoryList []string               `json:"categoryList,optional"` // 素材所属品类列表
+	ReleaseCopy  string                 `json:"releaseCopy,optional"`  // 投放文案
+	Columns      map[string]interface{} `json:"columns,optional"`      // 其他 diyColumns 列值
+	CopyVariants []CopyVariant          `json:"copyVariants,optional"` // 投放文案变体
+	CopyVars     map[string]string      `json:"copyVars,optional"`     // 文案模板变量
+	Profile      string                 `json:"profile,optional"`      // 上传前处理方案，决定跳过哪些文件
+	Preset       string                 `json:"preset,optional"`       // 推送预设，补全请求中未填写的设置
+}
+
+// ValidateFinding 单条预检结果
+type ValidateFinding struct {
+	File     string `json:"file"`     // 文件名，非文件相关的检查为空
+	Rule     string `json:"rule"`     // 规则标识
+	Severity string `json:"severity"` // error / warning
+	Message  string `json:"message"`  // 问题描述
+}
+
+// ValidateResponse 上传前预检响应
+type ValidateResponse struct {
+	Code         int               `json:"code"`
+	Message      string            `json:"message"`
+	Passed       bool              `json:"passed"`       // 没有 error 级别问题
+	ErrorCount   int               `json:"errorCount"`   // error 数量
+	WarningCount int               `json:"warningCount"` // warning 数量
+	Data         []ValidateFinding `json:"data"`
+}
+
+// JobManifest 浏览器上传附带的任务清单，以 multipart 中名为 manifest 的 JSON 字段或文件传入
+type JobManifest struct {
+	Profile            string                 `json:"profile,optional"`            // 上传前处理方案
+	Order              string                 `json:"order,optional"`              // 上传顺序：name、natural、mtime、size，为空时按文件在请求中的先后顺序
+	MediaList          []string               `json:"mediaList,optional"`          // 投放媒体列表，为空时只上传不提交
+	CategoryList       []string               `json:"categoryList,optional"`       // 素材所属品类列表
+	ReleaseCopy        string                 `json:"releaseCopy,optional"`        // 投放文案
+	Columns            map[string]interface{} `json:"columns,optional"`            // 其他 diyColumns 列值
+	IsolateFailures    bool                   `json:"isolateFailures,optional"`    // 批次被拒绝时拆分重新提交，定位被拒绝的素材
+	SubmitPolicy       string                 `json:"submitPolicy,optional"`       // 提交策略：partial（默认）、all、threshold
+	MinUploadedPercent int                    `json:"minUploadedPercent,optional"` // threshold 策略要求的上传成功比例（1-100）
+	NameTemplate       string                 `json:"nameTemplate,optional"`       // 素材名称模板
+	Campaign           string                 `json:"campaign,optional"`           // 活动名，用于名称模板中的 {campaign}
+	CopyVariants       []CopyVariant          `json:"copyVariants,optional"`       // 投放文案变体，按规则或轮流分配给素材，未分配到的素材使用 releaseCopy
+	CopyVars           map[string]string      `json:"copyVars,optional"`           // 文案模板变量，如 product、price
+	Preset             string                 `json:"preset,optional"`             // 推送预设，补全清单中未填写的设置
+}
+
+// SubmitBatchResult 一个提交批次的结果
+type SubmitBatchResult struct {
+	Batch     int      `json:"batch"`              // 批次序号，从 1 开始；0 为不符合投放媒体规格而未提交的素材
+	Materials []string `json:"materials"`          // 本批次的素材名称
+	Success   bool     `json:"success"`            // 是否全部提交成功
+	Message   string   `json:"message"`            // 素材中心返回的信息或错误
+	UUID      string   `json:"uuid"`               // 素材中心批次号，拆分提交时为空
+	Rejected  []string `json:"rejected,omitempty"` // 被拒绝的素材名称
+}
+
+// JobUploadResponse 浏览器上传响应
+type JobUploadResponse struct {
+	Code    int                 `json:"code"`
+	Message string              `json:"message"`
+	JobID   string              `json:"jobId"`             // 台账任务 ID
+	Data    []UploadResult      `json:"data"`              // 每个文件的上传结果
+	Skipped []SkippedFile       `json:"skipped,omitempty"` // 被方案忽略规则跳过的文件
+	Batches []SubmitBatchResult `json:"batches,omitempty"` // 提交结果，清单中未指定投放媒体时为空
+	Held    *HeldReport         `json:"held,omitempty"`    // 未满足提交策略时暂缓提交的情况
+}
+
+// HostedMaterial 已在京东存储上的素材，用于不重新上传直接提交
+type HostedMaterial struct {
+	Name     string `json:"name"`              // 素材名称
+	URL      string `json:"url"`               // URL
+	LocalURL string `json:"localUrl,optional"` // 本地 URL
+	Size     int64  `json:"size"`              // 素材大小（字节）
+	Type     int    `json:"type"`              // 素材类型（1 图片，2 视频）
+}
+
+// SubmitJobRequest 仅提交请求：素材来自请求中的清单或本地台账中已上传的记录
+type SubmitJobRequest struct {
+	Materials       []HostedMaterial       `json:"materials,optional"`       // 素材清单
+	FromJobID       string                 `json:"fromJobId,optional"`       // 从该台账任务中取已上传的素材
+	FileNames       []string               `json:"fileNames,optional"`       // 只取台账任务中这些文件名的素材，为空时取全部
+	MediaList       []string               `json:"mediaList,optional"`       // 投放媒体列表
+	CategoryList    []string               `json:"categoryList,optional"`    // 素材所属品类列表
+	ReleaseCopy     string                 `json:"releaseCopy,optional"`     // 投放文案
+	Columns         map[string]interface{} `json:"columns,optional"`         // 其他 diyColumns 列值
+	IsolateFailures bool                   `json:"isolateFailures,optional"` // 批次被拒绝时拆分重新提交，定位被拒绝的素材
+	NameTemplate    string                 `json:"nameTemplate,optional"`    // 素材名称模板
+	Campaign        string                 `json:"campaign,optional"`        // 活动名，用于名称模板中的 {campaign}
+	CopyVariants    []CopyVariant          `json:"copyVariants,optional"`    // 投放文案变体
+	CopyVars        map[string]string      `json:"copyVars,optional"`        // 文案模板变量
+	Profile         string                 `json:"profile,optional"`         // 处理方案，决定违禁词检查方式
+	Preset          string                 `json:"preset,optional"`          // 推送预设，补全请求中未填写的设置
+}
+
+// SubmitJobResponse 仅提交响应
+type SubmitJobResponse struct {
+	Code    int                 `json:"code"`
+	Message string              `json:"message"`
+	JobID   string              `json:"jobId"`             // 本次提交记录的台账任务 ID
+	Total   int                 `json:"total"`             // 提交的素材数
+	Batches []SubmitBatchResult `json:"batches,omitempty"` // 每批的提交结果
+	Held    *HeldReport         `json:"held,omitempty"`    // 仍未满足提交策略时的情况
+}
+
+// RetryJobRequest 重试任务中失败的文件
+type RetryJobRequest struct {
+	JobID   string `json:"jobId"`            // 台账任务 ID
+	Profile string `json:"profile,optional"` // 重新上传和提交时的处理方案，为空时沿用任务的处理方案
+}
+
+// RetryJobResponse 重试任务响应
+type RetryJobResponse struct {
+	Code    int                 `json:"code"`
+	Message string              `json:"message"`
+	JobID   string              `json:"jobId"`             // 台账任务 ID
+	Data    []UploadResult      `json:"data"`              // 重新上传的文件结果
+	Skipped []SkippedFile       `json:"skipped,omitempty"` // 重试时被忽略规则跳过的失败文件
+	Batches []SubmitBatchResult `json:"batches,omitempty"` // 尚未提交成功的素材的提交结果
+	Held    *HeldReport         `json:"held,omitempty"`    // 仍未满足提交策略时的情况
+}
+
+// SubmitHeldRequest 补交暂缓提交的素材
+type SubmitHeldRequest struct {
+	JobID string `json:"jobId"`          // 台账任务 ID
+	Force bool   `json:"force,optional"` // 仍未满足提交策略时也提交
+}
+
+// PreviewNamesRequest 预览素材名称请求，参数与提交时一致
+type PreviewNamesRequest struct {
+	Files        []PreviewFile `json:"files"`                 // 待提交的文件，按提交顺序
+	MediaList    []string      `json:"mediaList,optional"`    // 投放媒体列表
+	CategoryList []string      `json:"categoryList,optional"` // 素材所属品类列表
+	NameTemplate string        `json:"nameTemplate,optional"` // 素材名称模板，为空时使用配置的 NameTemplate
+	Campaign     string        `json:"campaign,optional"`     // 活动名
+}
+
+// PreviewFile 预览名称的文件
+type PreviewFile struct {
+	FileName string `json:"fileName"`          // 原文件名（经过重命名处理时为上传的文件名）
+	FileSize int64  `json:"fileSize,optional"` // 文件大小，用于 {hash}
+}
+
+// PreviewNamesResponse 预览素材名称响应
+type PreviewNamesResponse struct {
+	Code     int           `json:"code"`
+	Message  string        `json:"message"`
+	Template string        `json:"template"` // 实际使用的模板，为空表示使用原文件名
+	Data     []NamePreview `json:"data"`
+}
+
+// NamePreview 单个文件的名称预览
+type NamePreview struct {
+	FileName  string `json:"fileName"`  // 原文件名
+	Name      string `json:"name"`      // 提交时的素材名称
+	Truncated bool   `json:"truncated"` // 是否因超长截断
+}
+
+// CopyListRequest 查询文案库请求
+type CopyListRequest struct {
+	Tag string `form:"tag,optional"` // 只返回带有该标签的文案
+}
+
+// CopyEntry 文案库中的一条文案
+type CopyEntry struct {
+	ID         string         `json:"id"`
+	Name       string         `json:"name"`
+	Text       string         `json:"text"`
+	Tags       []string       `json:"tags"`
+	Length     int            `json:"length"`              // 文案字数（未展开模板变量）
+	Variables  []string       `json:"variables,omitempty"` // 文案中的模板变量
+	Revisions  []CopyRevision `json:"revisions,omitempty"` // 历史版本，最新的在前
+	UseCount   int            `json:"useCount"`
+	LastUsedAt string         `json:"lastUsedAt,omitempty"`
+	UpdatedAt  string         `json:"updatedAt"`
+}
+
+// CopyRevision 文案的一个历史版本
+type CopyRevision struct {
+	Text      string `json:"text"`
+	UpdatedAt string `json:"updatedAt"`
+}
+
+// CopyListResponse 查询文案库响应
+type CopyListResponse struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    []CopyEntry `json:"data"`
+}
+
+// SaveCopyRequest 保存文案请求
+type SaveCopyRequest struct {
+	ID   string   `json:"id,optional"`   // 为空时新建，否则修改该文案
+	Name string   `json:"name,optional"` // 名称，为空时保留原名称，新建时取文案开头
+	Text string   `json:"text"`          // 文案内容，可含模板变量
+	Tags []string `json:"tags,optional"` // 标签
+}
+
+// DeleteCopyRequest 删除文案请求
+type DeleteCopyRequest struct {
+	ID string `json:"id"`
+}
+
+// CopyResponse 保存或删除文案响应
+type CopyResponse struct {
+	Code    int        `json:"code"`
+	Message string     `json:"message"`
+	Data    *CopyEntry `json:"data,omitempty"`
+}
+
+// ComplianceCheckRequest 违禁词检查请求
+type ComplianceCheckRequest struct {
+	Texts         []string `json:"texts,optional"`         // 投放文案，可传多个变体
+	MaterialNames []string `json:"materialNames,optional"` // 素材名称
+	Profile       string   `json:"profile,optional"`       // 处理方案，决定检查方式
+}
+
+// ComplianceCheckResponse 违禁词检查响应
+type ComplianceCheckResponse struct {
+	Code           int             `json:"code"`
+	Message        string          `json:"message"`
+	Passed         bool            `json:"passed"`                   // 没有 error 级别的违禁词
+	LexiconMissing bool            `json:"lexiconMissing,omitempty"` // 词库文件不存在，未检查违禁词
+	Data           []ComplianceHit `json:"data"`
+}
+
+// ReloadLexiconResponse 重新加载词库响应
+type ReloadLexiconResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Terms   int    `json:"terms"` // 词库中的词数
+}
+
+// PresetInfo 推送预设：一组命名的投放、文案、名称和提交设置，使用时补全请求中未填写的字段
+type PresetInfo struct {
+	Name               string                 `json:"name"`                        // 预设名称
+	Description        string                 `json:"description,optional"`        // 说明
+	Account            string                 `json:"account,optional"`            // 要求的京东账号（Cookie 中的 pin），与当前账号不一致时拒绝提交
+	MediaList          []string               `json:"mediaList,optional"`          // 投放媒体列表
+	CategoryList       []string               `json:"categoryList,optional"`       // 素材所属品类列表
+	ReleaseCopy        string                 `json:"releaseCopy,optional"`        // 投放文案
+	CopyVariants       []CopyVariant          `json:"copyVariants,optional"`       // 投放文案变体
+	CopyVars           map[string]string      `json:"copyVars,optional"`           // 文案模板变量
+	Columns            map[string]interface{} `json:"columns,optional"`            // 其他 diyColumns 列值
+	NameTemplate       string                 `json:"nameTemplate,optional"`       // 素材名称模板
+	Campaign           string                 `json:"campaign,optional"`           // 活动名
+	Profile            string                 `json:"profile,optional"`            // 上传前处理方案
+	Order              string                 `json:"order,optional"`              // 上传顺序
+	SubmitPolicy       string                 `json:"submitPolicy,optional"`       // 提交策略
+	MinUploadedPercent int                    `json:"minUploadedPercent,optional"` // threshold 策略要求的上传成功比例（1-100）
+	IsolateFailures    bool                   `json:"isolateFailures,optional"`    // 批次被拒绝时拆分重新提交
+}
+
+// PresetListResponse 预设列表响应
+type PresetListResponse struct {
+	Code    int          `json:"code"`
+	Message string       `json:"message"`
+	Account string       `json:"account"` // 当前登录的京东账号
+	Data    []PresetInfo `json:"data"`
+}
+
+// DeletePresetRequest 删除预设请求
+type DeletePresetRequest struct {
+	Name string `json:"name"`
+}
+
+// PresetResponse 保存或删除预设响应
+type PresetResponse struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    *PresetInfo `json:"data,omitempty"`
+}
+
+// ExportPresetsRequest 导出预设请求
+type ExportPresetsRequest struct {
+	Names string `form:"names,optional"` // 预设名称，逗号分隔，为空时导出全部
+}
+
+// ExportPresetsResponse 导出预设响应
+type ExportPresetsResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Content string `json:"content"` // 预设文件内容（YAML），可直接作为共享的预设文件
+}
+
+// ImportPresetsRequest 导入预设请求
+type ImportPresetsRequest struct {
+	Content   string `json:"content"`            // 预设文件内容（YAML）
+	Overwrite bool   `json:"overwrite,optional"` // 覆盖同名预设，否则跳过
+}
+
+// ImportPresetsResponse 导入预设响应
+type ImportPresetsResponse struct {
+	Code     int      `json:"code"`
+	Message  string   `json:"message"`
+	Added    []string `json:"added"`    // 新增的预设
+	Replaced []string `json:"replaced"` // 覆盖的预设
+	Skipped  []string `json:"skipped"`  // 已存在而跳过的预设
+}
+
+// HistoryRequest 查询推送历史请求
+type HistoryRequest struct {
+	From     string `form:"from,optional"`                     // 开始日期（含），如 2024-06-01
+	To       string `form:"to,optional"`                       // 结束日期（含）
+	Account  string `form:"account,optional"`                  // 京东账号
+	Media    string `form:"media,optional"`                    // 投放媒体 ID
+	Category string `form:"category,optional"`                 // 素材品类 ID
+	Status   string `form:"status,optional"`                   // 任务状态：uploaded、held、success、partial、failed、withdrawn、empty
+	FileName string `form:"fileName,optional"`                 // 文件名或素材名称包含的内容
+	Page     int    `form:"page,default=1"`                    // 页码，从 1 开始
+	PageSize int    `form:"pageSize,default=20,range=[1:200]"` // 每页任务数
+}
+
+// HistoryJob 推送历史中的一个任务
+type HistoryJob struct {
+	ID           string            `json:"id"`
+	FolderPath   string            `json:"folderPath"`
+	Account      string            `json:"account"`
+	MediaList    []string          `json:"mediaList"`
+	CategoryList []string          `json:"categoryList"`
+	ReleaseCopy  string            `json:"releaseCopy"`
+	CopyVariants []CopyVariant     `json:"copyVariants,omitempty"`
+	CopyVars     map[string]string `json:"copyVars,omitempty"`
+	Campaign     string            `json:"campaign,omitempty"`
+	NameTemplate string            `json:"nameTemplate,omitempty"`
+	Profile      string            `json:"profile,omitempty"`
+	SubmitPolicy string            `json:"submitPolicy,omitempty"`
+	Status       string            `json:"status"`    // 任务状态
+	Total        int               `json:"total"`     // 素材数
+	Uploaded     int               `json:"uploaded"`  // 上传成功数
+	Submitted    int               `json:"submitted"` // 提交成功数（含已撤回）
+	Failed       int               `json:"failed"`    // 上传或提交失败数
+	Held         int               `json:"held"`      // 暂缓提交数
+	Withdrawn    int               `json:"withdrawn"` // 已撤回数
+	Approved     int               `json:"approved"`  // 审核通过数
+	Rejected     int               `json:"rejected"`  // 审核驳回数
+	Batches      []string          `json:"batches"`   // 素材中心批次号
+	CreatedAt    string            `json:"createdAt"`
+	UpdatedAt    string            `json:"updatedAt"`
+}
+
+// HistoryResponse 推送历史响应
+type HistoryResponse struct {
+	Code    int          `json:"code"`
+	Message string       `json:"message"`
+	Total   int          `json:"total"` // 满足条件的任务总数
+	Data    []HistoryJob `json:"data"`
+}
+
+// HistoryDetailRequest 查询任务详情请求
+type HistoryDetailRequest struct {
+	JobID string `form:"jobId"`
+}
+
+// HistoryMaterial 任务中一个素材的记录
+type HistoryMaterial struct {
+	FileName       string          `json:"fileName"`
+	FilePath       string          `json:"filePath"`
+	FileSize       int64           `json:"fileSize"`
+	MaterialType   int             `json:"materialType"`
+	Width          int             `json:"width,omitempty"`
+	Height         int             `json:"height,omitempty"`
+	Duration       float64         `json:"duration,omitempty"`
+	MaterialName   string          `json:"materialName"`
+	URL            string          `json:"url"`
+	LocalURL       string          `json:"localUrl"`
+	UploadStatus   string          `json:"uploadStatus"`   // uploaded、failed
+	SubmitStatus   string          `json:"submitStatus"`   // submitted、failed、held、withdrawn、withdraw_failed，未提交为空
+	BatchUUID      string          `json:"batchUuid"`      // 素材中心批次号
+	Message        string          `json:"message"`        // 上传或提交返回的信息
+	ApprovalStatus string          `json:"approvalStatus"` // pending、approved、rejected，未提交为空
+	ApprovalNote   string          `json:"approvalNote,omitempty"`
+	Steps          []TransformStep `json:"steps,omitempty"` // 上传前处理步骤
+	SubmittedAt    string          `json:"submittedAt,omitempty"`
+	UpdatedAt      string          `json:"updatedAt"`
+}
+
+// HistoryDetailResponse 任务详情响应
+type HistoryDetailResponse struct {
+	Code      int               `json:"code"`
+	Message   string            `json:"message"`
+	Job       *HistoryJob       `json:"job,omitempty"`
+	Materials []HistoryMaterial `json:"materials"`
+}
+
+// RecordApprovalRequest 回填素材审核结果请求
+type RecordApprovalRequest struct {
+	URL    string `json:"url"`                                      // 素材 URL
+	Status string `json:"status,options=pending|approved|rejected"` // 审核状态
+	Note   string `json:"note,optional"`                            // 审核意见，如驳回原因
+}
+
+// RecordApprovalResponse 回填审核结果响应
+type RecordApprovalResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Updated int    `json:"updated"` // 更新的素材记录数
+}
+
+// ExportReportRequest 导出任务报告请求
+type ExportReportRequest struct {
+	JobID  string `form:"jobId"`
+	Format string `form:"format,default=html,options=csv|json|html"` // csv、json 为素材清单，html 为带缩略图的离线页面
+}
+
+// ExportReportResponse 导出任务报告响应；成功时接口直接返回报告文件，失败时返回 code 和 message
+type ExportReportResponse struct {
+	Code        int    `json:"code"`
+	Message     string `json:"message"`
+	FileName    string `json:"-"` // 报告文件名
+	ContentType string `json:"-"`
+	Content     []byte `json:"-"`
 }